@@ -26,6 +26,12 @@ type SubtractParams struct {
 	B float64 `json:"b" description:"The number to subtract (subtrahend)."`
 }
 
+// MultiplyParams defines the input for our "multiply" tool.
+type MultiplyParams struct {
+	A float64 `json:"a" description:"The first factor."`
+	B float64 `json:"b" description:"The second factor."`
+}
+
 func main() {
 	// 1. Initialize the server with its name, version, and capabilities.
 	// We are enabling the "tools" capability.
@@ -61,6 +67,11 @@ func main() {
 				return fmt.Sprintf("The difference of %f minus %f is %f.", params.A, params.B, result), nil
 			},
 		},
+		// mcp.Tool builds the same kind of registration, but the compiler checks the
+		// handler's signature instead of the SDK checking it at registration time.
+		mcp.Tool("calculator/multiply", func(ctx context.Context, params *MultiplyParams) (string, error) {
+			return fmt.Sprintf("The product of %f and %f is %f.", params.A, params.B, params.A*params.B), nil
+		}, mcp.WithDescription("Calculates the product of two numbers, a and b.")),
 	}
 
 	// 3. Register all tools with a single, clean API call.
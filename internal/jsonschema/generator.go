@@ -2,64 +2,176 @@ package jsonschema
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/invopop/jsonschema"
 )
 
+// schemaDescriber is implemented by types that provide their own JSON schema.
+type schemaDescriber interface {
+	JSONSchema() *jsonschema.Schema
+}
+
+// Options controls how schemas are generated.
+type Options struct {
+	// UseReferences emits each named struct type once under $defs and refers to it
+	// with $ref, instead of inlining it wherever it is used.
+	UseReferences bool
+	// Descriptions sets the description of properties by name, taking precedence over
+	// 'description' tags. Nested properties are named by their path from the top level,
+	// such as "address.city"; array elements are descended into implicitly, so the items
+	// of a "tags" array of objects have properties "tags.name" and so on. With
+	// UseReferences, a nested description applies wherever the shared type is used.
+	Descriptions map[string]string
+	// MaxDepth bounds how deeply objects, arrays and maps may nest in the schema;
+	// a type nesting deeper is rejected. Zero means DefaultMaxDepth.
+	MaxDepth int
+	// FieldNaming names the properties of fields whose json tag does not name them.
+	// The zero value uses Go field names, as encoding/json does.
+	FieldNaming FieldNaming
+}
+
+// DefaultMaxDepth is the nesting limit applied when Options.MaxDepth is zero. It is far
+// beyond what a hand-written parameter struct needs, but stops pathological types.
+const DefaultMaxDepth = 32
+
 // GenerateSchemaForType uses reflection to create a JSON schema for a given Go struct type.
 func GenerateSchemaForType(t reflect.Type) (json.RawMessage, error) {
+	return GenerateSchemaWithOptions(t, Options{})
+}
+
+// GenerateSchemaWithOptions is like GenerateSchemaForType but allows the output to be customised.
+func GenerateSchemaWithOptions(t reflect.Type, opts Options) (json.RawMessage, error) {
 	// If the type is a pointer, get the element type it points to.
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
-	// The schema should describe a struct.
+	// The schema should describe a struct. Other types (such as those implementing
+	// json.Unmarshaler) may describe themselves through a JSONSchema method;
+	// otherwise we fall back to an open object.
 	if t.Kind() != reflect.Struct {
+		if len(opts.Descriptions) > 0 {
+			return nil, fmt.Errorf("descriptions cannot be applied to non-struct type %s", t)
+		}
+		if describer, ok := reflect.New(t).Interface().(schemaDescriber); ok {
+			return json.Marshal(describer.JSONSchema())
+		}
+		if describer, ok := reflect.Zero(t).Interface().(schemaDescriber); ok {
+			return json.Marshal(describer.JSONSchema())
+		}
 		return json.RawMessage(`{"type": "object", "properties": {}}`), nil
 	}
 
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if depth := typeDepth(t, make(map[reflect.Type]int), make(map[reflect.Type]bool)); depth > maxDepth {
+		return nil, fmt.Errorf("type %s nests %d levels deep, more than the limit of %d", t, depth, maxDepth)
+	}
+
 	// Step 1: Generate the base schema without using references.
 	// This ensures the schema is fully inlined, which is what the MCP spec expects.
-	reflector := &jsonschema.Reflector{
-		DoNotReference: true,
+	// Self-referential types cannot be inlined, so they fall back to $defs and $ref,
+	// as does every type when references were requested.
+	var schema *jsonschema.Schema
+	if opts.UseReferences || isRecursiveType(t) {
+		schema = reflectWithReferences(t)
+	} else {
+		reflector := &jsonschema.Reflector{
+			DoNotReference: true,
+		}
+		schema = reflector.Reflect(reflect.New(t).Interface())
 	}
-	schema := reflector.Reflect(reflect.New(t).Interface())
 
-	// Step 2: Add descriptions from struct tags.
-	// The jsonschema library does not handle 'description' tags, so we add them here.
-	// We must check if the Properties map is nil, as the library may not initialize it.
+	// Fields without a json tag name are named as the options ask, rather than with the
+	// Go field names the reflector uses.
+	if opts.FieldNaming != NameAsDeclared {
+		renameFields(schema, t, schema.Definitions, opts.FieldNaming, make(map[*jsonschema.Schema]bool))
+	}
+
+	// Step 2: Add descriptions and titles from struct tags.
+	// The jsonschema library does not handle 'description' or 'title' tags, so we add them here,
+	// at every level: a sub-struct used to group parameters keeps the documentation of
+	// its own fields as well as the description of the group itself.
+	if err := describeFields(schema, t, schema.Definitions, opts.FieldNaming, make(map[*jsonschema.Schema]bool)); err != nil {
+		return nil, err
+	}
+	if err := applyDescriptions(schema, opts.Descriptions); err != nil {
+		return nil, err
+	}
+
+	// Old names listed in an 'alias' tag are still accepted, so they are described as
+	// optional, deprecated copies of the property. Aliases only apply to top-level arguments.
 	if schema.Properties != nil {
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == "" || jsonTag == "-" {
+			propertyName, _, ok := FieldName(field, opts.FieldNaming)
+			if !ok {
 				continue
 			}
-			propertyName := strings.Split(jsonTag, ",")[0]
-
-			// Find the corresponding property in the generated schema.
-			if prop, ok := schema.Properties.Get(propertyName); ok {
-				// If the property exists, add the description from the tag.
-				if descTag := field.Tag.Get("description"); descTag != "" {
-					prop.Description = descTag
+			prop, ok := schema.Properties.Get(propertyName)
+			if !ok {
+				continue
+			}
+			for _, alias := range ParseAliasTag(field.Tag.Get("alias")) {
+				if _, exists := schema.Properties.Get(alias); exists {
+					continue
 				}
+				aliasProp := *prop
+				aliasProp.Deprecated = true
+				schema.Properties.Set(alias, &aliasProp)
 			}
 		}
 	}
 
-	// Step 3: Mark all fields as required for simplicity.
-	// A more robust solution might inspect struct tags.
+	// Step 3: Mark fields as required for simplicity, except pointer fields.
+	// A pointer field is left nil when the client omits it, so handlers can tell
+	// "not provided" apart from the zero value; the schema must allow omitting it.
 	if schema.Properties != nil {
+		optional := make(map[string]bool)
+		aliased := make(map[string]bool)
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
-			jsonTag := field.Tag.Get("json")
-			if jsonTag != "" && jsonTag != "-" {
-				propertyName := strings.Split(jsonTag, ",")[0]
+			if propertyName, _, ok := FieldName(field, opts.FieldNaming); ok {
+				if field.Type.Kind() == reflect.Ptr {
+					optional[propertyName] = true
+					for _, alias := range ParseAliasTag(field.Tag.Get("alias")) {
+						optional[alias] = true
+					}
+					continue
+				}
+				// A field with aliases may arrive under any of its names, so none is required.
+				if len(ParseAliasTag(field.Tag.Get("alias"))) > 0 {
+					aliased[propertyName] = true
+					continue
+				}
 				schema.Required = append(schema.Required, propertyName)
 			}
 		}
+
+		seen := make(map[string]bool)
+		required := make([]string, 0, len(schema.Required))
+		for _, name := range schema.Required {
+			if optional[name] || aliased[name] || seen[name] {
+				continue
+			}
+			seen[name] = true
+			required = append(required, name)
+		}
+		schema.Required = required
+
+		// Pointer fields may also be sent (or returned) as an explicit null.
+		for name := range optional {
+			if prop, ok := schema.Properties.Get(name); ok {
+				allowNull(prop)
+			}
+		}
 	}
 
 	// Step 4: Marshal the final, modified schema into JSON.
@@ -69,4 +181,311 @@ func GenerateSchemaForType(t reflect.Type) (json.RawMessage, error) {
 	}
 
 	return json.RawMessage(schemaBytes), nil
-}
\ No newline at end of file
+}
+
+// ParseAliasTag splits an 'alias' struct tag into the alternative names it lists.
+func ParseAliasTag(tag string) []string {
+	var aliases []string
+	for _, alias := range strings.Split(tag, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// describeFields applies the 'description', 'title', 'deprecated' and 'pattern' tags of
+// t's fields to the matching properties of schema, then descends into fields whose type is a struct
+// (directly, through a pointer, or as the element of a slice or array). Nested schemas
+// may be references into defs; seen stops recursive types from being walked forever.
+func describeFields(schema *jsonschema.Schema, t reflect.Type, defs jsonschema.Definitions, naming FieldNaming, seen map[*jsonschema.Schema]bool) error {
+	schema = resolveRef(schema, defs)
+	if schema == nil || schema.Properties == nil || seen[schema] {
+		return nil
+	}
+	seen[schema] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		propertyName, _, ok := FieldName(field, naming)
+		if !ok {
+			continue
+		}
+
+		// Find the corresponding property in the generated schema.
+		prop, ok := schema.Properties.Get(propertyName)
+		if !ok {
+			continue
+		}
+		if descTag := field.Tag.Get("description"); descTag != "" {
+			prop.Description = descTag
+		}
+		// The title is a short, human-friendly label, e.g. for form fields.
+		if titleTag := field.Tag.Get("title"); titleTag != "" {
+			prop.Title = titleTag
+		}
+		if deprecatedTag := field.Tag.Get("deprecated"); deprecatedTag != "" {
+			prop.Deprecated = true
+		}
+		if patternTag := field.Tag.Get("pattern"); patternTag != "" {
+			if err := setPattern(prop, field, patternTag); err != nil {
+				return err
+			}
+		}
+
+		if node, fieldType := nestedStruct(prop, field.Type); node != nil {
+			if err := describeFields(node, fieldType, defs, naming, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setPattern applies a 'pattern' tag to the schema of a string field, or to the items
+// of a slice or array of strings. The pattern must be a valid regular expression.
+func setPattern(prop *jsonschema.Schema, field reflect.StructField, pattern string) error {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("invalid pattern for field %s: %w", field.Name, err)
+	}
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && prop.Items != nil {
+		prop, t = prop.Items, t.Elem()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	if t.Kind() != reflect.String {
+		return fmt.Errorf("field %s has a pattern but does not hold strings", field.Name)
+	}
+	prop.Pattern = pattern
+	return nil
+}
+
+// nestedStruct finds the struct a field of type t holds, directly, through a pointer,
+// or as the element of a slice or array, and the part of the field's schema prop that
+// describes it. It returns nil if the field holds no struct.
+func nestedStruct(prop *jsonschema.Schema, t reflect.Type) (*jsonschema.Schema, reflect.Type) {
+	node := prop
+	for node != nil && t.Kind() != reflect.Struct {
+		switch t.Kind() {
+		case reflect.Ptr:
+			t = t.Elem()
+		case reflect.Slice, reflect.Array:
+			t, node = t.Elem(), node.Items
+		default:
+			node = nil
+		}
+	}
+	return node, t
+}
+
+// renameFields renames the properties of t's fields that have no json tag name from
+// the Go field names the reflector gives them to the names naming asks for, including
+// fields promoted from embedded structs, then does the same for nested structs.
+func renameFields(schema *jsonschema.Schema, t reflect.Type, defs jsonschema.Definitions, naming FieldNaming, seen map[*jsonschema.Schema]bool) {
+	schema = resolveRef(schema, defs)
+	if schema == nil || schema.Properties == nil || seen[schema] {
+		return
+	}
+	seen[schema] = true
+
+	renames := make(map[string]string)
+	for _, field := range EncodedFields(t) {
+		name, tagged, ok := FieldName(field, naming)
+		if !ok {
+			continue
+		}
+		key := name
+		if !tagged {
+			key = field.Name
+		}
+		prop, ok := schema.Properties.Get(key)
+		if !ok {
+			continue
+		}
+		if key != name {
+			renames[key] = name
+		}
+		if node, fieldType := nestedStruct(prop, field.Type); node != nil {
+			renameFields(node, fieldType, defs, naming, seen)
+		}
+	}
+	if len(renames) == 0 {
+		return
+	}
+
+	properties := jsonschema.NewProperties()
+	for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		key := pair.Key
+		if renamed, ok := renames[key]; ok {
+			key = renamed
+		}
+		properties.Set(key, pair.Value)
+	}
+	schema.Properties = properties
+	for i, name := range schema.Required {
+		if renamed, ok := renames[name]; ok {
+			schema.Required[i] = renamed
+		}
+	}
+}
+
+// applyDescriptions sets the description of each property named in descriptions. A path
+// that does not lead to a property is an error, so that typos are not silently ignored.
+func applyDescriptions(schema *jsonschema.Schema, descriptions map[string]string) error {
+	paths := make([]string, 0, len(descriptions))
+	for path := range descriptions {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		prop := schema
+		for _, name := range strings.Split(path, ".") {
+			node := resolveRef(prop, schema.Definitions)
+			for node != nil && node.Properties == nil && node.Items != nil {
+				node = resolveRef(node.Items, schema.Definitions)
+			}
+			if node == nil || node.Properties == nil {
+				return fmt.Errorf("description for unknown property %q", path)
+			}
+			next, ok := node.Properties.Get(name)
+			if !ok {
+				return fmt.Errorf("description for unknown property %q", path)
+			}
+			prop = next
+		}
+		prop.Description = descriptions[path]
+	}
+	return nil
+}
+
+// resolveRef follows a "#/$defs/..." reference to the definition it names.
+func resolveRef(schema *jsonschema.Schema, defs jsonschema.Definitions) *jsonschema.Schema {
+	if schema == nil || schema.Ref == "" {
+		return schema
+	}
+	return defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+}
+
+// reflectWithReferences generates a schema that uses $ref for every named struct type,
+// then promotes the root type's definition to the top level so the schema still
+// describes an object directly. For recursive types the root stays in $defs so that
+// references back to it resolve.
+func reflectWithReferences(t reflect.Type) *jsonschema.Schema {
+	reflector := &jsonschema.Reflector{}
+	full := reflector.Reflect(reflect.New(t).Interface())
+
+	rootName := strings.TrimPrefix(full.Ref, "#/$defs/")
+	rootDef, ok := full.Definitions[rootName]
+	if !ok {
+		return full
+	}
+
+	root := *rootDef
+	root.Version = full.Version
+	root.Definitions = full.Definitions
+	if !isRecursiveType(t) {
+		delete(root.Definitions, rootName)
+		if len(root.Definitions) == 0 {
+			root.Definitions = nil
+		}
+	}
+	root.Required = append([]string(nil), rootDef.Required...)
+	return &root
+}
+
+// typeDepth returns how many levels of objects, arrays and maps the schema for t nests.
+// A type referring back to itself is described with $ref, so the recursion is not
+// counted. Depths are memoized in known, as wide types often repeat the same fields.
+func typeDepth(t reflect.Type, known map[reflect.Type]int, visiting map[reflect.Type]bool) int {
+	if depth, ok := known[t]; ok {
+		return depth
+	}
+	if visiting[t] {
+		return 0
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	depth := 0
+	switch t.Kind() {
+	case reflect.Ptr:
+		depth = typeDepth(t.Elem(), known, visiting)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		// A []byte is encoded as a base64 string, not an array.
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		depth = 1 + typeDepth(t.Elem(), known, visiting)
+	case reflect.Struct:
+		inner := 0
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			fieldDepth := typeDepth(field.Type, known, visiting)
+			// Embedded struct fields are promoted into the enclosing object.
+			if field.Anonymous && field.Tag.Get("json") == "" {
+				fieldDepth--
+			}
+			if fieldDepth > inner {
+				inner = fieldDepth
+			}
+		}
+		depth = 1 + inner
+	}
+	known[t] = depth
+	return depth
+}
+
+// isRecursiveType reports whether a struct type refers back to itself through its fields.
+func isRecursiveType(t reflect.Type) bool {
+	return hasTypeCycle(t, make(map[reflect.Type]bool))
+}
+
+func hasTypeCycle(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasTypeCycle(t.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			return true
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if hasTypeCycle(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allowNull rewrites a property schema in place so that it also accepts null,
+// keeping its description, title and deprecation on the outer schema.
+func allowNull(prop *jsonschema.Schema) {
+	if prop.Type == "null" {
+		return
+	}
+	inner := *prop
+	inner.Description = ""
+	inner.Title = ""
+	inner.Deprecated = false
+	*prop = jsonschema.Schema{
+		Title:       prop.Title,
+		Description: prop.Description,
+		Deprecated:  prop.Deprecated,
+		AnyOf:       []*jsonschema.Schema{&inner, {Type: "null"}},
+	}
+}
@@ -0,0 +1,274 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type treeNode struct {
+	Name     string     `json:"name"`
+	Children []treeNode `json:"children"`
+}
+
+type listNode struct {
+	Value int       `json:"value"`
+	Next  *listNode `json:"next"`
+}
+
+type department struct {
+	Name  string     `json:"name"`
+	Staff []employee `json:"staff"`
+}
+
+type employee struct {
+	Name string      `json:"name"`
+	Runs *department `json:"runs"`
+}
+
+type flatOrder struct {
+	Item    string `json:"item"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+// decodeSchema generates the schema for v's type and decodes it into a map.
+func decodeSchema(t *testing.T, v interface{}, opts Options) map[string]interface{} {
+	t.Helper()
+	raw, err := GenerateSchemaWithOptions(reflect.TypeOf(v), opts)
+	if err != nil {
+		t.Fatalf("GenerateSchemaWithOptions: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("decoding schema: %v", err)
+	}
+	return schema
+}
+
+func TestGenerateSchemaRecursiveTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		document string
+		wantDefs bool
+	}{
+		{"tree through a slice", &treeNode{}, `{"name":"root","children":[{"name":"leaf","children":[]}]}`, true},
+		{"list through a pointer", &listNode{}, `{"value":1,"next":{"value":2,"next":null}}`, true},
+		{"mutual recursion", &department{}, `{"name":"ops","staff":[{"name":"kim","runs":{"name":"night","staff":[]}}]}`, true},
+		{"not recursive", &flatOrder{}, `{"item":"pen","address":{"city":"Oslo"}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := decodeSchema(t, tt.input, Options{})
+			if schema["type"] != "object" {
+				t.Errorf("type = %v, want the root to describe an object", schema["type"])
+			}
+			if _, ok := schema["$defs"]; ok != tt.wantDefs {
+				t.Errorf("has $defs = %v, want %v", ok, tt.wantDefs)
+			}
+			raw, _ := json.Marshal(schema)
+			if err := Validate(raw, []byte(tt.document)); err != nil {
+				t.Errorf("valid document rejected: %v", err)
+			}
+		})
+	}
+}
+
+type calculatorInput struct {
+	A      int    `json:"a" title:"First Number" description:"The first operand."`
+	B      int    `json:"b" title:"Second Number"`
+	Op     string `json:"op" description:"The operation."`
+	Rounds *int   `json:"rounds" title:"Rounds"`
+}
+
+// property returns the schema of a top-level property.
+func property(t *testing.T, schema map[string]interface{}, name string) map[string]interface{} {
+	t.Helper()
+	properties, _ := schema["properties"].(map[string]interface{})
+	prop, ok := properties[name].(map[string]interface{})
+	if !ok {
+		t.Fatalf("schema has no property %q: %v", name, schema)
+	}
+	return prop
+}
+
+func TestGenerateSchemaTitles(t *testing.T) {
+	tests := []struct {
+		property        string
+		wantTitle       string
+		wantDescription string
+	}{
+		{"a", "First Number", "The first operand."},
+		{"b", "Second Number", ""},
+		{"op", "", "The operation."},
+		// The title of an optional field stays on the outer schema that allows null.
+		{"rounds", "Rounds", ""},
+	}
+	schema := decodeSchema(t, &calculatorInput{}, Options{})
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			prop := property(t, schema, tt.property)
+			if got, _ := prop["title"].(string); got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+			if got, _ := prop["description"].(string); got != tt.wantDescription {
+				t.Errorf("description = %q, want %q", got, tt.wantDescription)
+			}
+		})
+	}
+}
+
+type shippingInput struct {
+	Item    string `json:"item"`
+	Address struct {
+		City   string `json:"city" description:"The destination city."`
+		Street string `json:"street"`
+	} `json:"address" description:"Where to deliver." title:"Delivery Address"`
+	Extras []struct {
+		Name string `json:"name" description:"The extra's name."`
+	} `json:"extras" description:"Optional add-ons."`
+	Billing *struct {
+		Account string `json:"account" description:"The account to charge."`
+	} `json:"billing" description:"Who pays."`
+}
+
+// lookup follows a dotted path of property names (and "items" for array elements).
+func lookup(t *testing.T, schema map[string]interface{}, path string) map[string]interface{} {
+	t.Helper()
+	node := schema
+	for _, name := range strings.Split(path, ".") {
+		if name == "items" {
+			node, _ = node["items"].(map[string]interface{})
+		} else if anyOf, ok := node["anyOf"].([]interface{}); ok {
+			node = property(t, anyOf[0].(map[string]interface{}), name)
+		} else {
+			node = property(t, node, name)
+		}
+		if node == nil {
+			t.Fatalf("schema has no %s", path)
+		}
+	}
+	return node
+}
+
+func TestGenerateSchemaGroupDescriptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            Options
+		path            string
+		wantDescription string
+		wantTitle       string
+	}{
+		{"group", Options{}, "address", "Where to deliver.", "Delivery Address"},
+		{"grouped field", Options{}, "address.city", "The destination city.", ""},
+		{"undocumented grouped field", Options{}, "address.street", "", ""},
+		{"array of groups", Options{}, "extras", "Optional add-ons.", ""},
+		{"field of an array element", Options{}, "extras.items.name", "The extra's name.", ""},
+		{"optional group", Options{}, "billing", "Who pays.", ""},
+		{"field of an optional group", Options{}, "billing.account", "The account to charge.", ""},
+		{"group with references", Options{UseReferences: true}, "address", "Where to deliver.", "Delivery Address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := lookup(t, decodeSchema(t, &shippingInput{}, tt.opts), tt.path)
+			if got, _ := node["description"].(string); got != tt.wantDescription {
+				t.Errorf("description = %q, want %q", got, tt.wantDescription)
+			}
+			if got, _ := node["title"].(string); got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestGenerateSchemaDescriptionsMap(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            Options
+		description     string
+		path            string
+		wantDescription string
+		wantErr         bool
+	}{
+		{"undocumented field", Options{}, "item", "item", "The item to ship.", false},
+		{"overrides a tag", Options{}, "address.city", "address.city", "The item to ship.", false},
+		{"nested undocumented field", Options{}, "address.street", "address.street", "The item to ship.", false},
+		{"field of an array element", Options{}, "extras.name", "extras.items.name", "The item to ship.", false},
+		{"field of an optional group", Options{}, "billing.account", "billing.account", "The item to ship.", false},
+		{"with references", Options{UseReferences: true}, "address.street", "address.street", "The item to ship.", false},
+		{"unknown field", Options{}, "weight", "", "", true},
+		{"unknown nested field", Options{}, "address.zip", "", "", true},
+		{"below a scalar", Options{}, "item.code", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Descriptions = map[string]string{tt.description: "The item to ship."}
+			if tt.wantErr {
+				if _, err := GenerateSchemaWithOptions(reflect.TypeOf(&shippingInput{}), opts); err == nil || !strings.Contains(err.Error(), tt.description) {
+					t.Errorf("error = %v, want one naming %q", err, tt.description)
+				}
+				return
+			}
+			node := lookup(t, decodeSchema(t, &shippingInput{}, opts), tt.path)
+			if got, _ := node["description"].(string); got != tt.wantDescription {
+				t.Errorf("description = %q, want %q", got, tt.wantDescription)
+			}
+		})
+	}
+}
+
+// deepInput nests six levels: the object, three nested objects, an array and a map.
+type deepInput struct {
+	A struct {
+		B struct {
+			C struct {
+				D []map[string]int `json:"d"`
+			} `json:"c"`
+		} `json:"b"`
+	} `json:"a"`
+}
+
+type blobInput struct {
+	Data []byte `json:"data"`
+}
+
+type embeddedInput struct {
+	flatOrder
+	Note string `json:"note"`
+}
+
+func TestGenerateSchemaMaxDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		maxDepth int
+		wantErr  bool
+	}{
+		{"at the limit", &deepInput{}, 6, false},
+		{"past the limit", &deepInput{}, 5, true},
+		{"default limit", &deepInput{}, 0, false},
+		{"bytes are a string", &blobInput{}, 1, false},
+		{"embedded fields are promoted", &embeddedInput{}, 2, false},
+		{"embedded fields past the limit", &embeddedInput{}, 1, true},
+		{"recursion counted once", &treeNode{}, 2, false},
+		{"recursion past the limit", &treeNode{}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateSchemaWithOptions(reflect.TypeOf(tt.input), Options{MaxDepth: tt.maxDepth})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "more than the limit") {
+					t.Errorf("error = %v, want a depth limit error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("GenerateSchemaWithOptions: %v", err)
+			}
+		})
+	}
+}
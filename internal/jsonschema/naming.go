@@ -0,0 +1,121 @@
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FieldNaming decides the property name of struct fields whose json tag does not name
+// them. A name given in the json tag is always used as-is.
+type FieldNaming int
+
+const (
+	// NameAsDeclared uses the Go field name unchanged, as encoding/json does.
+	NameAsDeclared FieldNaming = iota
+	// NameLowercase uses the Go field name in lower case: "UserID" becomes "userid".
+	NameLowercase
+	// NameCamelCase lower-cases the first word of the Go field name: "UserID" becomes "userID".
+	NameCamelCase
+	// NameSnakeCase joins the words of the Go field name with underscores: "UserID"
+	// becomes "user_id".
+	NameSnakeCase
+)
+
+// FieldName returns the property name of a struct field and whether it comes from the
+// field's json tag. It reports false for fields that are not encoded under a name of
+// their own: unexported fields, fields tagged "-", and embedded structs without a tag
+// name, whose fields are promoted into the enclosing object.
+func FieldName(field reflect.StructField, naming FieldNaming) (name string, tagged bool, ok bool) {
+	jsonTag := field.Tag.Get("json")
+	if jsonTag == "-" {
+		return "", false, false
+	}
+	if tagName := strings.Split(jsonTag, ",")[0]; tagName != "" {
+		return tagName, true, field.IsExported() || field.Anonymous
+	}
+	if IsPromoted(field) || !field.IsExported() {
+		return "", false, false
+	}
+	return applyNaming(field.Name, naming), false, true
+}
+
+// IsPromoted reports whether field is an embedded struct whose fields encoding/json
+// promotes into the enclosing object.
+func IsPromoted(field reflect.StructField) bool {
+	if !field.Anonymous || strings.Split(field.Tag.Get("json"), ",")[0] != "" {
+		return false
+	}
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// EncodedFields returns the fields of struct type t, followed by those promoted from
+// embedded structs, recursively. A promoted field is left out if a field of the same
+// Go name was already listed, since the shallower field hides it.
+func EncodedFields(t reflect.Type) []reflect.StructField {
+	var fields, embedded []reflect.StructField
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if IsPromoted(field) {
+			embedded = append(embedded, field)
+			continue
+		}
+		names[field.Name] = true
+		fields = append(fields, field)
+	}
+	for _, field := range embedded {
+		inner := field.Type
+		if inner.Kind() == reflect.Ptr {
+			inner = inner.Elem()
+		}
+		for _, promoted := range EncodedFields(inner) {
+			if !names[promoted.Name] {
+				names[promoted.Name] = true
+				fields = append(fields, promoted)
+			}
+		}
+	}
+	return fields
+}
+
+// applyNaming converts a Go identifier according to naming.
+func applyNaming(name string, naming FieldNaming) string {
+	switch naming {
+	case NameLowercase:
+		return strings.ToLower(name)
+	case NameCamelCase:
+		words := splitWords(name)
+		words[0] = strings.ToLower(words[0])
+		return strings.Join(words, "")
+	case NameSnakeCase:
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	default:
+		return name
+	}
+}
+
+// splitWords splits a Go identifier into words at each upper-case letter that follows a
+// lower-case letter or digit, and before the last letter of an initialism that is
+// followed by another word: "HTTPServerID" gives "HTTP", "Server" and "ID".
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		if !unicode.IsUpper(cur) {
+			continue
+		}
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
@@ -0,0 +1,33 @@
+package jsonschema
+
+import "testing"
+
+func TestApplyNaming(t *testing.T) {
+	tests := []struct {
+		name      string
+		lowercase string
+		camel     string
+		snake     string
+	}{
+		{"Name", "name", "name", "name"},
+		{"UserID", "userid", "userID", "user_id"},
+		{"HTTPServerID", "httpserverid", "httpServerID", "http_server_id"},
+		{"PostCode2", "postcode2", "postCode2", "post_code2"},
+		{"Address2Line", "address2line", "address2Line", "address2_line"},
+		{"ID", "id", "id", "id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for naming, want := range map[FieldNaming]string{
+				NameAsDeclared: tt.name,
+				NameLowercase:  tt.lowercase,
+				NameCamelCase:  tt.camel,
+				NameSnakeCase:  tt.snake,
+			} {
+				if got := applyNaming(tt.name, naming); got != want {
+					t.Errorf("applyNaming(%q, %d) = %q, want %q", tt.name, naming, got, want)
+				}
+			}
+		})
+	}
+}
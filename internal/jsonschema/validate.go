@@ -0,0 +1,361 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError describes why a value does not satisfy a schema.
+type ValidationError struct {
+	// Path is a JSON-pointer-like location of the offending value, e.g. "/items/0/name".
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Validate checks a JSON document against a JSON schema.
+// It supports the subset of keywords produced by the generator: type, properties,
+// required, additionalProperties, items, enum, const, pattern, length and range bounds,
+// allOf/anyOf/oneOf, and local "$ref"s into "$defs" or "definitions".
+func Validate(schema json.RawMessage, document []byte) error {
+	var root map[string]interface{}
+	if err := decodeJSON(schema, &root); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	var value interface{}
+	if err := decodeJSON(document, &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	v := &validator{root: root}
+	return v.validate(root, value, "")
+}
+
+func decodeJSON(data []byte, dst interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(dst)
+}
+
+type validator struct {
+	root map[string]interface{}
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) error {
+	return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+func (v *validator) validate(schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			return v.fail(path, "%v", err)
+		}
+		if err := v.validate(target, value, path); err != nil {
+			return err
+		}
+	}
+
+	if t, ok := schema["type"]; ok {
+		if err := v.checkType(t, value, path); err != nil {
+			return err
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		if !containsValue(enum, value) {
+			return v.fail(path, "value must be one of %s", compactJSON(enum))
+		}
+	}
+	if c, ok := schema["const"]; ok {
+		if !jsonEqual(c, value) {
+			return v.fail(path, "value must be %s", compactJSON(c))
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		if err := v.validateObject(schema, val, path); err != nil {
+			return err
+		}
+	case []interface{}:
+		if err := v.validateArray(schema, val, path); err != nil {
+			return err
+		}
+	case string:
+		if err := v.validateString(schema, val, path); err != nil {
+			return err
+		}
+	case json.Number:
+		if err := v.validateNumber(schema, val, path); err != nil {
+			return err
+		}
+	}
+
+	return v.validateCombinators(schema, value, path)
+}
+
+func (v *validator) resolve(ref string) (map[string]interface{}, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+		defs, _ := v.root[strings.TrimSuffix(strings.TrimPrefix(prefix, "#/"), "/")].(map[string]interface{})
+		if target, ok := defs[strings.TrimPrefix(ref, prefix)].(map[string]interface{}); ok {
+			return target, nil
+		}
+	}
+	return nil, fmt.Errorf("unresolvable $ref %q", ref)
+}
+
+func (v *validator) checkType(t interface{}, value interface{}, path string) error {
+	var allowed []string
+	switch tt := t.(type) {
+	case string:
+		allowed = []string{tt}
+	case []interface{}:
+		for _, item := range tt {
+			if s, ok := item.(string); ok {
+				allowed = append(allowed, s)
+			}
+		}
+	}
+	for _, name := range allowed {
+		if hasType(name, value) {
+			return nil
+		}
+	}
+	return v.fail(path, "expected %s, got %s", strings.Join(allowed, " or "), typeName(value))
+}
+
+func hasType(name string, value interface{}) bool {
+	switch name {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		r, ok := new(big.Rat).SetString(n.String())
+		return ok && r.IsInt()
+	}
+	return false
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func (v *validator) validateObject(schema map[string]interface{}, obj map[string]interface{}, path string) error {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := obj[name]; !present {
+				return v.fail(path, "missing required property %q", name)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, propValue := range obj {
+		propPath := path + "/" + name
+		if propSchema, ok := properties[name].(map[string]interface{}); ok {
+			if err := v.validate(propSchema, propValue, propPath); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return v.fail(path, "unexpected property %q", name)
+			}
+		case map[string]interface{}:
+			if err := v.validate(additional, propValue, propPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateArray(schema map[string]interface{}, arr []interface{}, path string) error {
+	if min, ok := intKeyword(schema, "minItems"); ok && len(arr) < min {
+		return v.fail(path, "expected at least %d items, got %d", min, len(arr))
+	}
+	if max, ok := intKeyword(schema, "maxItems"); ok && len(arr) > max {
+		return v.fail(path, "expected at most %d items, got %d", max, len(arr))
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range arr {
+			if err := v.validate(items, item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateString(schema map[string]interface{}, str string, path string) error {
+	length := utf8.RuneCountInString(str)
+	if min, ok := intKeyword(schema, "minLength"); ok && length < min {
+		return v.fail(path, "expected at least %d characters, got %d", min, length)
+	}
+	if max, ok := intKeyword(schema, "maxLength"); ok && length > max {
+		return v.fail(path, "expected at most %d characters, got %d", max, length)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return v.fail(path, "invalid pattern %q: %v", pattern, err)
+		}
+		if !re.MatchString(str) {
+			return v.fail(path, "value %q does not match pattern %q", str, pattern)
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateNumber(schema map[string]interface{}, num json.Number, path string) error {
+	value, ok := new(big.Rat).SetString(num.String())
+	if !ok {
+		return v.fail(path, "invalid number %s", num)
+	}
+	bound := func(keyword string) (*big.Rat, bool) {
+		n, ok := schema[keyword].(json.Number)
+		if !ok {
+			return nil, false
+		}
+		return new(big.Rat).SetString(n.String())
+	}
+	if min, ok := bound("minimum"); ok && value.Cmp(min) < 0 {
+		return v.fail(path, "value %s is less than minimum %s", num, schema["minimum"])
+	}
+	if max, ok := bound("maximum"); ok && value.Cmp(max) > 0 {
+		return v.fail(path, "value %s is greater than maximum %s", num, schema["maximum"])
+	}
+	if min, ok := bound("exclusiveMinimum"); ok && value.Cmp(min) <= 0 {
+		return v.fail(path, "value %s must be greater than %s", num, schema["exclusiveMinimum"])
+	}
+	if max, ok := bound("exclusiveMaximum"); ok && value.Cmp(max) >= 0 {
+		return v.fail(path, "value %s must be less than %s", num, schema["exclusiveMaximum"])
+	}
+	return nil
+}
+
+func (v *validator) validateCombinators(schema map[string]interface{}, value interface{}, path string) error {
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				if err := v.validate(subSchema, value, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		if v.countMatches(anyOf, value, path) == 0 {
+			return v.fail(path, "value does not match any of the allowed schemas")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if matches := v.countMatches(oneOf, value, path); matches != 1 {
+			return v.fail(path, "value must match exactly one schema, matched %d", matches)
+		}
+	}
+	return nil
+}
+
+func (v *validator) countMatches(schemas []interface{}, value interface{}, path string) int {
+	matches := 0
+	for _, sub := range schemas {
+		if subSchema, ok := sub.(map[string]interface{}); ok && v.validate(subSchema, value, path) == nil {
+			matches++
+		}
+	}
+	return matches
+}
+
+func intKeyword(schema map[string]interface{}, keyword string) (int, bool) {
+	n, ok := schema[keyword].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0, false
+	}
+	return int(i), true
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if jsonEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEqual compares two decoded JSON values, treating numbers by value.
+func jsonEqual(a, b interface{}) bool {
+	an, aIsNum := a.(json.Number)
+	bn, bIsNum := b.(json.Number)
+	if aIsNum && bIsNum {
+		ar, ok1 := new(big.Rat).SetString(an.String())
+		br, ok2 := new(big.Rat).SetString(bn.String())
+		return ok1 && ok2 && ar.Cmp(br) == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
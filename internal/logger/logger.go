@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -36,10 +37,70 @@ func (f *GinStyleFormatter) Format(entry *log.Entry) ([]byte, error) {
 		levelColor, timestamp, level, resetColor, entry.Message)), nil
 }
 
-func init() {
+// Environment variables read by Configure to configure logging without code changes.
+const (
+	// LevelEnv selects the log level: trace, debug, info, warn, error, fatal or panic.
+	LevelEnv = "MCP_LOG_LEVEL"
+	// FormatEnv selects the output format: "text" (the default, Gin style) or "json".
+	FormatEnv = "MCP_LOG_FORMAT"
+)
+
+var configureOnce sync.Once
+
+// Configure sets up logrus's standard logger for the SDK. It runs once, the first time
+// it is called; mcp.NewServer calls it, so the setup happens when the first server is
+// created rather than when the package is loaded.
+//
+// An application that configures the standard logger itself should do so before
+// creating its first server: a logger still at logrus's defaults at that point gets
+// this package's Gin-style text on stdout at info level, while one already configured
+// is left alone. MCP_LOG_LEVEL and MCP_LOG_FORMAT are explicit settings, so they apply
+// either way.
+func Configure() {
+	configureOnce.Do(func() {
+		for _, warning := range configure(log.StandardLogger(), os.Getenv) {
+			log.Warn(warning)
+		}
+	})
+}
+
+// configure applies the defaults and the environment settings read through getenv to
+// logger, and returns warnings about settings it ignored.
+func configure(logger *log.Logger, getenv func(string) string) []string {
+	// Leave a global logger that the application has already set up alone; only a
+	// logger still at logrus's defaults gets this package's defaults.
+	if isDefaultLogger(logger) {
+		logger.SetOutput(os.Stdout)
+		logger.SetFormatter(&GinStyleFormatter{})
+		logger.SetReportCaller(false) // Remove file:line
+		logger.SetLevel(log.InfoLevel)
+	}
 
-	log.SetOutput(os.Stdout)
-	log.SetFormatter(&GinStyleFormatter{})
-	log.SetReportCaller(false) // Remove file:line
-	log.SetLevel(log.InfoLevel)
-}
\ No newline at end of file
+	// Settings made through the environment are explicit, so they always apply.
+	var warnings []string
+	if value := strings.TrimSpace(getenv(LevelEnv)); value != "" {
+		if level, err := log.ParseLevel(value); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Ignoring %s=%q: not a valid log level", LevelEnv, value))
+		} else {
+			logger.SetLevel(level)
+		}
+	}
+	if value := strings.TrimSpace(getenv(FormatEnv)); value != "" {
+		switch strings.ToLower(value) {
+		case "text":
+			logger.SetFormatter(&GinStyleFormatter{})
+		case "json":
+			logger.SetFormatter(&log.JSONFormatter{})
+		default:
+			warnings = append(warnings, fmt.Sprintf("Ignoring %s=%q: format must be \"text\" or \"json\"", FormatEnv, value))
+		}
+	}
+	return warnings
+}
+
+// isDefaultLogger reports whether logger still looks as logrus creates it: writing to
+// stderr through a text formatter, at info level, without caller reporting.
+func isDefaultLogger(logger *log.Logger) bool {
+	_, textFormatter := logger.Formatter.(*log.TextFormatter)
+	return logger.Out == os.Stderr && textFormatter && logger.Level == log.InfoLevel && !logger.ReportCaller
+}
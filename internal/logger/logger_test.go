@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup adjusts a logger at logrus's defaults before configure runs.
+		setup         func(l *log.Logger)
+		env           map[string]string
+		wantLevel     log.Level
+		wantFormatter interface{}
+		wantStdout    bool
+		wantWarnings  int
+	}{
+		{"defaults", nil, nil, log.InfoLevel, &GinStyleFormatter{}, true, 0},
+		{"level from env", nil, map[string]string{LevelEnv: "debug"}, log.DebugLevel, &GinStyleFormatter{}, true, 0},
+		{"json from env", nil, map[string]string{FormatEnv: "JSON"}, log.InfoLevel, &log.JSONFormatter{}, true, 0},
+		{"invalid values", nil, map[string]string{LevelEnv: "loud", FormatEnv: "xml"}, log.InfoLevel, &GinStyleFormatter{}, true, 2},
+		{"configured logger kept", func(l *log.Logger) {
+			l.SetOutput(&bytes.Buffer{})
+			l.SetFormatter(&log.JSONFormatter{})
+		}, nil, log.InfoLevel, &log.JSONFormatter{}, false, 0},
+		{"env overrides configured logger", func(l *log.Logger) {
+			l.SetLevel(log.ErrorLevel)
+		}, map[string]string{LevelEnv: "warn", FormatEnv: "text"}, log.WarnLevel, &GinStyleFormatter{}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := log.New()
+			if tt.setup != nil {
+				tt.setup(l)
+			}
+			warnings := configure(l, func(key string) string { return tt.env[key] })
+
+			if l.Level != tt.wantLevel {
+				t.Errorf("level = %v, want %v", l.Level, tt.wantLevel)
+			}
+			if got, want := typeName(l.Formatter), typeName(tt.wantFormatter); got != want {
+				t.Errorf("formatter = %s, want %s", got, want)
+			}
+			if got := l.Out == os.Stdout; got != tt.wantStdout {
+				t.Errorf("writes to stdout = %v, want %v", got, tt.wantStdout)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case *GinStyleFormatter:
+		return "GinStyleFormatter"
+	case *log.JSONFormatter:
+		return "JSONFormatter"
+	case *log.TextFormatter:
+		return "TextFormatter"
+	}
+	return "unknown"
+}
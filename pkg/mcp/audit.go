@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AuditRecord describes a single tool invocation.
+// Arguments are never stored raw; only a keyed hash of their JSON encoding is kept.
+type AuditRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	SessionID string    `json:"sessionId,omitempty"`
+	Tool      string    `json:"tool"`
+	// ArgumentsHash is the hex-encoded HMAC-SHA256 of the arguments under the server's
+	// audit key, so equal arguments can be matched without being guessable from the log.
+	ArgumentsHash string        `json:"argumentsHash"`
+	Duration      time.Duration `json:"duration"`
+	Success       bool          `json:"success"`
+	// Rejected is set for calls refused before the tool ran, such as calls to unknown
+	// tools, with invalid arguments, or over a concurrency limit.
+	Rejected bool   `json:"rejected,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// AuditSink receives a record after every tool invocation, and for every call that is
+// rejected before reaching its tool. Implementations must be safe for concurrent use.
+type AuditSink interface {
+	Record(record AuditRecord) error
+}
+
+// WithAuditSink sends an AuditRecord to sink after every tool call, including calls
+// rejected before reaching their tool.
+func WithAuditSink(sink AuditSink) ServerOption {
+	return func(s *Server) {
+		s.auditSink = sink
+	}
+}
+
+// WithAuditKey sets the key of the HMAC that AuditRecord.ArgumentsHash is computed with.
+// Use the same secret key across restarts and replicas to match arguments between their
+// logs. Without it, each server uses a random key of its own, so hashes can only be
+// compared within one server's lifetime.
+func WithAuditKey(key []byte) ServerOption {
+	return func(s *Server) {
+		s.auditKey = append([]byte(nil), key...)
+	}
+}
+
+// newAuditKey returns a random key for hashing audited arguments.
+func newAuditKey() []byte {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("mcp: could not generate audit key: %v", err))
+	}
+	return key
+}
+
+// FileAuditSink is an AuditSink that appends records to a file as JSON lines.
+type FileAuditSink struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewFileAuditSink opens (or creates) the file at path for appending audit records.
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log %s: %w", path, err)
+	}
+	return &FileAuditSink{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Record appends a single record to the audit file.
+func (f *FileAuditSink) Record(record AuditRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.encoder.Encode(record)
+}
+
+// Close closes the underlying file.
+func (f *FileAuditSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// recordAudit builds an AuditRecord for a finished tool call and hands it to the configured sink.
+func (s *Server) recordAudit(ctx context.Context, tool string, args interface{}, start time.Time, callErr error) {
+	if s.auditSink == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Timestamp:     start,
+		SessionID:     SessionIDFromContext(ctx),
+		Tool:          tool,
+		ArgumentsHash: hashArguments(s.auditKey, args),
+		Duration:      time.Since(start),
+		Success:       callErr == nil,
+	}
+	if callErr != nil {
+		record.Error = callErr.Error()
+	}
+	s.writeAudit(record)
+}
+
+// rejectCall answers a tools/call refused before its tool ran with a JSON-RPC error
+// response, and audits it as a rejected call.
+func (s *Server) rejectCall(ctx context.Context, w http.ResponseWriter, id protocol.RequestID, call *protocol.CallToolRequest, code int, message string, err error) {
+	if s.auditSink != nil {
+		reason := message
+		if err != nil && !strings.Contains(message, err.Error()) {
+			reason += ": " + err.Error()
+		}
+		s.writeAudit(AuditRecord{
+			Timestamp:     time.Now(),
+			SessionID:     SessionIDFromContext(ctx),
+			Tool:          call.Name,
+			ArgumentsHash: hashArguments(s.auditKey, call.Arguments),
+			Rejected:      true,
+			Error:         reason,
+		})
+	}
+	s.writeErrorResponse(w, id, code, message, err)
+}
+
+func (s *Server) writeAudit(record AuditRecord) {
+	if err := s.auditSink.Record(record); err != nil {
+		log.Errorf("Failed to write audit record for tool '%s': %v", record.Tool, err)
+	}
+}
+
+// hashArguments returns the hex-encoded HMAC-SHA256 under key of the JSON encoding of args.
+func hashArguments(key []byte, args interface{}) string {
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(argsBytes)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type auditedInput struct {
+	Value string `json:"value" pattern:"^[a-z]+$"`
+}
+
+func TestAuditRecords(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       string
+		wantTool     string
+		wantSuccess  bool
+		wantRejected bool
+		wantError    string
+	}{
+		{"success", `{"name":"audited","arguments":{"value":"ok"}}`, "audited", true, false, ""},
+		{"tool error", `{"name":"audited","arguments":{"value":"fail"}}`, "audited", false, false, "failed on purpose"},
+		{"unknown tool", `{"name":"missing","arguments":{}}`, "missing", false, true, "Tool not found: missing"},
+		{"invalid arguments", `{"name":"audited","arguments":{"value":7}}`, "audited", false, true, "Invalid arguments for tool audited"},
+		{"pattern mismatch", `{"name":"audited","arguments":{"value":"NO"}}`, "audited", false, true, "must match the pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &memoryAuditSink{}
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "audited", Description: "Fails on request."},
+				Handler: func(ctx context.Context, in *auditedInput) (string, error) {
+					if in.Value == "fail" {
+						return "", errors.New("failed on purpose")
+					}
+					return in.Value, nil
+				},
+			}}, WithAuditSink(sink))
+			c := mcptest.NewClient(t, s)
+			c.Call("tools/call", json.RawMessage(tt.params))
+
+			records := sink.all()
+			if len(records) != 1 {
+				t.Fatalf("got %d audit records, want 1: %+v", len(records), records)
+			}
+			record := records[0]
+			if record.Tool != tt.wantTool || record.Success != tt.wantSuccess || record.Rejected != tt.wantRejected {
+				t.Errorf("record = %+v, want tool %s, success %v, rejected %v", record, tt.wantTool, tt.wantSuccess, tt.wantRejected)
+			}
+			if !strings.Contains(record.Error, tt.wantError) {
+				t.Errorf("Error = %q, want it to contain %q", record.Error, tt.wantError)
+			}
+			if record.SessionID != c.SessionID() || record.ArgumentsHash == "" {
+				t.Errorf("record = %+v, want session %s and an arguments hash", record, c.SessionID())
+			}
+		})
+	}
+}
+
+func TestAuditArgumentsHashIsKeyed(t *testing.T) {
+	args := map[string]string{"value": "secret"}
+	hashWith := func(opts ...ServerOption) string {
+		sink := &memoryAuditSink{}
+		s := newTestServer(t, []ToolRegistration{{
+			Definition: protocol.Tool{Name: "audited", Description: "Echoes its input."},
+			Handler:    func(ctx context.Context, in *auditedInput) (string, error) { return in.Value, nil },
+		}}, append(opts, WithAuditSink(sink))...)
+		mcptest.CallTool(t, s, "audited", args)
+		return sink.all()[0].ArgumentsHash
+	}
+
+	encoded, _ := json.Marshal(args)
+	unsalted := sha256.Sum256(encoded)
+	key := []byte("audit key")
+	tests := []struct {
+		name      string
+		a, b      string
+		wantEqual bool
+	}{
+		{"same key", hashWith(WithAuditKey(key)), hashWith(WithAuditKey(key)), true},
+		{"different keys", hashWith(WithAuditKey(key)), hashWith(WithAuditKey([]byte("other key"))), false},
+		{"random keys", hashWith(), hashWith(), false},
+		{"not a plain SHA-256", hashWith(WithAuditKey(key)), hex.EncodeToString(unsalted[:]), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.a == tt.b) != tt.wantEqual {
+				t.Errorf("hashes %s and %s: equal = %v, want %v", tt.a, tt.b, tt.a == tt.b, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestFileAuditSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileAuditSink(path)
+	if err != nil {
+		t.Fatalf("NewFileAuditSink: %v", err)
+	}
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "audited", Description: "Echoes its input."},
+		Handler:    func(ctx context.Context, in *auditedInput) (string, error) { return in.Value, nil },
+	}}, WithAuditSink(sink))
+	c := mcptest.NewClient(t, s)
+	c.CallTool("audited", map[string]string{"value": "one"})
+	c.Call("tools/call", map[string]interface{}{"name": "missing"})
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log has %d lines, want 2:\n%s", len(lines), data)
+	}
+	if strings.Contains(string(data), "one") {
+		t.Errorf("audit log contains raw arguments:\n%s", data)
+	}
+	var rejected AuditRecord
+	if err := json.Unmarshal([]byte(lines[1]), &rejected); err != nil || !rejected.Rejected || rejected.Tool != "missing" {
+		t.Errorf("second record = %+v (%v), want a rejected call to missing", rejected, err)
+	}
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// WithResultCache caches the results of tools registered as Cacheable, keyed by the tool
+// name and its arguments. Results of session tools and of tools from a ToolProvider are
+// only shared within the session that produced them, since another session may see a
+// different tool under the same name. Cache hits are audited and counted in metrics like
+// other calls, and wait for the same concurrency limits. Entries expire after ttl (zero
+// means never) and, once maxEntries results are cached, the least recently used is
+// evicted. Only successful results are cached.
+func WithResultCache(ttl time.Duration, maxEntries int) ServerOption {
+	return func(s *Server) {
+		if maxEntries <= 0 {
+			s.resultCache = nil
+			return
+		}
+		s.resultCache = newResultCache(ttl, maxEntries)
+	}
+}
+
+// resultCache is a size-bounded LRU cache of tool results with a time-to-live.
+type resultCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	result  *protocol.CallToolResult
+	expires time.Time
+}
+
+func newResultCache(ttl time.Duration, maxEntries int) *resultCache {
+	return &resultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the cached result for key, if present and not expired.
+func (c *resultCache) get(key string) (*protocol.CallToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return cloneResult(entry.result), true
+}
+
+// put stores a copy of result under key, evicting the least recently used entry if full.
+func (c *resultCache) put(key string, result *protocol.CallToolResult) {
+	entry := &cacheEntry{key: key, result: cloneResult(result)}
+	// Metadata such as deprecation warnings belongs to the call that produced it.
+	entry.result.Meta = nil
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cloneResult copies a result deeply enough that callers, such as result interceptors,
+// can modify the copy without affecting the cached value.
+func cloneResult(result *protocol.CallToolResult) *protocol.CallToolResult {
+	clone := *result
+	clone.Content = append([]protocol.ContentBlock(nil), result.Content...)
+	if result.Meta != nil {
+		clone.Meta = make(map[string]interface{}, len(result.Meta))
+		for k, v := range result.Meta {
+			clone.Meta[k] = v
+		}
+	}
+	return &clone
+}
+
+// resultCacheKey hashes a tool name and its arguments, together with the calling session
+// for tools that are not registered globally. The arguments are re-encoded first so that
+// calls differing only in key order or whitespace share an entry.
+func resultCacheKey(origin toolOrigin, sessionID, tool string, args json.RawMessage) string {
+	canonical := []byte(args)
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(args))
+	decoder.UseNumber()
+	if len(args) > 0 && decoder.Decode(&decoded) == nil {
+		if encoded, err := json.Marshal(decoded); err == nil {
+			canonical = encoded
+		}
+	}
+	if origin == originGlobal {
+		sessionID = ""
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00%s\x00", origin, sessionID)
+	hash.Write([]byte(tool))
+	hash.Write([]byte{0})
+	hash.Write(canonical)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type echoInput struct {
+	Value string `json:"value"`
+}
+
+func TestResultCacheIsolatesSessionTools(t *testing.T) {
+	s := newTestServer(t, nil, WithResultCache(time.Minute, 10))
+	first := mcptest.NewClient(t, s)
+	second := mcptest.NewClient(t, s)
+
+	for _, c := range []struct {
+		client *mcptest.Client
+		tenant string
+	}{{first, "tenant-a"}, {second, "tenant-b"}} {
+		tenant := c.tenant
+		err := s.RegisterSessionTools(c.client.SessionID(), []ToolRegistration{{
+			Definition: protocol.Tool{Name: "whoami", Description: "Names the tenant."},
+			Handler:    func(in echoInput) (string, error) { return tenant, nil },
+			Cacheable:  true,
+		}})
+		if err != nil {
+			t.Fatalf("RegisterSessionTools: %v", err)
+		}
+	}
+
+	tests := []struct {
+		client *mcptest.Client
+		want   string
+	}{
+		{first, "tenant-a"},
+		{second, "tenant-b"},
+		{first, "tenant-a"},
+	}
+	for i, tt := range tests {
+		if got := textOf(t, tt.client.CallTool("whoami", echoInput{Value: "x"})); got != tt.want {
+			t.Errorf("call %d: got %q, want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestResultCacheSharesGlobalTools(t *testing.T) {
+	calls := 0
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "count", Description: "Counts calls."},
+		Handler:    func(in echoInput) (int, error) { calls++; return calls, nil },
+		Cacheable:  true,
+	}}, WithResultCache(time.Minute, 10))
+
+	for i := 0; i < 3; i++ {
+		if got := textOf(t, mcptest.NewClient(t, s).CallTool("count", echoInput{Value: "x"})); got != "1" {
+			t.Errorf("call %d: got %q, want the cached %q", i, got, "1")
+		}
+	}
+}
+
+func TestResultCacheHitsAreAudited(t *testing.T) {
+	sink := &memoryAuditSink{}
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "echo", Description: "Echoes its input."},
+		Handler:    func(ctx context.Context, in echoInput) (string, error) { return in.Value, nil },
+		Cacheable:  true,
+	}}, WithResultCache(time.Minute, 10), WithAuditSink(sink), WithPrometheusMetrics())
+	c := mcptest.NewClient(t, s)
+
+	c.CallTool("echo", echoInput{Value: "a"})
+	c.CallTool("echo", echoInput{Value: "a"})
+
+	records := sink.all()
+	if len(records) != 2 {
+		t.Fatalf("got %d audit records, want 2 (one for the cache hit)", len(records))
+	}
+	for _, record := range records {
+		if !record.Success || record.Tool != "echo" {
+			t.Errorf("unexpected record %+v", record)
+		}
+	}
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+	if got := s.metrics.toolCalls[toolOutcome{tool: "echo", outcome: "success"}]; got != 2 {
+		t.Errorf("metrics counted %d calls, want 2", got)
+	}
+}
+
+func TestResultCacheKey(t *testing.T) {
+	args := []byte(`{"a": 1, "b": 2}`)
+	tests := []struct {
+		name  string
+		other string
+		same  bool
+	}{
+		{"global tools ignore the session", resultCacheKey(originGlobal, "s2", "t", args), true},
+		{"key order is irrelevant", resultCacheKey(originGlobal, "s1", "t", []byte(`{"b":2,"a":1}`)), true},
+		{"session tools are per session", resultCacheKey(originSession, "s1", "t", args), false},
+		{"provider tools differ from global", resultCacheKey(originProvider, "s1", "t", args), false},
+		{"different tools differ", resultCacheKey(originGlobal, "s1", "u", args), false},
+	}
+	base := resultCacheKey(originGlobal, "s1", "t", args)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.other == base; got != tt.same {
+				t.Errorf("keys equal = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
+func TestResultCacheHitMissExpiry(t *testing.T) {
+	result := func(text string) *protocol.CallToolResult {
+		return &protocol.CallToolResult{Content: []protocol.ContentBlock{{Type: "text", Text: text}}}
+	}
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		maxEntries int
+		// fill runs against the cache before key is looked up.
+		fill     func(c *resultCache)
+		key      string
+		wantHit  bool
+		wantText string
+	}{
+		{"hit", time.Minute, 10, func(c *resultCache) { c.put("a", result("A")) }, "a", true, "A"},
+		{"miss", time.Minute, 10, func(c *resultCache) { c.put("a", result("A")) }, "b", false, ""},
+		{"replaced", time.Minute, 10, func(c *resultCache) {
+			c.put("a", result("A"))
+			c.put("a", result("A2"))
+		}, "a", true, "A2"},
+		{"expired", 10 * time.Millisecond, 10, func(c *resultCache) {
+			c.put("a", result("A"))
+			time.Sleep(20 * time.Millisecond)
+		}, "a", false, ""},
+		{"no ttl", 0, 10, func(c *resultCache) {
+			c.put("a", result("A"))
+			time.Sleep(20 * time.Millisecond)
+		}, "a", true, "A"},
+		{"least recently used evicted", time.Minute, 2, func(c *resultCache) {
+			c.put("a", result("A"))
+			c.put("b", result("B"))
+			c.get("a")
+			c.put("c", result("C"))
+		}, "b", false, ""},
+		{"recently used kept", time.Minute, 2, func(c *resultCache) {
+			c.put("a", result("A"))
+			c.put("b", result("B"))
+			c.get("a")
+			c.put("c", result("C"))
+		}, "a", true, "A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newResultCache(tt.ttl, tt.maxEntries)
+			tt.fill(c)
+			got, hit := c.get(tt.key)
+			if hit != tt.wantHit {
+				t.Fatalf("get(%q) hit = %v, want %v", tt.key, hit, tt.wantHit)
+			}
+			if hit && textOf(t, got) != tt.wantText {
+				t.Errorf("get(%q) = %q, want %q", tt.key, textOf(t, got), tt.wantText)
+			}
+		})
+	}
+}
+
+func TestResultCacheOnlyCachesSuccessfulCacheableCalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		cacheable bool
+		fail      bool
+		wantCalls int
+	}{
+		{"cacheable", true, false, 1},
+		{"not cacheable", false, false, 3},
+		{"tool error", true, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "lookup", Description: "Looks up a value."},
+				Handler: func(in echoInput) (string, error) {
+					calls++
+					if tt.fail {
+						return "", errors.New("backend down")
+					}
+					return in.Value, nil
+				},
+				Cacheable: tt.cacheable,
+			}}, WithResultCache(time.Minute, 10))
+			c := mcptest.NewClient(t, s)
+			for i := 0; i < 3; i++ {
+				c.CallTool("lookup", echoInput{Value: "x"})
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"encoding/json"
+)
+
+// Codec encodes and decodes the JSON-RPC envelopes exchanged over POST requests.
+//
+// The envelope structure is unchanged whatever the codec: params, results and tool
+// arguments are still carried as JSON (json.RawMessage) inside it. The protocol types
+// rely on encoding/json hooks, so a binary codec such as MessagePack usually transcodes
+// to and from JSON at the boundary, honouring json.Marshaler, json.Unmarshaler and
+// json.RawMessage. Server-sent event streams are a text format and always use JSON.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	// ContentType is the media type set on responses, e.g. "application/json".
+	ContentType() string
+}
+
+// JSONCodec is the default Codec, using encoding/json.
+type JSONCodec struct{}
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into v.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// ContentType returns "application/json".
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+// WithCodec sets the codec used for request and response bodies. Only the JSON-RPC
+// envelope is swapped: params and results remain JSON, held as json.RawMessage, inside
+// whatever framing the codec uses. A deployment uses a single codec for all clients; it
+// is not negotiated per request, so clients must be configured to match, and responses
+// are never streamed as server-sent events.
+func WithCodec(codec Codec) ServerOption {
+	return func(s *Server) {
+		if codec != nil {
+			s.codec = codec
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// base64Codec frames each message as base64-encoded JSON, standing in for a binary
+// codec that transcodes to and from JSON at the boundary.
+type base64Codec struct{}
+
+func (base64Codec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(data)), nil
+}
+
+func (base64Codec) Unmarshal(data []byte, v interface{}) error {
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, v)
+}
+
+func (base64Codec) ContentType() string {
+	return "application/x-mcp-base64"
+}
+
+func TestCustomCodec(t *testing.T) {
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "echo", Description: "Echoes its input."},
+		Handler:    func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil },
+	}}, WithCodec(base64Codec{}))
+	send := func(t *testing.T, sessionID, body string) (*httptest.ResponseRecorder, protocol.Response) {
+		t.Helper()
+		encoded := base64.StdEncoding.EncodeToString([]byte(body))
+		if !json.Valid([]byte(body)) {
+			// Sent as is, to check how undecodable bodies are answered.
+			encoded = body
+		}
+		// Ask for an event stream too: the server must still answer in its codec.
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(encoded))
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		var resp protocol.Response
+		if rec.Body.Len() > 0 {
+			if err := (base64Codec{}).Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response %q: %v", rec.Body.String(), err)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/x-mcp-base64" {
+				t.Errorf("Content-Type = %q, want the codec's", got)
+			}
+		}
+		return rec, resp
+	}
+
+	rec, resp := send(t, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"c","version":"1"}}}`)
+	sessionID := rec.Header().Get("Mcp-Session-Id")
+	if resp.Error != nil || sessionID == "" {
+		t.Fatalf("initialize failed: %+v", resp.Error)
+	}
+	send(t, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantText string
+		wantCode int
+	}{
+		{"success", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"value":"hi"}}}`, "hi", 0},
+		{"method error", `{"jsonrpc":"2.0","id":3,"method":"no/such/method"}`, "", -32601},
+		{"tool error", `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"missing"}}`, "", -32602},
+		{"undecodable body", `{"jsonrpc":"2.0","id":5`, "", -32700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, resp := send(t, sessionID, tt.body)
+			if tt.wantCode != 0 {
+				if resp.Error == nil || resp.Error.Code != tt.wantCode {
+					t.Errorf("error = %+v, want code %d", resp.Error, tt.wantCode)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("call failed: %+v", resp.Error)
+			}
+			// Results stay JSON inside the envelope.
+			var result protocol.CallToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatalf("result is not JSON: %v", err)
+			}
+			if got := textOf(t, &result); got != tt.wantText {
+				t.Errorf("result = %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
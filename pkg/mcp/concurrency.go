@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// errServerBusy is returned when a tool call cannot run or queue because the server is at capacity.
+var errServerBusy = errors.New("server busy: too many concurrent tool calls")
+
+// errToolBusy is returned when a tool call cannot run or queue because the tool is at its
+// own MaxConcurrent limit.
+var errToolBusy = errors.New("tool busy: too many concurrent calls to this tool")
+
+// WithMaxConcurrency caps how many tool handlers run at once across all sessions.
+// When all n slots are taken, up to queueDepth further calls wait for a slot (or for
+// their request to be cancelled); calls beyond that are rejected immediately with a
+// "server busy" error. A queueDepth of zero rejects as soon as the limit is reached.
+// n <= 0 leaves concurrency unlimited, which is the default.
+func WithMaxConcurrency(n, queueDepth int) ServerOption {
+	return func(s *Server) {
+		s.limiter = newExecutionLimiter(n, queueDepth, errServerBusy)
+	}
+}
+
+// newExecutionLimiter returns a limiter allowing n concurrent executions with up to
+// queueDepth waiting, or nil if n <= 0. Calls that can neither run nor wait fail with busy.
+func newExecutionLimiter(n, queueDepth int, busy error) *executionLimiter {
+	if n <= 0 {
+		return nil
+	}
+	if queueDepth < 0 {
+		queueDepth = 0
+	}
+	return &executionLimiter{
+		slots:      make(chan struct{}, n),
+		queueDepth: int64(queueDepth),
+		busy:       busy,
+	}
+}
+
+// executionLimiter is a counting semaphore with a bounded wait queue.
+type executionLimiter struct {
+	slots      chan struct{}
+	queueDepth int64
+	waiting    atomic.Int64
+	busy       error
+}
+
+// acquire takes a slot, waiting in the queue if there is room in it.
+// Every successful acquire must be paired with a release.
+func (l *executionLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if l.waiting.Add(1) > l.queueDepth {
+		l.waiting.Add(-1)
+		return l.busy
+	}
+	defer l.waiting.Add(-1)
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *executionLimiter) release() {
+	<-l.slots
+}
@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t testing.TB, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit, queue  int
+		calls         int
+		wantCompleted int
+	}{
+		{"under the limit", 4, 0, 3, 3},
+		{"reject at the limit", 2, 0, 5, 2},
+		{"queue then reject", 2, 2, 6, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running atomic.Int64
+			release := make(chan struct{})
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "slow", Description: "Waits to be released."},
+				Handler: func(ctx context.Context, in *echoInput) (string, error) {
+					running.Add(1)
+					<-release
+					return "done", nil
+				},
+			}}, WithMaxConcurrency(tt.limit, tt.queue))
+			sessionID := mcptest.NewClient(t, s).SessionID()
+
+			type outcome struct {
+				status int
+				body   string
+			}
+			outcomes := make(chan outcome, tt.calls)
+			for i := 0; i < tt.calls; i++ {
+				go func() {
+					rec := post(t, s, sessionID, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{"value":"x"}}}`)
+					outcomes <- outcome{rec.Code, rec.Body.String()}
+				}()
+			}
+
+			wantRejected := tt.calls - tt.wantCompleted
+			for i := 0; i < wantRejected; i++ {
+				select {
+				case o := <-outcomes:
+					if o.status == http.StatusOK || !strings.Contains(o.body, "Server busy") {
+						t.Errorf("call over the limit answered %d %s, want a busy error", o.status, o.body)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("only %d of %d calls over the limit were rejected", i, wantRejected)
+				}
+			}
+			wantRunning := int64(min(tt.limit, tt.calls))
+			waitFor(t, "handlers to start", func() bool { return running.Load() == wantRunning })
+
+			close(release)
+			for i := 0; i < tt.wantCompleted; i++ {
+				if o := <-outcomes; o.status != http.StatusOK || !strings.Contains(o.body, "done") {
+					t.Errorf("admitted call answered %d %s", o.status, o.body)
+				}
+			}
+			if got := running.Load(); got != int64(tt.wantCompleted) {
+				t.Errorf("%d handlers ran, want %d", got, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestExecutionLimiterQueueGivesUpOnCancel(t *testing.T) {
+	l := newExecutionLimiter(1, 1, errServerBusy)
+	if err := l.acquire(context.Background()); err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan error, 1)
+	go func() { queued <- l.acquire(ctx) }()
+	waitFor(t, "the call to queue", func() bool { return l.waiting.Load() == 1 })
+
+	cancel()
+	if err := <-queued; err != context.Canceled {
+		t.Errorf("queued acquire returned %v, want context.Canceled", err)
+	}
+	if got := l.waiting.Load(); got != 0 {
+		t.Errorf("%d calls still waiting after cancel, want 0", got)
+	}
+}
+
+func TestToolMaxConcurrent(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit, queue int
+		calls        int
+		wantRejected int
+	}{
+		{"serialized", 1, 64, 64, 0},
+		{"bounded", 3, 64, 64, 0},
+		{"rejected past the queue", 2, 3, 9, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak, limitedStarted atomic.Int64
+			release := make(chan struct{})
+			s := newTestServer(t, []ToolRegistration{
+				{
+					Definition: protocol.Tool{Name: "device", Description: "Talks to a serial device."},
+					Handler: func(ctx context.Context, in *echoInput) (string, error) {
+						limitedStarted.Add(1)
+						now := running.Add(1)
+						for {
+							old := peak.Load()
+							if now <= old || peak.CompareAndSwap(old, now) {
+								break
+							}
+						}
+						if tt.wantRejected > 0 {
+							<-release
+						} else {
+							time.Sleep(time.Millisecond)
+						}
+						running.Add(-1)
+						return "done", nil
+					},
+					MaxConcurrent: tt.limit,
+					MaxQueued:     tt.queue,
+				},
+				{Definition: protocol.Tool{Name: "other", Description: "An unlimited tool."}, Handler: replyWith("other")},
+			})
+			sessionID := mcptest.NewClient(t, s).SessionID()
+
+			type outcome struct {
+				status int
+				body   string
+			}
+			outcomes := make(chan outcome, tt.calls)
+			for i := 0; i < tt.calls; i++ {
+				go func() {
+					rec := post(t, s, sessionID, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"device","arguments":{"value":"x"}}}`)
+					outcomes <- outcome{rec.Code, rec.Body.String()}
+				}()
+			}
+
+			for i := 0; i < tt.wantRejected; i++ {
+				select {
+				case o := <-outcomes:
+					if !strings.Contains(o.body, "is busy") {
+						t.Errorf("call over the limit answered %d %s, want a busy error", o.status, o.body)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("only %d of %d calls over the queue were rejected", i, tt.wantRejected)
+				}
+			}
+			if tt.wantRejected > 0 {
+				waitFor(t, "handlers to start", func() bool { return limitedStarted.Load() == int64(tt.limit) })
+			}
+			// Other tools keep running while this one is at its limit.
+			if result := mcptest.CallTool(t, s, "other", map[string]string{"value": "x"}); textOf(t, result) != "other" {
+				t.Errorf("other tool answered %q", textOf(t, result))
+			}
+
+			close(release)
+			for i := 0; i < tt.calls-tt.wantRejected; i++ {
+				if o := <-outcomes; o.status != http.StatusOK || !strings.Contains(o.body, "done") {
+					t.Errorf("admitted call answered %d %s", o.status, o.body)
+				}
+			}
+			if got := peak.Load(); got > int64(tt.limit) {
+				t.Errorf("%d calls ran at once, want at most %d", got, tt.limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package mcp
+
+import (
+	"context"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type contextKey int
+
+const (
+	sessionIDKey contextKey = iota
+	serverKey
+	requestIDKey
+	requestStreamKey
+	progressTokenKey
+	responseHeadersKey
+	acceptLanguageKey
+)
+
+// contextWithSessionID returns a copy of ctx carrying the caller's session id.
+func contextWithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey, sessionID)
+}
+
+// SessionIDFromContext returns the id of the session that issued the current request,
+// or an empty string if the request was made outside of a session.
+func SessionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey).(string)
+	return id
+}
+
+// contextWithRequest returns a copy of ctx carrying the server handling a request and the request's id.
+func contextWithRequest(ctx context.Context, s *Server, id protocol.RequestID) context.Context {
+	ctx = context.WithValue(ctx, serverKey, s)
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the JSON-RPC id of the request being handled, if any.
+func RequestIDFromContext(ctx context.Context) (protocol.RequestID, bool) {
+	id, ok := ctx.Value(requestIDKey).(protocol.RequestID)
+	return id, ok
+}
@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EchoToolName is the name of the built-in diagnostic tool enabled by WithDebugTools.
+const EchoToolName = "mcp/echo"
+
+// WithDebugTools registers built-in diagnostic tools, named with an "mcp/" prefix that
+// the server does not reserve: they are registered when the server is created, so a
+// tool registered later under the same name is rejected as a duplicate.
+// Currently this is EchoToolName, which returns the arguments it received verbatim
+// together with the caller's session id and negotiated protocol version, to help
+// verify a client's argument encoding and session handling. The server must advertise
+// the tools capability for clients to call it. Off by default; avoid enabling it in production.
+func WithDebugTools() ServerOption {
+	return func(s *Server) {
+		s.debugTools = true
+	}
+}
+
+// echoResult is what the echo tool reports back to the client.
+type echoResult struct {
+	Arguments       json.RawMessage `json:"arguments"`
+	SessionID       string          `json:"sessionId,omitempty"`
+	ProtocolVersion string          `json:"protocolVersion,omitempty"`
+}
+
+func (s *Server) registerDebugTools() {
+	echo := func(ctx context.Context, args *json.RawMessage) (echoResult, error) {
+		result := echoResult{Arguments: *args, SessionID: SessionIDFromContext(ctx)}
+		if len(result.Arguments) == 0 {
+			result.Arguments = json.RawMessage("{}")
+		}
+		if session := s.lookupSession(result.SessionID); session != nil {
+			result.ProtocolVersion = session.ProtocolVersion
+		}
+		return result, nil
+	}
+
+	err := s.RegisterTools([]ToolRegistration{{
+		Definition: protocol.Tool{
+			Name:        EchoToolName,
+			Title:       "Echo (debug)",
+			Description: "Returns the arguments it receives verbatim, with the session id and negotiated protocol version.",
+		},
+		Handler: echo,
+	}})
+	if err != nil {
+		log.Errorf("Failed to register debug tools: %v", err)
+	}
+}
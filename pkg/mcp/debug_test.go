@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestEchoTool(t *testing.T) {
+	tests := []struct {
+		name          string
+		arguments     string
+		wantArguments string
+	}{
+		{"object", `{"b":[1,2],"a":"x"}`, `{"b":[1,2],"a":"x"}`},
+		{"large number", `{"id":9007199254740993}`, `{"id":9007199254740993}`},
+		{"empty", `{}`, `{}`},
+	}
+	s := newTestServer(t, nil, WithDebugTools())
+	c := mcptest.NewClient(t, s)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := c.CallTool(EchoToolName, json.RawMessage(tt.arguments))
+			var echoed echoResult
+			if err := json.Unmarshal([]byte(textOf(t, result)), &echoed); err != nil {
+				t.Fatalf("decoding echo: %v", err)
+			}
+			if string(echoed.Arguments) != tt.wantArguments {
+				t.Errorf("arguments = %s, want %s", echoed.Arguments, tt.wantArguments)
+			}
+			if echoed.SessionID != c.SessionID() || echoed.ProtocolVersion != mcptest.ProtocolVersion {
+				t.Errorf("session %q, version %q; want %q, %q", echoed.SessionID, echoed.ProtocolVersion, c.SessionID(), mcptest.ProtocolVersion)
+			}
+		})
+	}
+}
+
+func TestEchoToolIsOffByDefault(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []ServerOption
+		wantAvailable bool
+	}{
+		{"default", nil, false},
+		{"debug tools", []ServerOption{WithDebugTools()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mcptest.NewClient(t, newTestServer(t, nil, tt.opts...))
+			resp := c.Call("tools/call", map[string]interface{}{"name": EchoToolName})
+			if called := resp.Error == nil; called != tt.wantAvailable {
+				t.Errorf("calling %s: error = %+v, want available %v", EchoToolName, resp.Error, tt.wantAvailable)
+			}
+		})
+	}
+}
+
+func TestDebugToolNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ServerOption
+		wantErr bool
+	}{
+		{"without debug tools", nil, false},
+		{"with debug tools", []ServerOption{WithDebugTools()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			err := s.RegisterTools([]ToolRegistration{{
+				Definition: protocol.Tool{Name: EchoToolName, Description: "Echoes its input."},
+				Handler:    replyWith("ok"),
+			}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("registering %s: error = %v, want error: %v", EchoToolName, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RegisterFromDefinitions registers tools whose definitions are kept as data, such as a
+// catalog file decoded into []protocol.Tool, binding each to the handler lookup returns
+// for its name. Handlers take the same forms as ToolRegistration.Handler.
+//
+// A definition's input schema, when present, is advertised in place of the one generated
+// from the handler's input type; arguments are still decoded into that type, so the two
+// should agree. The tools are registered all together, or not at all if any definition is
+// invalid, has no handler, or has a name that is already taken.
+func (s *Server) RegisterFromDefinitions(defs []protocol.Tool, lookup func(name string) interface{}) error {
+	if lookup == nil {
+		return fmt.Errorf("handler lookup must not be nil")
+	}
+
+	tools := make([]internalRegisteredTool, 0, len(defs))
+	names := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		if names[def.Name] {
+			return fmt.Errorf("failed to register tool '%s': tool is listed more than once", def.Name)
+		}
+		handler := lookup(def.Name)
+		if handler == nil {
+			return fmt.Errorf("failed to register tool '%s': no handler found", def.Name)
+		}
+		tool, err := s.buildTool(ToolRegistration{Definition: def, Handler: handler})
+		if err == nil {
+			err = s.checkDescription(tool.Definition)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to register tool '%s': %w", def.Name, err)
+		}
+		if len(def.InputSchema) > 0 {
+			if !json.Valid(def.InputSchema) {
+				return fmt.Errorf("failed to register tool '%s': input schema is not valid JSON", def.Name)
+			}
+			tool.Definition.InputSchema = def.InputSchema
+			tool.required = requiredArguments(def.InputSchema)
+		}
+		names[def.Name] = true
+		tools = append(tools, tool)
+	}
+
+	if err := s.installTools(tools); err != nil {
+		return err
+	}
+
+	log.Infof("Registered %d tools from definitions", len(tools))
+	if len(tools) > 0 {
+		s.notifyListChanged(listTools)
+	}
+	return nil
+}
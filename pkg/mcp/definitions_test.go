@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type addInput struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func TestRegisterFromDefinitions(t *testing.T) {
+	handlers := map[string]interface{}{
+		"add": func(ctx context.Context, in *addInput) (int, error) { return in.A + in.B, nil },
+		"echo": func(ctx context.Context, in *echoInput) (string, error) {
+			return in.Value, nil
+		},
+	}
+	lookup := func(name string) interface{} { return handlers[name] }
+	tests := []struct {
+		name      string
+		catalog   string
+		wantErr   string
+		wantTools []string
+	}{
+		{"catalog", `[
+			{"name":"add","description":"Adds two numbers.","inputSchema":{"type":"object","properties":{"a":{"type":"integer","description":"The first number."},"b":{"type":"integer"}},"required":["a","b"]}},
+			{"name":"echo","description":"Echoes a value."}
+		]`, "", []string{"add", "echo"}},
+		{"no handler", `[{"name":"add","description":"Adds two numbers."},{"name":"subtract","description":"Subtracts."}]`, "'subtract': no handler found", nil},
+		{"listed twice", `[{"name":"add","description":"Adds."},{"name":"add","description":"Adds again."}]`, "listed more than once", nil},
+		{"empty catalog", `[]`, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var defs []protocol.Tool
+			if err := json.Unmarshal([]byte(tt.catalog), &defs); err != nil {
+				t.Fatalf("decoding catalog: %v", err)
+			}
+			s := newTestServer(t, nil)
+			err := s.RegisterFromDefinitions(defs, lookup)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("RegisterFromDefinitions: %v", err)
+			}
+
+			s.toolLock.RLock()
+			registered := len(s.tools)
+			s.toolLock.RUnlock()
+			if registered != len(tt.wantTools) {
+				t.Fatalf("%d tools registered, want %v", registered, tt.wantTools)
+			}
+			for _, def := range defs {
+				tool, ok := s.lookupTool(context.Background(), def.Name)
+				if !ok {
+					continue
+				}
+				if len(def.InputSchema) > 0 && string(tool.Definition.InputSchema) != string(def.InputSchema) {
+					t.Errorf("%s schema = %s, want the catalog's %s", def.Name, tool.Definition.InputSchema, def.InputSchema)
+				}
+				if len(def.InputSchema) == 0 && len(tool.Definition.InputSchema) == 0 {
+					t.Errorf("%s has no generated schema", def.Name)
+				}
+			}
+			if registered > 0 {
+				if got := textOf(t, mcptest.CallTool(t, s, "add", map[string]int{"a": 2, "b": 3})); got != "5" {
+					t.Errorf("add = %q, want 5", got)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid schema", func(t *testing.T) {
+		s := newTestServer(t, nil)
+		err := s.RegisterFromDefinitions([]protocol.Tool{{Name: "add", Description: "Adds.", InputSchema: json.RawMessage(`{"type":`)}}, lookup)
+		if err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+			t.Errorf("error = %v, want an invalid schema error", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DynamicToolHandler implements every tool registered with RegisterDynamicTools. It is
+// given the name of the tool that was called and the call's arguments, undecoded, and
+// its result is formatted into a text content block as for ToolRegistration handlers,
+// unless it is a *protocol.CallToolResult, which is sent as built.
+type DynamicToolHandler func(ctx context.Context, name string, args json.RawMessage) (interface{}, error)
+
+// rawArgumentsType is the input type of dynamic tools: arguments are passed on as-is.
+var rawArgumentsType = reflect.TypeOf((*json.RawMessage)(nil))
+
+// defaultDynamicSchema is advertised for dynamic tools whose definition has no input schema.
+var defaultDynamicSchema = json.RawMessage(`{"type": "object", "properties": {}}`)
+
+// RegisterDynamicTools registers many tools that share one handler, which receives the
+// name of the tool being called; for example one tool per database table. Nothing is
+// allocated per tool beyond its definition, so this scales to thousands of tools.
+//
+// Each definition should carry its input schema, since there is no input type to
+// generate one from; a definition without one accepts any object. The tools are
+// registered all together, or not at all if any definition is invalid or its name is
+// already taken.
+func (s *Server) RegisterDynamicTools(defs []protocol.Tool, handler DynamicToolHandler) error {
+	if handler == nil {
+		return fmt.Errorf("dynamic tool handler must not be nil")
+	}
+	handlerVal := reflect.ValueOf(handler)
+
+	tools := make([]internalRegisteredTool, 0, len(defs))
+	names := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		if def.Name == "" {
+			return fmt.Errorf("tool definition must include a name")
+		}
+		if isReservedToolName(def.Name) {
+			return fmt.Errorf("failed to register tool '%s': tool name is reserved for a protocol method", def.Name)
+		}
+		if names[def.Name] {
+			return fmt.Errorf("failed to register tool '%s': tool is listed more than once", def.Name)
+		}
+		if def.Version != "" && !semverPattern.MatchString(def.Version) {
+			return fmt.Errorf("failed to register tool '%s': tool version '%s' is not a valid semantic version", def.Name, def.Version)
+		}
+		if err := s.checkDescription(def); err != nil {
+			return fmt.Errorf("failed to register tool '%s': %w", def.Name, err)
+		}
+		if len(def.InputSchema) == 0 {
+			def.InputSchema = defaultDynamicSchema
+		} else if !json.Valid(def.InputSchema) {
+			return fmt.Errorf("failed to register tool '%s': input schema is not valid JSON", def.Name)
+		}
+		names[def.Name] = true
+		tools = append(tools, internalRegisteredTool{
+			Definition:   def,
+			handlerValue: handlerVal,
+			inputType:    rawArgumentsType,
+			inputByValue: true,
+			takesContext: true,
+			dynamic:      true,
+			required:     requiredArguments(def.InputSchema),
+		})
+	}
+
+	if err := s.installTools(tools); err != nil {
+		return err
+	}
+
+	log.Infof("Registered %d dynamic tools", len(tools))
+	if len(tools) > 0 {
+		s.notifyListChanged(listTools)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestRegisterDynamicTools(t *testing.T) {
+	tables := []string{"users", "orders", "invoices"}
+	var defs []protocol.Tool
+	for _, table := range tables {
+		defs = append(defs, protocol.Tool{Name: "count_" + table, Description: "Counts rows in " + table + "."})
+	}
+	s := newTestServer(t, nil)
+	err := s.RegisterDynamicTools(defs, func(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
+		if strings.HasSuffix(name, "invoices") {
+			return nil, fmt.Errorf("table %s is locked", strings.TrimPrefix(name, "count_"))
+		}
+		return fmt.Sprintf("%s %s", name, args), nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterDynamicTools: %v", err)
+	}
+	c := mcptest.NewClient(t, s)
+
+	tests := []struct {
+		tool      string
+		args      map[string]interface{}
+		wantText  string
+		wantError bool
+	}{
+		{"count_users", map[string]interface{}{"active": true}, `count_users {"active":true}`, false},
+		{"count_orders", map[string]interface{}{}, `count_orders {}`, false},
+		{"count_invoices", nil, "table invoices is locked", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			result := c.CallTool(tt.tool, tt.args)
+			if result.IsError != tt.wantError || !strings.Contains(textOf(t, result), tt.wantText) {
+				t.Errorf("result = %q (isError %v), want %q (isError %v)", textOf(t, result), result.IsError, tt.wantText, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestRegisterDynamicToolsRejectsBatch(t *testing.T) {
+	handler := func(ctx context.Context, name string, args json.RawMessage) (interface{}, error) { return "", nil }
+	tests := []struct {
+		name string
+		defs []protocol.Tool
+	}{
+		{"listed twice", []protocol.Tool{{Name: "a", Description: "A."}, {Name: "a", Description: "A."}}},
+		{"name taken", []protocol.Tool{{Name: "b", Description: "B."}, {Name: "existing", Description: "E."}}},
+		{"invalid schema", []protocol.Tool{{Name: "c", Description: "C.", InputSchema: json.RawMessage(`{`)}}},
+		{"reserved name", []protocol.Tool{{Name: "tools/list", Description: "R."}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			if err := s.RegisterDynamicTools([]protocol.Tool{{Name: "existing", Description: "E."}}, handler); err != nil {
+				t.Fatalf("RegisterDynamicTools: %v", err)
+			}
+			if err := s.RegisterDynamicTools(tt.defs, handler); err == nil {
+				t.Fatal("RegisterDynamicTools succeeded, want an error")
+			}
+			for _, def := range tt.defs {
+				if _, exists := s.lookupTool(context.Background(), def.Name); exists && def.Name != "existing" {
+					t.Errorf("tool %s registered from a rejected batch", def.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package mcp
+
+import (
+	"context"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// MethodHandler handles a JSON-RPC request the server has no built-in handler for.
+// The result is marshalled as the response's result. A *protocol.ErrorObject error is
+// returned to the client as-is; any other error is reported as an internal error.
+type MethodHandler func(ctx context.Context, req *protocol.Request) (interface{}, error)
+
+// SetUnknownMethodHandler installs handler for requests whose method the server does not
+// implement, so a gateway can proxy them upstream or an application can serve
+// experimental methods. Requests still go through session checks first. A nil handler
+// restores the default "Method not found" (-32601) response.
+func (s *Server) SetUnknownMethodHandler(handler MethodHandler) {
+	s.unknownMethodLock.Lock()
+	defer s.unknownMethodLock.Unlock()
+	s.unknownMethodHandler = handler
+}
+
+// unknownMethod returns the handler for unimplemented methods, or nil if none is set.
+func (s *Server) unknownMethod() MethodHandler {
+	s.unknownMethodLock.RLock()
+	defer s.unknownMethodLock.RUnlock()
+	return s.unknownMethodHandler
+}
@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestUnknownMethodHandler(t *testing.T) {
+	// experimental serves "experimental/echo" and turns everything else away.
+	experimental := func(ctx context.Context, req *protocol.Request) (interface{}, error) {
+		switch req.Method {
+		case "experimental/echo":
+			return map[string]interface{}{"params": req.Params, "session": SessionIDFromContext(ctx) != ""}, nil
+		case "experimental/broken":
+			return nil, errors.New("upstream unavailable")
+		}
+		return nil, &protocol.ErrorObject{Code: -32601, Message: "Method not found upstream"}
+	}
+	tests := []struct {
+		name        string
+		handler     MethodHandler
+		method      string
+		wantResult  string
+		wantCode    int
+		wantMessage string
+	}{
+		{"no handler", nil, "experimental/echo", "", -32601, "Method not found"},
+		{"handled", experimental, "experimental/echo", `{"params":{"x":1},"session":true}`, 0, ""},
+		{"protocol error", experimental, "experimental/other", "", -32601, "Method not found upstream"},
+		{"other error", experimental, "experimental/broken", "", -32603, "Internal error"},
+		{"built-in method", func(ctx context.Context, req *protocol.Request) (interface{}, error) {
+			return "intercepted", nil
+		}, "tools/list", `{"tools":[]}`, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			s.SetUnknownMethodHandler(tt.handler)
+			resp := mcptest.NewClient(t, s).Call(tt.method, map[string]int{"x": 1})
+			if tt.wantCode != 0 {
+				if resp.Error == nil || resp.Error.Code != tt.wantCode || resp.Error.Message != tt.wantMessage {
+					t.Errorf("error = %+v, want %d %q", resp.Error, tt.wantCode, tt.wantMessage)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("%s failed: %d %s", tt.method, resp.Error.Code, resp.Error.Message)
+			}
+			if got := string(resp.Result); got != tt.wantResult {
+				t.Errorf("result = %s, want %s", got, tt.wantResult)
+			}
+		})
+	}
+}
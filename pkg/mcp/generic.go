@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// ToolOption adjusts a registration built by Tool or TypedTool.
+type ToolOption func(*ToolRegistration)
+
+// WithDescription sets the description that tells clients what a tool does. Tools
+// registered under WithStrictToolValidation must have one.
+func WithDescription(description string) ToolOption {
+	return func(reg *ToolRegistration) {
+		reg.Definition.Description = description
+	}
+}
+
+// Tool returns a registration for a tool named name whose handler signature is checked
+// by the compiler instead of at registration time. In is the tool's input struct (or a
+// type implementing json.Unmarshaler); its schema is generated as for any other handler.
+//
+//	server.RegisterTools([]mcp.ToolRegistration{
+//		mcp.Tool("greet", func(ctx context.Context, in *GreetParams) (string, error) {
+//			return "Hello, " + in.Name, nil
+//		}, mcp.WithDescription("Greets a person by name.")),
+//	})
+//
+// The returned registration can be adjusted before registering it, for example to set
+// Definition.Title. Handlers of other shapes are registered with ToolRegistration
+// directly.
+func Tool[In any](name string, handler func(context.Context, *In) (string, error), opts ...ToolOption) ToolRegistration {
+	return newToolRegistration(name, handler, opts)
+}
+
+// TypedTool is like Tool for handlers with a typed result. Out, a struct or a map with
+// string keys, is sent as the result's structuredContent and, for clients that only
+// read content, as JSON text. For a struct Out the tool's output schema is generated
+// from it, just as the input schema is generated from In.
+//
+//	mcp.TypedTool("weather", func(ctx context.Context, in *WeatherQuery) (Forecast, error) {
+//		return lookupForecast(ctx, in.City)
+//	}, mcp.WithDescription("Forecasts the weather for a city."))
+func TypedTool[In, Out any](name string, handler func(context.Context, *In) (Out, error), opts ...ToolOption) ToolRegistration {
+	return newToolRegistration(name, func(ctx context.Context, in *In) (string, Out, error) {
+		out, err := handler(ctx, in)
+		if err != nil {
+			return "", out, err
+		}
+		return formatResultText(out), out, nil
+	}, opts)
+}
+
+// newToolRegistration builds the registration returned by Tool and TypedTool.
+func newToolRegistration(name string, handler interface{}, opts []ToolOption) ToolRegistration {
+	reg := ToolRegistration{
+		Definition: protocol.Tool{Name: name},
+		Handler:    handler,
+	}
+	for _, opt := range opts {
+		opt(&reg)
+	}
+	return reg
+}
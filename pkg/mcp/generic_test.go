@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+)
+
+type weatherQuery struct {
+	City string `json:"city" jsonschema:"required"`
+}
+
+type forecast struct {
+	City        string  `json:"city"`
+	Temperature float64 `json:"temperature"`
+}
+
+// schemaProperties returns the sorted property names of a JSON schema, or nil if it has none.
+func schemaProperties(t *testing.T, raw json.RawMessage) []string {
+	t.Helper()
+	if len(raw) == 0 {
+		return nil
+	}
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("decoding schema %s: %v", raw, err)
+	}
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestTypedTool(t *testing.T) {
+	tests := []struct {
+		name           string
+		reg            ToolRegistration
+		wantOutput     []string
+		wantStructured string
+		wantError      bool
+	}{
+		{
+			name: "struct result",
+			reg: TypedTool("weather", func(ctx context.Context, in *weatherQuery) (forecast, error) {
+				return forecast{City: in.City, Temperature: 12.5}, nil
+			}, WithDescription("Forecasts the weather.")),
+			wantOutput:     []string{"city", "temperature"},
+			wantStructured: `{"city":"Oslo","temperature":12.5}`,
+		},
+		{
+			name: "map result",
+			reg: TypedTool("weather", func(ctx context.Context, in *weatherQuery) (map[string]string, error) {
+				return map[string]string{"city": in.City}, nil
+			}, WithDescription("Forecasts the weather.")),
+			wantStructured: `{"city":"Oslo"}`,
+		},
+		{
+			name: "error",
+			reg: TypedTool("weather", func(ctx context.Context, in *weatherQuery) (forecast, error) {
+				return forecast{}, fmt.Errorf("no forecast for %s", in.City)
+			}, WithDescription("Forecasts the weather.")),
+			wantOutput: []string{"city", "temperature"},
+			wantError:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{tt.reg})
+			tool, ok := s.lookupTool(context.Background(), "weather")
+			if !ok {
+				t.Fatal("tool was not registered")
+			}
+			if got := schemaProperties(t, tool.Definition.InputSchema); !reflect.DeepEqual(got, []string{"city"}) {
+				t.Errorf("input schema properties = %v, want [city]", got)
+			}
+			if got := schemaProperties(t, tool.Definition.OutputSchema); !reflect.DeepEqual(got, tt.wantOutput) {
+				t.Errorf("output schema properties = %v, want %v", got, tt.wantOutput)
+			}
+
+			result := mcptest.CallTool(t, s, "weather", map[string]string{"city": "Oslo"})
+			if result.IsError != tt.wantError {
+				t.Fatalf("isError = %v, want %v: %+v", result.IsError, tt.wantError, result.Content)
+			}
+			if tt.wantError {
+				return
+			}
+			structured, err := json.Marshal(result.StructuredContent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(structured) != tt.wantStructured {
+				t.Errorf("structuredContent = %s, want %s", structured, tt.wantStructured)
+			}
+			if got := textOf(t, result); got != tt.wantStructured {
+				t.Errorf("text = %q, want %q", got, tt.wantStructured)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package mcp
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
+	"sort"
 	"time"
 
 	"go-mcp-sdk/pkg/protocol"
@@ -13,76 +16,257 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func (s *Server) handleInitialize(w http.ResponseWriter, req *protocol.Request) {
+func (s *Server) handleInitialize(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
 	log.Infof("Received initialize request: ID=%s", req.ID.String())
 	var initParams protocol.InitializeRequest
-	if err := json.Unmarshal(req.Params, &initParams); err != nil {
-		writeErrorResponse(w, req.ID, -32602, "Invalid params for initialize", err)
+	if err := DecodeParams(req, &initParams); err != nil {
+		s.writeRPCError(w, req.ID, err)
 		return
 	}
 
 	log.Infof("Client '%s' version '%s' connecting with protocol version '%s'", initParams.ClientInfo.Name, initParams.ClientInfo.Version, initParams.ProtocolVersion)
 
-	negotiatedVersion := initParams.ProtocolVersion
-	sessionID := fmt.Sprintf("session-%d", time.Now().UnixNano())
-
-	s.sessionLock.Lock()
-	s.sessions[sessionID] = &SessionState{ClientCapabilities: initParams.Capabilities}
-	s.sessionLock.Unlock()
+	negotiatedVersion := s.negotiateProtocolVersion(initParams.ProtocolVersion)
+	// The session keeps the client's language, for answers to later requests that do
+	// not carry their own Accept-Language header.
+	locale := initParams.Locale
+	if locale == "" {
+		if languages := s.preferredLanguages(ctx); len(languages) > 0 {
+			locale = languages[0]
+		}
+	}
+	sessionID, err := s.createSession(initParams.Capabilities, negotiatedVersion, locale)
+	if err != nil {
+		s.writeErrorResponse(w, req.ID, -32603, "Internal error: could not create session", err)
+		return
+	}
 	log.Infof("Created new session: %s", sessionID)
 
 	result := protocol.InitializeResult{
 		ProtocolVersion: negotiatedVersion,
-		ServerInfo:      s.info,
+		ServerInfo:      s.ServerInfo(),
 		Capabilities:    s.capabilities,
 	}
 
 	w.Header().Set("Mcp-Session-Id", sessionID)
-	writeSuccessResponse(w, req.ID, result)
+	s.writeSuccessResponse(w, req.ID, result)
+}
+
+// requireCapability writes a "Method not found" error when the server does not advertise
+// the capability a method belongs to. It reports whether the caller may proceed.
+func (s *Server) requireCapability(w http.ResponseWriter, req *protocol.Request, advertised bool, name string) bool {
+	if advertised {
+		return true
+	}
+	log.Warnf("Rejected %s request: server does not advertise the '%s' capability", req.Method, name)
+	s.writeErrorResponse(w, req.ID, -32601, fmt.Sprintf("Method not found: server does not support %s", name), nil)
+	return false
 }
 
 // --- Tool Method Handlers ---
 
-func (s *Server) handleListTools(w http.ResponseWriter, req *protocol.Request) {
+func (s *Server) handleListTools(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
 	log.Infof("Received tools/list request: ID=%s", req.ID.String())
-	s.toolLock.RLock()
-	defer s.toolLock.RUnlock()
-	toolList := make([]protocol.Tool, 0, len(s.tools))
-	for _, tool := range s.tools {
-		toolList = append(toolList, tool.Definition)
+	if !s.requireCapability(w, req, s.capabilities.Tools != nil, "tools") {
+		return
+	}
+	s.writeSuccessResponse(w, req.ID, protocol.ListToolsResult{Tools: s.listTools(ctx)})
+}
+
+// handleGetTool serves "tools/get", which returns the full definition of one tool, so
+// that clients needing a single schema do not have to fetch the whole list.
+func (s *Server) handleGetTool(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	if !s.requireCapability(w, req, s.capabilities.Tools != nil, "tools") {
+		return
+	}
+
+	var params protocol.GetToolRequest
+	if err := DecodeParams(req, &params); err != nil {
+		s.writeRPCError(w, req.ID, err)
+		return
 	}
-	writeSuccessResponse(w, req.ID, protocol.ListToolsResult{Tools: toolList})
+	log.Infof("Received tools/get request for tool '%s': ID=%s", params.Name, req.ID.String())
+
+	tool, exists := s.lookupTool(ctx, params.Name)
+	if !exists || !tool.visibleTo(ctx) {
+		s.writeErrorResponse(w, req.ID, -32602, fmt.Sprintf("Tool not found: %s", params.Name), nil)
+		return
+	}
+	s.writeSuccessResponse(w, req.ID, protocol.GetToolResult{Tool: tool.definitionFor(s.preferredLanguages(ctx))})
 }
 
-func (s *Server) handleCallTool(w http.ResponseWriter, req *protocol.Request) {
+func (s *Server) handleCallTool(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	if !s.requireCapability(w, req, s.capabilities.Tools != nil, "tools") {
+		return
+	}
+
 	var callParams protocol.CallToolRequest
-	if err := json.Unmarshal(req.Params, &callParams); err != nil {
-		writeErrorResponse(w, req.ID, -32602, "Invalid params for tools/call", err)
+	if err := DecodeParams(req, &callParams); err != nil {
+		s.writeRPCError(w, req.ID, err)
 		return
 	}
 
 	log.Infof("Received tools/call request for tool '%s': ID=%s", callParams.Name, req.ID.String())
 
-	s.toolLock.RLock()
-	tool, exists := s.tools[callParams.Name]
-	s.toolLock.RUnlock()
-	if !exists {
-		writeErrorResponse(w, req.ID, -32602, fmt.Sprintf("Tool not found: %s", callParams.Name), nil)
+	tool, exists := s.lookupTool(ctx, callParams.Name)
+	// Hidden tools are reported exactly like unknown ones so their existence is not revealed.
+	if !exists || !tool.visibleTo(ctx) {
+		s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Tool not found: %s", callParams.Name), nil)
 		return
 	}
 
-	inputValue := reflect.New(tool.inputType.Elem())
-	argsBytes, _ := json.Marshal(callParams.Arguments)
-	if err := json.Unmarshal(argsBytes, inputValue.Interface()); err != nil {
-		writeErrorResponse(w, req.ID, -32602, fmt.Sprintf("Invalid arguments for tool %s", callParams.Name), err)
+	// The limit applies to the arguments as the client sent them, before any of the
+	// rewriting below can change their size.
+	if tool.maxInputBytes > 0 && int64(len(callParams.Arguments)) > tool.maxInputBytes {
+		s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Arguments for tool %s exceed the limit of %d bytes", callParams.Name, tool.maxInputBytes), nil)
 		return
 	}
 
+	if callParams.PositionalArguments() {
+		if !s.positionalArguments {
+			s.rejectCall(ctx, w, req.ID, &callParams, -32602, "Invalid params for tools/call: arguments must be an object", nil)
+			return
+		}
+		var positional []json.RawMessage
+		if err := json.Unmarshal(callParams.Arguments, &positional); err != nil {
+			s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Invalid arguments for tool %s", callParams.Name), err)
+			return
+		}
+		args := make([]interface{}, len(positional))
+		for i, arg := range positional {
+			args[i] = arg
+		}
+		namedArgs, err := positionalToNamed(tool.inputType.Elem(), args, s.fieldNaming.schemaNaming())
+		if err != nil {
+			s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Invalid arguments for tool %s", callParams.Name), err)
+			return
+		}
+		callParams.Arguments, _ = json.Marshal(namedArgs)
+	}
+
+	// Deprecated argument names are mapped onto the current ones, and the client is
+	// told about each one it used.
+	var deprecationWarnings []string
+	if len(tool.aliases) > 0 && callParams.HasArguments() {
+		args, warnings, err := resolveAliases(callParams.Arguments, tool.aliases)
+		if err != nil {
+			s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Invalid arguments for tool %s", callParams.Name), err)
+			return
+		}
+		for _, warning := range warnings {
+			log.Warnf("Tool '%s' called with deprecated arguments: %s", callParams.Name, warning)
+		}
+		callParams.Arguments = args
+		deprecationWarnings = warnings
+	}
+
+	if s.requiredArgumentChecks && len(tool.required) > 0 {
+		if err := checkRequiredArguments(callParams.Arguments, tool.required); err != nil {
+			s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Invalid arguments for tool %s: %v", callParams.Name, err), err)
+			return
+		}
+	}
+
+	inputValue := reflect.New(tool.inputType.Elem())
+	if callParams.HasArguments() {
+		args := callParams.Arguments
+		if tool.renameArgs {
+			renamed, err := renameArguments(args, tool.inputType, s.fieldNaming.schemaNaming())
+			if err != nil {
+				s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Invalid arguments for tool %s", callParams.Name), err)
+				return
+			}
+			args = renamed
+		}
+		if err := json.Unmarshal(args, inputValue.Interface()); err != nil {
+			s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Invalid arguments for tool %s", callParams.Name), err)
+			return
+		}
+	}
+
+	if len(tool.patterns) > 0 {
+		if err := checkPatterns(inputValue, tool.patterns, s.fieldNaming.schemaNaming(), ""); err != nil {
+			s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Invalid arguments for tool %s: %v", callParams.Name, err), err)
+			return
+		}
+	}
+
+	if tool.validate != nil {
+		if err := tool.validate(inputValue.Interface()); err != nil {
+			s.rejectCall(ctx, w, req.ID, &callParams, -32602, fmt.Sprintf("Invalid arguments for tool %s: %v", callParams.Name, err), err)
+			return
+		}
+	}
+
+	// A call that asks for progress is answered with an SSE stream straight away when
+	// the client accepts one, so progress notifications arrive before the result.
+	if len(ProgressTokenFromContext(ctx)) > 0 {
+		if stream, ok := ctx.Value(requestStreamKey).(*requestStream); ok {
+			stream.start()
+		}
+	}
+
+	// The handler gets the tightest of the server, tool and client deadlines. The result
+	// is written under the request's own context, which is cancelled only if the client
+	// goes away, so that a timed-out call is still answered.
+	requestCtx := ctx
+	if timeout := s.callTimeout(tool, callParams.Meta); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	callArgs := []reflect.Value{}
 	if tool.takesContext {
-		callArgs = append(callArgs, reflect.ValueOf(context.Background()))
+		callArgs = append(callArgs, reflect.ValueOf(ctx))
+	}
+	if tool.dynamic {
+		callArgs = append(callArgs, reflect.ValueOf(callParams.Name))
+	}
+	if tool.inputByValue {
+		callArgs = append(callArgs, inputValue.Elem())
+	} else {
+		callArgs = append(callArgs, inputValue)
+	}
+
+	// A call waits for its tool's own limit before taking a server-wide slot, so that
+	// calls queued behind a busy tool do not hold slots other tools could use.
+	if tool.limiter != nil {
+		if err := tool.limiter.acquire(ctx); err != nil {
+			log.Warnf("Rejected call to tool '%s': %v", callParams.Name, err)
+			s.rejectCall(ctx, w, req.ID, &callParams, -32000, fmt.Sprintf("Tool %s is busy, try calling it again later", callParams.Name), err)
+			return
+		}
+		defer tool.limiter.release()
+	}
+	if s.limiter != nil {
+		if err := s.limiter.acquire(ctx); err != nil {
+			log.Warnf("Rejected call to tool '%s': %v", callParams.Name, err)
+			s.rejectCall(ctx, w, req.ID, &callParams, -32000, fmt.Sprintf("Server busy, try calling tool %s again later", callParams.Name), err)
+			return
+		}
+		defer s.limiter.release()
+	}
+
+	// A cached result is served like any other: after the limits above, and audited and
+	// counted as a successful call.
+	start := time.Now()
+	cacheKey := ""
+	if tool.cacheable && s.resultCache != nil {
+		cacheKey = resultCacheKey(tool.origin, SessionIDFromContext(ctx), callParams.Name, callParams.Arguments)
+		if cached, ok := s.resultCache.get(cacheKey); ok {
+			log.Debugf("Serving tool '%s' from the result cache", callParams.Name)
+			s.recordAudit(ctx, callParams.Name, callParams.Arguments, start, nil)
+			if s.metrics != nil {
+				s.metrics.observeToolCall(callParams.Name, time.Since(start), false)
+			}
+			if len(deprecationWarnings) > 0 {
+				cached.Meta = map[string]interface{}{"warnings": deprecationWarnings}
+			}
+			s.writeToolResult(requestCtx, w, req.ID, callParams.Name, cached)
+			return
+		}
 	}
-	callArgs = append(callArgs, inputValue)
 
 	results := tool.handlerValue.Call(callArgs)
 
@@ -91,24 +275,194 @@ func (s *Server) handleCallTool(w http.ResponseWriter, req *protocol.Request) {
 		resultErr = errVal.Interface().(error)
 	}
 
-	if resultErr != nil {
-		errorResult := &protocol.CallToolResult{
-			Content: []protocol.ContentBlock{{Type: "text", Text: resultErr.Error()}},
-			IsError: true,
+	// A reader's contents are streamed straight into the response when nothing needs
+	// the whole result first; otherwise they are read into the text block.
+	if tool.returnsReader {
+		reader, _ := results[0].Interface().(io.Reader)
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		results[0] = reflect.ValueOf("")
+		if resultErr == nil && reader != nil && s.canStreamReader(tool) {
+			// Waiting for the first byte lets an immediate failure still be reported as
+			// an ordinary error result; once streaming starts, a failure aborts the response.
+			buffered := bufio.NewReaderSize(reader, readerChunkSize)
+			if _, err := buffered.Peek(1); err != nil && err != io.EOF {
+				resultErr = fmt.Errorf("reading result: %w", err)
+			} else {
+				streamErr := s.writeReaderResult(requestCtx, w, req.ID, buffered, deprecationWarnings)
+				s.recordAudit(ctx, callParams.Name, callParams.Arguments, start, streamErr)
+				if s.metrics != nil {
+					s.metrics.observeToolCall(callParams.Name, time.Since(start), streamErr != nil)
+				}
+				if streamErr != nil {
+					if requestCtx.Err() != nil {
+						log.Debugf("Abandoned streamed result of tool '%s': client went away", callParams.Name)
+					} else {
+						log.Errorf("Aborting streamed result of tool '%s': %v", callParams.Name, streamErr)
+					}
+					panic(http.ErrAbortHandler)
+				}
+				return
+			}
+		} else if resultErr == nil && reader != nil {
+			text, err := io.ReadAll(reader)
+			if err != nil {
+				resultErr = fmt.Errorf("reading result: %w", err)
+			}
+			results[0] = reflect.ValueOf(string(text))
+		}
+	}
+	s.recordAudit(ctx, callParams.Name, callParams.Arguments, start, resultErr)
+
+	result := buildCallToolResult(results, resultErr, s.emptyResultText)
+	if resultErr != nil && s.structuredErrors {
+		result.Content = append(result.Content, toolErrorBlock(resultErr))
+	}
+	if s.metrics != nil {
+		s.metrics.observeToolCall(callParams.Name, time.Since(start), resultErr != nil || result.IsError)
+	}
+	if len(deprecationWarnings) > 0 {
+		result.Meta = map[string]interface{}{"warnings": deprecationWarnings}
+	}
+	if s.outputValidation {
+		if err := validateStructuredContent(tool.Definition, result); err != nil {
+			log.Errorf("Tool '%s' returned structured content that does not match its output schema: %v", callParams.Name, err)
+			s.writeErrorResponse(w, req.ID, -32603, fmt.Sprintf("Internal error: tool %s produced invalid output", callParams.Name), err)
+			return
+		}
+	}
+	if cacheKey != "" && resultErr == nil && !result.IsError {
+		s.resultCache.put(cacheKey, result)
+	}
+	s.writeToolResult(requestCtx, w, req.ID, callParams.Name, result)
+}
+
+// writeToolResult passes a tool's result through the configured interceptor and writes it.
+// Nothing is written once ctx is cancelled, since the client is no longer waiting.
+func (s *Server) writeToolResult(ctx context.Context, w http.ResponseWriter, id protocol.RequestID, toolName string, result *protocol.CallToolResult) {
+	if ctx.Err() != nil {
+		log.Debugf("Not writing result of tool '%s': %v", toolName, ctx.Err())
+		return
+	}
+	if s.resultInterceptor != nil {
+		if intercepted := s.resultInterceptor(ctx, toolName, result); intercepted != nil {
+			result = intercepted
 		}
-		writeSuccessResponse(w, req.ID, errorResult)
+	}
+	if s.shouldStream(result) {
+		s.writeStreamedResult(ctx, w, id, result)
 		return
 	}
+	s.writeSuccessResponse(w, id, result)
+}
 
-	var resultText string
-	if len(results) > 1 {
-		resultText = fmt.Sprintf("%v", results[0].Interface())
-	} else {
-		resultText = "Operation completed successfully."
+// --- Resource Method Handlers ---
+
+func (s *Server) handleListResources(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	log.Infof("Received resources/list request: ID=%s", req.ID.String())
+	if !s.requireCapability(w, req, s.capabilities.Resources != nil, "resources") {
+		return
+	}
+	s.resourceLock.RLock()
+	defer s.resourceLock.RUnlock()
+	resourceList := make([]protocol.Resource, 0, len(s.resources))
+	for _, resource := range s.resources {
+		resourceList = append(resourceList, resource.Definition)
+	}
+	sortResources(resourceList)
+	s.writeSuccessResponse(w, req.ID, protocol.ListResourcesResult{Resources: resourceList})
+}
+
+func (s *Server) handleReadResource(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	if !s.requireCapability(w, req, s.capabilities.Resources != nil, "resources") {
+		return
+	}
+
+	var readParams protocol.ReadResourceRequest
+	if err := DecodeParams(req, &readParams); err != nil {
+		s.writeRPCError(w, req.ID, err)
+		return
 	}
 
-	successResult := &protocol.CallToolResult{
-		Content: []protocol.ContentBlock{{Type: "text", Text: resultText}},
+	log.Infof("Received resources/read request for '%s': ID=%s", readParams.URI, req.ID.String())
+
+	s.resourceLock.RLock()
+	resource, exists := s.resources[readParams.URI]
+	s.resourceLock.RUnlock()
+	if !exists {
+		s.writeErrorResponse(w, req.ID, -32002, fmt.Sprintf("Resource not found: %s", readParams.URI), nil)
+		return
 	}
-	writeSuccessResponse(w, req.ID, successResult)
-}
\ No newline at end of file
+
+	contents, err := resource.Handler(ctx, readParams.URI)
+	if err != nil {
+		s.writeErrorResponse(w, req.ID, -32603, fmt.Sprintf("Failed to read resource %s", readParams.URI), err)
+		return
+	}
+
+	contents = typeResourceContents(contents, resource.Definition.MIMEType)
+	s.writeSuccessResponse(w, req.ID, protocol.ReadResourceResult{Contents: contents})
+}
+
+// --- Prompt Method Handlers ---
+
+func (s *Server) handleListPrompts(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	log.Infof("Received prompts/list request: ID=%s", req.ID.String())
+	if !s.requireCapability(w, req, s.capabilities.Prompts != nil, "prompts") {
+		return
+	}
+	s.promptLock.RLock()
+	defer s.promptLock.RUnlock()
+	promptList := make([]protocol.Prompt, 0, len(s.prompts))
+	for _, prompt := range s.prompts {
+		promptList = append(promptList, prompt.Definition)
+	}
+	sort.Slice(promptList, func(i, j int) bool { return promptList[i].Name < promptList[j].Name })
+	s.writeSuccessResponse(w, req.ID, protocol.ListPromptsResult{Prompts: promptList})
+}
+
+func (s *Server) handleGetPrompt(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	if !s.requireCapability(w, req, s.capabilities.Prompts != nil, "prompts") {
+		return
+	}
+
+	var getParams protocol.GetPromptRequest
+	if err := DecodeParams(req, &getParams); err != nil {
+		s.writeRPCError(w, req.ID, err)
+		return
+	}
+
+	log.Infof("Received prompts/get request for prompt '%s': ID=%s", getParams.Name, req.ID.String())
+
+	s.promptLock.RLock()
+	prompt, exists := s.prompts[getParams.Name]
+	s.promptLock.RUnlock()
+	if !exists {
+		s.writeErrorResponse(w, req.ID, -32602, fmt.Sprintf("Prompt not found: %s", getParams.Name), nil)
+		return
+	}
+
+	for _, arg := range prompt.Definition.Arguments {
+		if _, ok := getParams.Arguments[arg.Name]; arg.Required && !ok {
+			s.writeErrorResponse(w, req.ID, -32602, fmt.Sprintf("Missing required argument for prompt %s: %s", getParams.Name, arg.Name), nil)
+			return
+		}
+	}
+
+	messages, err := prompt.Handler(ctx, getParams.Arguments)
+	if err != nil {
+		s.writeErrorResponse(w, req.ID, -32603, fmt.Sprintf("Failed to render prompt %s", getParams.Name), err)
+		return
+	}
+	if err := validatePromptMessages(messages); err != nil {
+		log.Errorf("Prompt '%s' returned invalid messages: %v", getParams.Name, err)
+		s.writeErrorResponse(w, req.ID, -32603, fmt.Sprintf("Prompt %s produced invalid content", getParams.Name), err)
+		return
+	}
+
+	s.writeSuccessResponse(w, req.ID, protocol.GetPromptResult{
+		Description: prompt.Definition.Description,
+		Messages:    messages,
+	})
+}
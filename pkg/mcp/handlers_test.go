@@ -0,0 +1,362 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type orderInput struct {
+	ID       int64   `json:"id"`
+	Quantity uint8   `json:"quantity"`
+	Price    float64 `json:"price"`
+	Express  bool    `json:"express"`
+	Address  struct {
+		City string `json:"city"`
+	} `json:"address"`
+	Tags []string `json:"tags"`
+}
+
+func TestCallToolDecodesArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		want      string
+		wantErr   bool
+	}{
+		{"all fields", `{"id":7,"quantity":3,"price":9.5,"express":true,"address":{"city":"Oslo"},"tags":["a","b"]}`, "7 3 9.5 true Oslo [a b]", false},
+		{"large integer", `{"id":9007199254740993}`, "9007199254740993 0 0 false  []", false},
+		{"integer as float", `{"price":2}`, "0 0 2 false  []", false},
+		{"no arguments", ``, "0 0 0 false  []", false},
+		{"null arguments", `null`, "0 0 0 false  []", false},
+		{"wrong type", `{"id":"seven"}`, "", true},
+		{"overflow", `{"quantity":300}`, "", true},
+		{"fraction into integer", `{"id":1.5}`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "order", Description: "Places an order."},
+				Handler: func(ctx context.Context, in *orderInput) (string, error) {
+					return fmt.Sprintf("%d %d %v %v %s %v", in.ID, in.Quantity, in.Price, in.Express, in.Address.City, in.Tags), nil
+				},
+			}})
+			c := mcptest.NewClient(t, s)
+
+			params := json.RawMessage(`{"name":"order"}`)
+			if tt.arguments != "" {
+				params = json.RawMessage(`{"name":"order","arguments":` + tt.arguments + `}`)
+			}
+			resp := c.Call("tools/call", params)
+			if tt.wantErr {
+				if resp.Error == nil || resp.Error.Code != -32602 {
+					t.Fatalf("error = %+v, want -32602", resp.Error)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("tools/call failed: %d %s", resp.Error.Code, resp.Error.Message)
+			}
+			var result protocol.CallToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatalf("decoding result: %v", err)
+			}
+			if got := textOf(t, &result); got != tt.want {
+				t.Errorf("handler saw %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+var benchmarkArguments = json.RawMessage(`{"id":9007199254740993,"quantity":3,"price":9.5,"express":true,"address":{"city":"Oslo"},"tags":["a","b","c"]}`)
+
+// BenchmarkDecodeArguments compares decoding arguments straight into the input struct
+// with the map round trip tools/call used to make.
+func BenchmarkDecodeArguments(b *testing.B) {
+	b.Run("map round trip", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var args map[string]interface{}
+			if err := json.Unmarshal(benchmarkArguments, &args); err != nil {
+				b.Fatal(err)
+			}
+			encoded, _ := json.Marshal(args)
+			var in orderInput
+			if err := json.Unmarshal(encoded, &in); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var in orderInput
+			if err := json.Unmarshal(benchmarkArguments, &in); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkCallTool(b *testing.B) {
+	s := newTestServer(b, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "order", Description: "Places an order."},
+		Handler: func(ctx context.Context, in *orderInput) (string, error) {
+			return "ok", nil
+		},
+	}})
+	sessionID := mcptest.NewClient(b, s).SessionID()
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"order","arguments":` + string(benchmarkArguments) + `}}`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		s.ServeHTTP(&discardResponseWriter{header: http.Header{}}, req)
+	}
+}
+
+func TestCallToolMaxInputBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		wantErr   string
+	}{
+		{"under the limit", `{"value":"short"}`, ""},
+		{"over the limit", `{"value":"` + strings.Repeat("x", 64) + `"}`, "exceed the limit of 32 bytes"},
+		{"positional over the limit", `["` + strings.Repeat("x", 64) + `"]`, "exceed the limit of 32 bytes"},
+		// The size is checked before required arguments, so an oversized call is not
+		// parsed any further.
+		{"over the limit and missing the required argument", `{"other":"` + strings.Repeat("x", 64) + `"}`, "exceed the limit of 32 bytes"},
+		{"missing the required argument", `{}`, "value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition:    protocol.Tool{Name: "echo", Description: "Echoes its input."},
+				Handler:       func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil },
+				MaxInputBytes: 32,
+			}}, WithPositionalArguments(), WithRequiredArgumentChecks())
+			c := mcptest.NewClient(t, s)
+
+			resp := c.Call("tools/call", json.RawMessage(`{"name":"echo","arguments":`+tt.arguments+`}`))
+			if tt.wantErr == "" {
+				if resp.Error != nil {
+					t.Fatalf("tools/call failed: %d %s", resp.Error.Code, resp.Error.Message)
+				}
+				return
+			}
+			if resp.Error == nil || resp.Error.Code != -32602 || !strings.Contains(resp.Error.Message, tt.wantErr) {
+				t.Errorf("error = %+v, want -32602 mentioning %q", resp.Error, tt.wantErr)
+			}
+		})
+	}
+}
+
+type searchInput struct {
+	Query string `json:"query"`
+	Limit *int   `json:"limit"`
+}
+
+func TestCallToolPointerFieldsAreOptional(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		want      string
+	}{
+		{"omitted", `{"query":"go"}`, "go limit=nil"},
+		{"null", `{"query":"go","limit":null}`, "go limit=nil"},
+		{"zero", `{"query":"go","limit":0}`, "go limit=0"},
+		{"set", `{"query":"go","limit":5}`, "go limit=5"},
+	}
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "search", Description: "Searches."},
+		Handler: func(ctx context.Context, in *searchInput) (string, error) {
+			if in.Limit == nil {
+				return in.Query + " limit=nil", nil
+			}
+			return fmt.Sprintf("%s limit=%d", in.Query, *in.Limit), nil
+		},
+	}}, WithRequiredArgumentChecks())
+
+	var schema struct {
+		Required []string `json:"required"`
+	}
+	tool, _ := s.lookupTool(context.Background(), "search")
+	if err := json.Unmarshal(tool.Definition.InputSchema, &schema); err != nil {
+		t.Fatalf("decoding input schema: %v", err)
+	}
+	if strings.Join(schema.Required, ",") != "query" {
+		t.Errorf("required = %v, want only query", schema.Required)
+	}
+
+	c := mcptest.NewClient(t, s)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := c.CallTool("search", json.RawMessage(tt.arguments))
+			if got := textOf(t, result); got != tt.want {
+				t.Errorf("handler saw %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type bookingInput struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func TestCallToolValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		wantErr   string
+	}{
+		{"start before end", `{"start":"2026-01-01","end":"2026-01-05"}`, ""},
+		{"start after end", `{"start":"2026-01-05","end":"2026-01-01"}`, "start must be before end"},
+		{"same day", `{"start":"2026-01-05","end":"2026-01-05"}`, "start must be before end"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "book", Description: "Books a stay."},
+				Handler: func(ctx context.Context, in *bookingInput) (string, error) {
+					called = true
+					return "booked", nil
+				},
+				Validate: func(input interface{}) error {
+					in := input.(*bookingInput)
+					if in.Start >= in.End {
+						return fmt.Errorf("start must be before end")
+					}
+					return nil
+				},
+			}})
+			resp := mcptest.NewClient(t, s).Call("tools/call", json.RawMessage(`{"name":"book","arguments":`+tt.arguments+`}`))
+			if tt.wantErr == "" {
+				if resp.Error != nil || !called {
+					t.Fatalf("error = %+v, handler called = %v; want a successful call", resp.Error, called)
+				}
+				return
+			}
+			if resp.Error == nil || resp.Error.Code != -32602 || !strings.Contains(resp.Error.Message, tt.wantErr) {
+				t.Errorf("error = %+v, want -32602 mentioning %q", resp.Error, tt.wantErr)
+			}
+			if called {
+				t.Error("handler ran after Validate rejected the call")
+			}
+		})
+	}
+}
+
+type renamedInput struct {
+	Query string `json:"query" alias:"q,search"`
+	Limit int    `json:"limit" deprecated:"results are paged now"`
+}
+
+func TestCallToolArgumentAliases(t *testing.T) {
+	tests := []struct {
+		name         string
+		arguments    string
+		want         string
+		wantWarnings []interface{}
+		wantErr      bool
+	}{
+		{"current name", `{"query":"go"}`, "go 0", nil, false},
+		{"old name", `{"q":"go"}`, "go 0", []interface{}{"argument 'q' is deprecated: use 'query' instead"}, false},
+		{"other old name", `{"search":"go"}`, "go 0", []interface{}{"argument 'search' is deprecated: use 'query' instead"}, false},
+		{"deprecated argument", `{"query":"go","limit":5}`, "go 5", []interface{}{"argument 'limit' is deprecated: results are paged now"}, false},
+		{"old and current name", `{"q":"go","query":"rust"}`, "", nil, true},
+	}
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "search", Description: "Searches."},
+		Handler: func(ctx context.Context, in *renamedInput) (string, error) {
+			return fmt.Sprintf("%s %d", in.Query, in.Limit), nil
+		},
+	}})
+	c := mcptest.NewClient(t, s)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := c.Call("tools/call", json.RawMessage(`{"name":"search","arguments":`+tt.arguments+`}`))
+			if tt.wantErr {
+				if resp.Error == nil || resp.Error.Code != -32602 {
+					t.Errorf("error = %+v, want -32602", resp.Error)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("tools/call failed: %d %s", resp.Error.Code, resp.Error.Message)
+			}
+			var result protocol.CallToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatalf("decoding result: %v", err)
+			}
+			if got := textOf(t, &result); got != tt.want {
+				t.Errorf("handler saw %q, want %q", got, tt.want)
+			}
+			warnings, _ := result.Meta["warnings"].([]interface{})
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("_meta warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestCallToolValueInput(t *testing.T) {
+	greet := func(in orderInput) string {
+		return fmt.Sprintf("%d to %s", in.ID, in.Address.City)
+	}
+	tests := []struct {
+		name    string
+		handler interface{}
+		wantErr bool
+	}{
+		{"struct by value", func(ctx context.Context, in orderInput) (string, error) { return greet(in), nil }, false},
+		{"struct by value without context", func(in orderInput) (string, error) { return greet(in), nil }, false},
+		{"struct by pointer", func(ctx context.Context, in *orderInput) (string, error) { return greet(*in), nil }, false},
+		{"string by value", func(ctx context.Context, in string) (string, error) { return in, nil }, true},
+		{"map by value", func(ctx context.Context, in map[string]interface{}) (string, error) { return "", nil }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var validated interface{}
+			s := NewServer("test", "1.0.0", testCapabilities)
+			err := s.RegisterTools([]ToolRegistration{{
+				Definition: protocol.Tool{Name: "order", Description: "Places an order."},
+				Handler:    tt.handler,
+				Validate: func(input interface{}) error {
+					validated = input
+					return nil
+				},
+			}})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("RegisterTools accepted a handler whose input is not a struct")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterTools: %v", err)
+			}
+
+			result := mcptest.CallTool(t, s, "order", map[string]interface{}{"id": 7, "address": map[string]string{"city": "Oslo"}})
+			if got := textOf(t, result); got != "7 to Oslo" {
+				t.Errorf("result = %q, want %q", got, "7 to Oslo")
+			}
+			if _, ok := validated.(*orderInput); !ok {
+				t.Errorf("Validate received %T, want *orderInput", validated)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// reservedResponseHeaders are managed by the transport and cannot be set by handlers.
+var reservedResponseHeaders = map[string]bool{
+	"Content-Type":      true,
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Mcp-Session-Id":    true,
+}
+
+// responseHeaders collects the headers handlers ask to add to a request's HTTP response.
+type responseHeaders struct {
+	mu     sync.Mutex
+	header http.Header
+	// sent is set once the response has started; later headers are dropped.
+	sent bool
+}
+
+// SetResponseHeader asks the HTTP transport to set a header on the response to the
+// request being handled, e.g. a cache directive or a trace id for a gateway.
+//
+// It reports whether the header will be sent. It is a no-op, returning false, when ctx
+// does not belong to a request served over HTTP, when the response has already started
+// (for instance because progress notifications are being streamed), or for headers the
+// transport manages itself, such as Content-Type and Mcp-Session-Id. Results served
+// from the result cache do not run the handler, so they carry no handler headers.
+func SetResponseHeader(ctx context.Context, key, value string) bool {
+	rh, ok := ctx.Value(responseHeadersKey).(*responseHeaders)
+	if !ok {
+		return false
+	}
+	key = http.CanonicalHeaderKey(key)
+	if reservedResponseHeaders[key] {
+		return false
+	}
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	if rh.sent {
+		return false
+	}
+	rh.header.Set(key, value)
+	return true
+}
+
+// headerWriter adds the headers collected for a request to its response just before
+// the response starts.
+type headerWriter struct {
+	http.ResponseWriter
+	headers *responseHeaders
+}
+
+// withResponseHeaders returns a context that collects response headers and a writer
+// that applies them.
+func withResponseHeaders(ctx context.Context, w http.ResponseWriter) (context.Context, *headerWriter) {
+	rh := &responseHeaders{header: make(http.Header)}
+	return context.WithValue(ctx, responseHeadersKey, rh), &headerWriter{ResponseWriter: w, headers: rh}
+}
+
+func (hw *headerWriter) apply() {
+	hw.headers.mu.Lock()
+	defer hw.headers.mu.Unlock()
+	if hw.headers.sent {
+		return
+	}
+	hw.headers.sent = true
+	for key, values := range hw.headers.header {
+		hw.ResponseWriter.Header()[key] = values
+	}
+}
+
+func (hw *headerWriter) WriteHeader(statusCode int) {
+	hw.apply()
+	hw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (hw *headerWriter) Write(p []byte) (int, error) {
+	hw.apply()
+	return hw.ResponseWriter.Write(p)
+}
+
+// Flush lets responses written through hw still be streamed.
+func (hw *headerWriter) Flush() {
+	hw.apply()
+	if flusher, ok := hw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (hw *headerWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
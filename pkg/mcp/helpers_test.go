@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TestMain keeps the server's per-request logging out of test and benchmark output.
+func TestMain(m *testing.M) {
+	log.SetLevel(log.WarnLevel)
+	os.Exit(m.Run())
+}
+
+// testCapabilities advertises every feature, so tests only need to register what they use.
+var testCapabilities = protocol.ServerCapabilities{
+	Tools:     &protocol.ServerToolCapabilities{ListChanged: true},
+	Resources: &protocol.ServerResourceCapabilities{Subscribe: true, ListChanged: true},
+	Prompts:   &protocol.ServerPromptCapabilities{},
+	Logging:   &struct{}{},
+}
+
+// newTestServer returns a server with testCapabilities and the given tools registered.
+func newTestServer(t testing.TB, tools []ToolRegistration, opts ...ServerOption) *Server {
+	t.Helper()
+	s := NewServer("test", "1.0.0", testCapabilities, opts...)
+	if err := s.RegisterTools(tools); err != nil {
+		t.Fatalf("RegisterTools: %v", err)
+	}
+	return s
+}
+
+// memoryAuditSink collects audit records in memory.
+type memoryAuditSink struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (m *memoryAuditSink) Record(record AuditRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, record)
+	return nil
+}
+
+func (m *memoryAuditSink) all() []AuditRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]AuditRecord(nil), m.records...)
+}
+
+// textOf returns the text of a result's first content block.
+func textOf(t testing.TB, result *protocol.CallToolResult) string {
+	t.Helper()
+	if len(result.Content) == 0 {
+		t.Fatalf("result has no content: %+v", result)
+	}
+	return result.Content[0].Text
+}
+
+// queuedNotifications drains the notifications waiting for a session's stream and
+// returns their methods in order.
+func queuedNotifications(t testing.TB, s *Server, sessionID string) []string {
+	t.Helper()
+	session := s.lookupSession(sessionID)
+	if session == nil {
+		t.Fatalf("session %s not found", sessionID)
+	}
+	var methods []string
+	for {
+		select {
+		case notif := <-session.notifications:
+			methods = append(methods, notif.Method)
+		default:
+			return methods
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// LocalizedText is a tool's title and description in one language. An empty field
+// falls back to the tool's definition.
+type LocalizedText struct {
+	Title       string
+	Description string
+}
+
+// contextWithAcceptLanguage returns a copy of ctx carrying the request's Accept-Language header.
+func contextWithAcceptLanguage(ctx context.Context, header string) context.Context {
+	if header == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, acceptLanguageKey, header)
+}
+
+// preferredLanguages returns the language tags the caller prefers, best first: those of
+// the request's Accept-Language header, or else the locale its session gave during
+// initialize. Tags are in lower case.
+func (s *Server) preferredLanguages(ctx context.Context) []string {
+	if header, ok := ctx.Value(acceptLanguageKey).(string); ok {
+		if languages := parseAcceptLanguage(header); len(languages) > 0 {
+			return languages
+		}
+	}
+	if session := s.lookupSession(SessionIDFromContext(ctx)); session != nil && session.Locale != "" {
+		return []string{strings.ToLower(session.Locale)}
+	}
+	return nil
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language header in order of
+// preference, leaving out the wildcard and tags with a quality of zero.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].quality > tags[j].quality })
+	languages := make([]string, len(tags))
+	for i, tag := range tags {
+		languages[i] = tag.tag
+	}
+	return languages
+}
+
+// normalizeLocalized checks the language tags of a registration's Localized texts and
+// returns a copy keyed by lower-case tag.
+func normalizeLocalized(localized map[string]LocalizedText) (map[string]LocalizedText, error) {
+	if len(localized) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]LocalizedText, len(localized))
+	for tag, text := range localized {
+		if strings.TrimSpace(tag) == "" || strings.ContainsAny(tag, " ,;*") {
+			return nil, fmt.Errorf("invalid language tag '%s'", tag)
+		}
+		normalized[strings.ToLower(tag)] = text
+	}
+	return normalized, nil
+}
+
+// definitionFor returns the tool's definition with its title and description in the first
+// of languages it has text for. A tag also matches text for its base language, so "pt-br"
+// uses "pt", and a base language matches text for any of its regions, so "pt" uses "pt-br".
+func (t internalRegisteredTool) definitionFor(languages []string) protocol.Tool {
+	def := t.Definition
+	if len(t.localized) == 0 {
+		return def
+	}
+	for _, language := range languages {
+		text, ok := t.localized[language]
+		if !ok {
+			text, ok = t.localizedByBase(language)
+		}
+		if !ok {
+			continue
+		}
+		if text.Title != "" {
+			def.Title = text.Title
+		}
+		if text.Description != "" {
+			def.Description = text.Description
+		}
+		break
+	}
+	return def
+}
+
+// localizedByBase finds text for language by its base language, or for one of the
+// regional variants of it, choosing the first tag in sorted order for determinism.
+func (t internalRegisteredTool) localizedByBase(language string) (LocalizedText, bool) {
+	base, _, _ := strings.Cut(language, "-")
+	if text, ok := t.localized[base]; ok {
+		return text, true
+	}
+	var match string
+	for tag := range t.localized {
+		if strings.HasPrefix(tag, base+"-") && (match == "" || tag < match) {
+			match = tag
+		}
+	}
+	if match == "" {
+		return LocalizedText{}, false
+	}
+	return t.localized[match], true
+}
+
+// staleLocalizedRemoved returns localized without the translations of the title or
+// description that differ between the definitions old and updated. Entries left with
+// no text are removed; localized itself is not modified.
+func staleLocalizedRemoved(localized map[string]LocalizedText, old, updated protocol.Tool) map[string]LocalizedText {
+	titleChanged := old.Title != updated.Title
+	descriptionChanged := old.Description != updated.Description
+	if len(localized) == 0 || (!titleChanged && !descriptionChanged) {
+		return localized
+	}
+	kept := make(map[string]LocalizedText, len(localized))
+	for tag, text := range localized {
+		if titleChanged {
+			text.Title = ""
+		}
+		if descriptionChanged {
+			text.Description = ""
+		}
+		if text != (LocalizedText{}) {
+			kept[tag] = text
+		}
+	}
+	return kept
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"fr", []string{"fr"}},
+		{"fr-CA, fr;q=0.8, en;q=0.5", []string{"fr-ca", "fr", "en"}},
+		{"en;q=0.3, de;q=0.9, *;q=0.1", []string{"de", "en"}},
+		{"de;q=0, fr", []string{"fr"}},
+		{"de;q=abc, fr", []string{"fr"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := parseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizedToolDefinitions(t *testing.T) {
+	const (
+		english    = "Forecasts the weather."
+		french     = "Prévoit la météo."
+		portuguese = "Prevê o tempo."
+	)
+	tests := []struct {
+		name            string
+		locale          string
+		initLanguage    string
+		acceptLanguage  string
+		wantTitle       string
+		wantDescription string
+	}{
+		{"no language", "", "", "", "Weather", english},
+		{"header", "", "", "fr", "Météo", french},
+		{"regional header uses the base language", "", "", "fr-CA", "Météo", french},
+		{"base header uses a regional text", "", "", "pt", "Weather", portuguese},
+		{"best available by quality", "", "", "de, pt-BR;q=0.4, fr;q=0.5", "Météo", french},
+		{"no match", "", "", "de, en", "Weather", english},
+		{"refused language", "", "", "fr;q=0", "Weather", english},
+		{"initialize locale", "pt-BR", "", "", "Weather", portuguese},
+		{"header over initialize locale", "pt-BR", "", "fr", "Météo", french},
+		{"initialize header", "", "fr-CA", "", "Météo", french},
+	}
+	reg := ToolRegistration{
+		Definition: protocol.Tool{Name: "weather", Title: "Weather", Description: english},
+		Handler:    replyWith("sunny"),
+		Localized: map[string]LocalizedText{
+			"fr":    {Title: "Météo", Description: french},
+			"pt-BR": {Description: portuguese},
+		},
+	}
+	for _, tt := range tests {
+		for _, provided := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s provided=%v", tt.name, provided), func(t *testing.T) {
+				s := newTestServer(t, []ToolRegistration{reg})
+				if provided {
+					provider := &stubProvider{}
+					provider.set(reg)
+					s = newTestServer(t, nil, WithToolProvider(provider))
+				}
+				send := func(sessionID, language, body string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+					req.Header.Set("Content-Type", "application/json")
+					if sessionID != "" {
+						req.Header.Set("Mcp-Session-Id", sessionID)
+					}
+					if language != "" {
+						req.Header.Set("Accept-Language", language)
+					}
+					rec := httptest.NewRecorder()
+					s.ServeHTTP(rec, req)
+					return rec
+				}
+
+				initParams, _ := json.Marshal(protocol.InitializeRequest{
+					ProtocolVersion: "2025-06-18",
+					ClientInfo:      protocol.ImplementationInfo{Name: "test", Version: "0"},
+					Locale:          tt.locale,
+				})
+				sessionID := send("", tt.initLanguage, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":`+string(initParams)+`}`).Header().Get("Mcp-Session-Id")
+				send(sessionID, "", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+				var list protocol.ListToolsResult
+				decodeResult(t, send(sessionID, tt.acceptLanguage, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`), &list)
+				if len(list.Tools) != 1 {
+					t.Fatalf("tools/list = %+v, want one tool", list.Tools)
+				}
+				var got protocol.GetToolResult
+				decodeResult(t, send(sessionID, tt.acceptLanguage, `{"jsonrpc":"2.0","id":3,"method":"tools/get","params":{"name":"weather"}}`), &got)
+
+				for method, def := range map[string]protocol.Tool{"tools/list": list.Tools[0], "tools/get": got.Tool} {
+					if def.Title != tt.wantTitle || def.Description != tt.wantDescription {
+						t.Errorf("%s = %q / %q, want %q / %q", method, def.Title, def.Description, tt.wantTitle, tt.wantDescription)
+					}
+				}
+			})
+		}
+	}
+}
+
+// decodeResult decodes the result of a JSON-RPC response into v, failing on an error response.
+func decodeResult(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	var resp protocol.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body.String(), err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("request failed: %+v", resp.Error)
+	}
+	if err := json.Unmarshal(resp.Result, v); err != nil {
+		t.Fatalf("decoding result %s: %v", resp.Result, err)
+	}
+}
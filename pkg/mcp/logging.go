@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Logger writes messages to the server log and forwards them to the client that made
+// the current request as "notifications/message", so tools can report progress to users.
+// Messages are forwarded only if the server advertises the logging capability and the
+// client has asked for their level, or a less severe one, with "logging/setLevel".
+type Logger struct {
+	ctx    context.Context
+	server *Server
+	entry  *log.Entry
+}
+
+// Log returns a Logger bound to the request carried by ctx. Outside a request handler
+// it still writes to the server log but forwards nothing.
+func Log(ctx context.Context) *Logger {
+	fields := log.Fields{}
+	if sessionID := SessionIDFromContext(ctx); sessionID != "" {
+		fields["session"] = sessionID
+	}
+	if id, ok := RequestIDFromContext(ctx); ok {
+		fields["request"] = id.String()
+	}
+	server, _ := ctx.Value(serverKey).(*Server)
+	return &Logger{ctx: ctx, server: server, entry: log.WithFields(fields)}
+}
+
+// Debugf logs a message at debug level.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(protocol.LevelDebug, log.DebugLevel, format, args...)
+}
+
+// Infof logs a message at info level.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(protocol.LevelInfo, log.InfoLevel, format, args...)
+}
+
+// Warnf logs a message at warning level.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(protocol.LevelWarning, log.WarnLevel, format, args...)
+}
+
+// Errorf logs a message at error level.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(protocol.LevelError, log.ErrorLevel, format, args...)
+}
+
+func (l *Logger) logf(level protocol.LoggingLevel, serverLevel log.Level, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.entry.Log(serverLevel, message)
+	if l.server != nil {
+		l.server.forwardLog(l.ctx, level, message)
+	}
+}
+
+// forwardLog sends a "notifications/message" to the calling client if it wants messages at level.
+func (s *Server) forwardLog(ctx context.Context, level protocol.LoggingLevel, message string) {
+	if s.capabilities.Logging == nil {
+		return
+	}
+	session := s.lookupSession(SessionIDFromContext(ctx))
+	if session == nil {
+		return
+	}
+	minLevel, _ := session.logLevel.Load().(protocol.LoggingLevel)
+	if minLevel == "" || !level.AtLeast(minLevel) {
+		return
+	}
+
+	notif, err := newNotification("notifications/message", protocol.LoggingMessageNotification{
+		Level:  level,
+		Logger: s.ServerInfo().Name,
+		Data:   message,
+	})
+	if err != nil {
+		log.Errorf("Failed to build log notification: %v", err)
+		return
+	}
+	s.sendNotification(ctx, notif)
+}
+
+func (s *Server) handleSetLevel(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	if !s.requireCapability(w, req, s.capabilities.Logging != nil, "logging") {
+		return
+	}
+
+	var params protocol.SetLevelRequest
+	if err := DecodeParams(req, &params); err != nil {
+		s.writeRPCError(w, req.ID, err)
+		return
+	}
+	if !params.Level.Valid() {
+		s.writeErrorResponse(w, req.ID, -32602, fmt.Sprintf("Invalid params for logging/setLevel: unknown level %q", params.Level), nil)
+		return
+	}
+
+	log.Infof("Received logging/setLevel request (level %s): ID=%s", params.Level, req.ID.String())
+	if session := s.lookupSession(SessionIDFromContext(ctx)); session != nil {
+		session.logLevel.Store(params.Level)
+	}
+	s.writeSuccessResponse(w, req.ID, struct{}{})
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Manifest is a session-less description of everything a server offers, intended for
+// registries and catalog tooling.
+type Manifest struct {
+	ServerInfo       protocol.ImplementationInfo `json:"serverInfo"`
+	ProtocolVersions []string                    `json:"protocolVersions"`
+	Capabilities     protocol.ServerCapabilities `json:"capabilities"`
+	Tools            []protocol.Tool             `json:"tools,omitempty"`
+	Resources        []protocol.Resource         `json:"resources,omitempty"`
+	Prompts          []protocol.Prompt           `json:"prompts,omitempty"`
+}
+
+// WithManifest serves the server's Manifest as JSON at GET /mcp/manifest,
+// or at <path>/manifest when the server uses WithPath.
+func WithManifest() ServerOption {
+	return func(s *Server) {
+		s.manifestEnabled = true
+	}
+}
+
+// Manifest returns a snapshot of the server's info, capabilities and registered definitions.
+// Tools with a visibility predicate are evaluated against ctx and omitted if hidden.
+func (s *Server) Manifest(ctx context.Context) Manifest {
+	manifest := Manifest{
+		ServerInfo:       s.ServerInfo(),
+		ProtocolVersions: s.protocolVersions(),
+		Capabilities:     s.capabilities,
+	}
+
+	if s.capabilities.Tools != nil {
+		s.toolLock.RLock()
+		for _, tool := range s.tools {
+			if tool.visibleTo(ctx) {
+				manifest.Tools = append(manifest.Tools, tool.Definition)
+			}
+		}
+		s.toolLock.RUnlock()
+		sort.Slice(manifest.Tools, func(i, j int) bool { return manifest.Tools[i].Name < manifest.Tools[j].Name })
+	}
+	if s.capabilities.Resources != nil {
+		s.resourceLock.RLock()
+		for _, resource := range s.resources {
+			manifest.Resources = append(manifest.Resources, resource.Definition)
+		}
+		s.resourceLock.RUnlock()
+		sortResources(manifest.Resources)
+	}
+	if s.capabilities.Prompts != nil {
+		s.promptLock.RLock()
+		for _, prompt := range s.prompts {
+			manifest.Prompts = append(manifest.Prompts, prompt.Definition)
+		}
+		s.promptLock.RUnlock()
+		sort.Slice(manifest.Prompts, func(i, j int) bool { return manifest.Prompts[i].Name < manifest.Prompts[j].Name })
+	}
+	return manifest
+}
+
+func (s *Server) handleManifest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.Manifest(r.Context())); err != nil {
+		log.Errorf("Error writing manifest: %v", err)
+	}
+}
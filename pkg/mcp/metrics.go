@@ -0,0 +1,204 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// metricsPath is where WithPrometheusMetrics serves metrics, relative to the server's path.
+const metricsPath = "/metrics"
+
+// durationBuckets are the upper bounds, in seconds, of the tool call duration histogram.
+// They match the Prometheus client's default buckets.
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// WithPrometheusMetrics collects request, tool call and session metrics and serves them
+// at GET <path>/metrics, /mcp/metrics by default, in the Prometheus text exposition
+// format, without depending on a Prometheus client library. The metrics are:
+//
+//   - mcp_requests_total{method}: JSON-RPC requests received
+//   - mcp_tool_calls_total{tool,outcome}: tool calls, with outcome "success" or "error"
+//   - mcp_tool_call_duration_seconds{tool}: histogram of tool handler run times
+//   - mcp_active_sessions: sessions currently open
+//
+// MetricsHandler serves the same metrics, for scraping them at another path.
+func WithPrometheusMetrics() ServerOption {
+	return func(s *Server) {
+		s.metrics = newServerMetrics()
+	}
+}
+
+// MetricsHandler returns the handler serving the server's metrics, or nil if the server
+// was created without WithPrometheusMetrics.
+func (s *Server) MetricsHandler() http.Handler {
+	if s.metrics == nil {
+		return nil
+	}
+	return http.HandlerFunc(s.handleMetrics)
+}
+
+// serverMetrics accumulates the counters exposed by WithPrometheusMetrics.
+type serverMetrics struct {
+	mu        sync.Mutex
+	requests  map[string]uint64
+	toolCalls map[toolOutcome]uint64
+	durations map[string]*histogram
+}
+
+type toolOutcome struct {
+	tool    string
+	outcome string
+}
+
+// histogram counts observations into cumulative durationBuckets.
+type histogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		requests:  make(map[string]uint64),
+		toolCalls: make(map[toolOutcome]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// knownMethods are reported under their own name; any other method is counted as
+// "other" so clients cannot create unbounded label values.
+var knownMethods = map[string]bool{
+	"initialize":            true,
+	"tools/list":            true,
+	"tools/call":            true,
+	"tools/get":             true,
+	"resources/list":        true,
+	"resources/read":        true,
+	"resources/subscribe":   true,
+	"resources/unsubscribe": true,
+	"prompts/list":          true,
+	"prompts/get":           true,
+	"logging/setLevel":      true,
+}
+
+// countRequest records a request for method.
+func (m *serverMetrics) countRequest(method string) {
+	if !knownMethods[method] {
+		method = "other"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[method]++
+}
+
+// observeToolCall records a finished tool call and how long its handler ran.
+func (m *serverMetrics) observeToolCall(tool string, duration time.Duration, failed bool) {
+	outcome := "success"
+	if failed {
+		outcome = "error"
+	}
+	seconds := duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.toolCalls[toolOutcome{tool: tool, outcome: outcome}]++
+	h, ok := m.durations[tool]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(durationBuckets))}
+		m.durations[tool] = h
+	}
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.sessionLock.RLock()
+	activeSessions := len(s.sessions)
+	s.sessionLock.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := s.metrics.write(w, activeSessions); err != nil {
+		log.Errorf("Error writing metrics: %v", err)
+	}
+}
+
+// write renders the metrics in the Prometheus text exposition format, with series in
+// a stable order.
+func (m *serverMetrics) write(w io.Writer, activeSessions int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP mcp_requests_total JSON-RPC requests received, by method.\n")
+	b.WriteString("# TYPE mcp_requests_total counter\n")
+	methods := make([]string, 0, len(m.requests))
+	for method := range m.requests {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		fmt.Fprintf(&b, "mcp_requests_total{method=%s} %d\n", labelValue(method), m.requests[method])
+	}
+
+	b.WriteString("# HELP mcp_tool_calls_total Tool calls, by tool and outcome.\n")
+	b.WriteString("# TYPE mcp_tool_calls_total counter\n")
+	calls := make([]toolOutcome, 0, len(m.toolCalls))
+	for call := range m.toolCalls {
+		calls = append(calls, call)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].tool != calls[j].tool {
+			return calls[i].tool < calls[j].tool
+		}
+		return calls[i].outcome < calls[j].outcome
+	})
+	for _, call := range calls {
+		fmt.Fprintf(&b, "mcp_tool_calls_total{tool=%s,outcome=%s} %d\n", labelValue(call.tool), labelValue(call.outcome), m.toolCalls[call])
+	}
+
+	b.WriteString("# HELP mcp_tool_call_duration_seconds Time spent in tool handlers.\n")
+	b.WriteString("# TYPE mcp_tool_call_duration_seconds histogram\n")
+	tools := make([]string, 0, len(m.durations))
+	for tool := range m.durations {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		h, label := m.durations[tool], labelValue(tool)
+		for i, bound := range durationBuckets {
+			fmt.Fprintf(&b, "mcp_tool_call_duration_seconds_bucket{tool=%s,le=\"%s\"} %d\n", label, strconv.FormatFloat(bound, 'g', -1, 64), h.buckets[i])
+		}
+		fmt.Fprintf(&b, "mcp_tool_call_duration_seconds_bucket{tool=%s,le=\"+Inf\"} %d\n", label, h.count)
+		fmt.Fprintf(&b, "mcp_tool_call_duration_seconds_sum{tool=%s} %s\n", label, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "mcp_tool_call_duration_seconds_count{tool=%s} %d\n", label, h.count)
+	}
+
+	b.WriteString("# HELP mcp_active_sessions Sessions currently open.\n")
+	b.WriteString("# TYPE mcp_active_sessions gauge\n")
+	fmt.Fprintf(&b, "mcp_active_sessions %d\n", activeSessions)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labelValue quotes a label value, escaping backslashes, quotes and newlines.
+func labelValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// newMountedServers mounts two servers with every optional endpoint on one mux, the way
+// the documentation shows, and makes one tool call on each.
+func newMountedServers(t *testing.T) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+	for _, name := range []string{"calc", "files"} {
+		s := newTestServer(t, []ToolRegistration{{
+			Definition: protocol.Tool{Name: name + "_echo", Description: "Echoes its input."},
+			Handler:    func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil },
+		}}, WithPath("/mcp/"+name), WithPrometheusMetrics(), WithManifest(), WithQueryToolCalls())
+		mux.Handle(s.Path(), s.Handler())
+		mux.Handle(s.Path()+"/", s.Handler())
+		mcptest.NewClientAt(t, mux, s.Path()).CallTool(name+"_echo", map[string]string{"value": "hi"})
+	}
+	return mux
+}
+
+func TestMountedServerEndpoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		wantContent string
+		wantAbsent  string
+	}{
+		{"calc metrics", "/mcp/calc/metrics", `mcp_tool_calls_total{tool="calc_echo",outcome="success"} 1`, "files_echo"},
+		{"files metrics", "/mcp/files/metrics", `mcp_tool_calls_total{tool="files_echo",outcome="success"} 1`, "calc_echo"},
+		{"calc manifest", "/mcp/calc/manifest", `"name":"calc_echo"`, "files_echo"},
+		{"files manifest", "/mcp/files/manifest", `"name":"files_echo"`, "calc_echo"},
+		{"calc query call", "/mcp/calc/tools/calc_echo?value=hello", `"text":"hello"`, ""},
+		{"files query call", "/mcp/files/tools/files_echo?value=hello", `"text":"hello"`, ""},
+	}
+	mux := newMountedServers(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			body, _ := io.ReadAll(rec.Body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET %s: status %d: %s", tt.path, rec.Code, body)
+			}
+			if !strings.Contains(string(body), tt.wantContent) {
+				t.Errorf("GET %s is missing %s:\n%s", tt.path, tt.wantContent, body)
+			}
+			if tt.wantAbsent != "" && strings.Contains(string(body), tt.wantAbsent) {
+				t.Errorf("GET %s mentions the other server's %s:\n%s", tt.path, tt.wantAbsent, body)
+			}
+		})
+	}
+}
+
+func TestMetricsScrape(t *testing.T) {
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "echo", Description: "Echoes its input."},
+		Handler:    func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil },
+	}}, WithPrometheusMetrics())
+	c := mcptest.NewClient(t, s)
+	c.CallTool("echo", map[string]string{"value": "hi"})
+	c.Call("tools/call", map[string]interface{}{"name": "missing"})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mcp/metrics", nil))
+	for _, want := range []string{
+		`mcp_requests_total{method="initialize"} 1`,
+		`mcp_requests_total{method="tools/call"} 2`,
+		`mcp_tool_calls_total{tool="echo",outcome="success"} 1`,
+		`mcp_tool_call_duration_seconds_count{tool="echo"} 1`,
+		`mcp_active_sessions 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics are missing %s:\n%s", want, rec.Body.String())
+		}
+	}
+	if got := s.MetricsHandler(); got == nil {
+		t.Error("MetricsHandler() = nil with WithPrometheusMetrics")
+	}
+	if got := newTestServer(t, nil).MetricsHandler(); got != nil {
+		t.Error("MetricsHandler() is not nil without WithPrometheusMetrics")
+	}
+}
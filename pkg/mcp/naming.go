@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"go-mcp-sdk/internal/jsonschema"
+)
+
+// FieldNaming decides how the fields of a tool's input struct are named in its
+// arguments when their json tag does not name them. Named tags are always used as-is.
+type FieldNaming int
+
+const (
+	// FieldNamesAsTagged uses the json tag name and otherwise the Go field name in
+	// lower case: UserID is "userid". This is the default.
+	FieldNamesAsTagged FieldNaming = iota
+	// FieldNamesCamelCase names untagged fields in camelCase: UserID is "userID".
+	FieldNamesCamelCase
+	// FieldNamesSnakeCase names untagged fields in snake_case: UserID is "user_id".
+	FieldNamesSnakeCase
+)
+
+// WithFieldNaming sets how input struct fields without a json tag name are named in
+// tool input schemas, and accepts arguments under those names. The names are used
+// consistently for the schema's properties and required list, for positional and
+// aliased arguments, and when decoding. Structured output is encoded by encoding/json,
+// so output schemas always use Go field names for untagged fields.
+func WithFieldNaming(naming FieldNaming) ServerOption {
+	return func(s *Server) {
+		s.fieldNaming = naming
+	}
+}
+
+// schemaNaming returns the schema generator's equivalent of n.
+func (n FieldNaming) schemaNaming() jsonschema.FieldNaming {
+	switch n {
+	case FieldNamesCamelCase:
+		return jsonschema.NameCamelCase
+	case FieldNamesSnakeCase:
+		return jsonschema.NameSnakeCase
+	default:
+		return jsonschema.NameLowercase
+	}
+}
+
+// needsArgumentRenames reports whether arguments for input type t must be renamed
+// before decoding: encoding/json matches field names case-insensitively, so only names
+// that differ from the Go name by more than case, such as snake_case ones, need it.
+func needsArgumentRenames(t reflect.Type, naming jsonschema.FieldNaming) bool {
+	return hasRenamedField(t, naming, make(map[reflect.Type]bool))
+}
+
+func hasRenamedField(t reflect.Type, naming jsonschema.FieldNaming, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return false
+	}
+	visited[t] = true
+	for _, field := range jsonschema.EncodedFields(t) {
+		name, tagged, ok := jsonschema.FieldName(field, naming)
+		if !ok {
+			continue
+		}
+		if !tagged && !strings.EqualFold(name, field.Name) {
+			return true
+		}
+		if hasRenamedField(field.Type, naming, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// renameArguments rewrites the names of untagged fields in args, a JSON object, from
+// the names advertised in the schema to the Go field names encoding/json decodes.
+func renameArguments(args json.RawMessage, t reflect.Type, naming jsonschema.FieldNaming) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(args))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(renameValue(value, t, naming))
+}
+
+// renameValue renames the fields of value, decoded from JSON, that belong to type t,
+// descending into nested structs the same way the schema generator does.
+func renameValue(value interface{}, t reflect.Type, naming jsonschema.FieldNaming) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if items, ok := value.([]interface{}); ok {
+			for i, item := range items {
+				items[i] = renameValue(item, t.Elem(), naming)
+			}
+		}
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+			return value
+		}
+		for _, field := range jsonschema.EncodedFields(t) {
+			name, tagged, ok := jsonschema.FieldName(field, naming)
+			if !ok {
+				continue
+			}
+			fieldValue, present := object[name]
+			if !present {
+				continue
+			}
+			fieldValue = renameValue(fieldValue, field.Type, naming)
+			if !tagged && name != field.Name {
+				delete(object, name)
+				name = field.Name
+			}
+			object[name] = fieldValue
+		}
+	}
+	return value
+}
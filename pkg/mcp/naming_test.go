@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type profileInput struct {
+	UserID      string
+	HTTPProxy   string
+	DisplayName string `json:"display"`
+	Home        struct {
+		PostCode string
+	}
+}
+
+func TestFieldNaming(t *testing.T) {
+	tests := []struct {
+		name         string
+		naming       FieldNaming
+		wantProps    []string
+		wantRequired []string
+		arguments    string
+	}{
+		{"as tagged", FieldNamesAsTagged, []string{"display", "home", "httpproxy", "userid"}, []string{"userid", "httpproxy", "display", "home"},
+			`{"userid":"u1","httpproxy":"proxy:80","display":"Ada","home":{"postcode":"0150"}}`},
+		{"camel case", FieldNamesCamelCase, []string{"display", "home", "httpProxy", "userID"}, []string{"userID", "httpProxy", "display", "home"},
+			`{"userID":"u1","httpProxy":"proxy:80","display":"Ada","home":{"postCode":"0150"}}`},
+		{"snake case", FieldNamesSnakeCase, []string{"display", "home", "http_proxy", "user_id"}, []string{"user_id", "http_proxy", "display", "home"},
+			`{"user_id":"u1","http_proxy":"proxy:80","display":"Ada","home":{"post_code":"0150"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "profile", Description: "Saves a profile."},
+				Handler: func(ctx context.Context, in *profileInput) (string, error) {
+					return fmt.Sprintf("%s %s %s %s", in.UserID, in.HTTPProxy, in.DisplayName, in.Home.PostCode), nil
+				},
+			}}, WithFieldNaming(tt.naming))
+			tool, _ := s.lookupTool(context.Background(), "profile")
+			if got := schemaProperties(t, tool.Definition.InputSchema); !reflect.DeepEqual(got, tt.wantProps) {
+				t.Errorf("properties = %v, want %v", got, tt.wantProps)
+			}
+			if got := requiredArguments(tool.Definition.InputSchema); !reflect.DeepEqual(got, tt.wantRequired) {
+				t.Errorf("required = %v, want %v", got, tt.wantRequired)
+			}
+
+			resp := mcptest.NewClient(t, s).Call("tools/call", json.RawMessage(`{"name":"profile","arguments":`+tt.arguments+`}`))
+			if resp.Error != nil {
+				t.Fatalf("tools/call failed: %+v", resp.Error)
+			}
+			var result protocol.CallToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := textOf(t, &result), "u1 proxy:80 Ada 0150"; got != want {
+				t.Errorf("decoded %q, want %q", got, want)
+			}
+		})
+	}
+}
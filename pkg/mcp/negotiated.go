@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// Negotiated records what a session agreed on during initialize: the protocol version,
+// which client features the server may use, and which server features the client was
+// offered. Handlers can consult it instead of re-deriving it from the capabilities of
+// both sides; for example a tool should only request sampling if Sampling is set.
+type Negotiated struct {
+	ProtocolVersion string
+
+	// Sampling, Elicitation and Roots report whether the client accepts
+	// sampling/createMessage, elicitation/create and roots/list requests.
+	Sampling    bool
+	Elicitation bool
+	Roots       bool
+
+	// The server features available to the session.
+	Tools               bool
+	ToolListChanged     bool
+	Resources           bool
+	ResourceSubscribe   bool
+	ResourceListChanged bool
+	Prompts             bool
+	PromptListChanged   bool
+	Logging             bool
+}
+
+// negotiate works out what a session with the given client capabilities and protocol
+// version may use.
+func (s *Server) negotiate(client protocol.ClientCapabilities, protocolVersion string) Negotiated {
+	server := s.capabilities
+	negotiated := Negotiated{
+		ProtocolVersion: protocolVersion,
+		Sampling:        client.Sampling != nil,
+		Elicitation:     client.Elicitation != nil,
+		Roots:           client.Roots != nil,
+		Tools:           server.Tools != nil,
+		Resources:       server.Resources != nil,
+		Prompts:         server.Prompts != nil,
+		Logging:         server.Logging != nil,
+	}
+	if server.Tools != nil {
+		negotiated.ToolListChanged = server.Tools.ListChanged
+	}
+	if server.Resources != nil {
+		negotiated.ResourceSubscribe = server.Resources.Subscribe
+		negotiated.ResourceListChanged = server.Resources.ListChanged
+	}
+	if server.Prompts != nil {
+		negotiated.PromptListChanged = server.Prompts.ListChanged
+	}
+	return negotiated
+}
+
+// NegotiatedFromContext returns what the calling session negotiated during initialize.
+// It reports false outside a request handler or session.
+func NegotiatedFromContext(ctx context.Context) (Negotiated, bool) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return Negotiated{}, false
+	}
+	return session.Negotiated, true
+}
@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestNegotiatedFromContext(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities protocol.ServerCapabilities
+		client       string
+		version      string
+		want         Negotiated
+	}{
+		{"every feature", testCapabilities, `{"sampling":{},"elicitation":{},"roots":{}}`, "2025-06-18", Negotiated{
+			ProtocolVersion: "2025-06-18",
+			Sampling:        true, Elicitation: true, Roots: true,
+			Tools: true, ToolListChanged: true,
+			Resources: true, ResourceSubscribe: true, ResourceListChanged: true,
+			Prompts: true, Logging: true,
+		}},
+		{"client without features", testCapabilities, `{}`, "2025-06-18", Negotiated{
+			ProtocolVersion: "2025-06-18",
+			Tools:           true, ToolListChanged: true,
+			Resources: true, ResourceSubscribe: true, ResourceListChanged: true,
+			Prompts: true, Logging: true,
+		}},
+		{"tools only", protocol.ServerCapabilities{Tools: &protocol.ServerToolCapabilities{}}, `{"sampling":{}}`, "2025-06-18", Negotiated{
+			ProtocolVersion: "2025-06-18",
+			Sampling:        true,
+			Tools:           true,
+		}},
+		{"older protocol version", testCapabilities, `{"roots":{}}`, "2025-03-26", Negotiated{
+			ProtocolVersion: "2025-03-26",
+			Roots:           true,
+			Tools:           true, ToolListChanged: true,
+			Resources: true, ResourceSubscribe: true, ResourceListChanged: true,
+			Prompts: true, Logging: true,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Negotiated
+			var ok bool
+			s := NewServer("test", "1.0.0", tt.capabilities)
+			if err := s.RegisterTools([]ToolRegistration{{
+				Definition: protocol.Tool{Name: "negotiated", Description: "Reports what the session negotiated."},
+				Handler: func(ctx context.Context, in *echoInput) error {
+					got, ok = NegotiatedFromContext(ctx)
+					return nil
+				},
+			}}); err != nil {
+				t.Fatalf("RegisterTools: %v", err)
+			}
+
+			rec := post(t, s, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+tt.version+`","capabilities":`+tt.client+`,"clientInfo":{"name":"test","version":"0"}}}`)
+			sessionID := rec.Header().Get("Mcp-Session-Id")
+			if sessionID == "" {
+				t.Fatalf("initialize did not create a session: %s", rec.Body.String())
+			}
+			post(t, s, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+			rec = post(t, s, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"negotiated","arguments":{}}}`)
+			var resp protocol.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error != nil {
+				t.Fatalf("tools/call failed: %s", rec.Body.String())
+			}
+
+			if !ok {
+				t.Fatal("NegotiatedFromContext reported no session inside a tool call")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("negotiated = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := NegotiatedFromContext(context.Background()); ok {
+		t.Error("NegotiatedFromContext reported a session outside a request")
+	}
+}
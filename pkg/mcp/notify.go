@@ -0,0 +1,411 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultNotificationBuffer is the number of notifications queued per session
+// while no stream is draining them.
+const defaultNotificationBuffer = 64
+
+// OverflowPolicy decides what happens when a session's notification queue is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest queued notification to make room for the new one.
+	DropOldest OverflowPolicy = iota
+	// Disconnect closes the session's stream so the client reconnects and resynchronises.
+	Disconnect
+)
+
+// WithNotificationBuffer sets how many notifications are queued per session and what
+// happens when a slow client lets the queue fill up. The default is 64 with DropOldest.
+func WithNotificationBuffer(size int, policy OverflowPolicy) ServerOption {
+	return func(s *Server) {
+		if size > 0 {
+			s.notificationBuffer = size
+		}
+		s.overflowPolicy = policy
+	}
+}
+
+// WithoutEventStream turns off the SSE stream a client opens with GET on the MCP
+// endpoint, for deployments that cannot hold connections open. GET is then answered
+// with 405 Method Not Allowed and a JSON-RPC error saying so, as the Streamable HTTP
+// transport specifies, rather than a response a client could mistake for a stream.
+// Notifications still reach clients on the streamed responses to their requests, but
+// those not tied to a request, such as list changes, are discarded.
+func WithoutEventStream() ServerOption {
+	return func(s *Server) {
+		s.eventStreamDisabled = true
+	}
+}
+
+// defaultReplayBuffer is the number of delivered stream events kept per session so a
+// client reconnecting with Last-Event-ID can catch up.
+const defaultReplayBuffer = 128
+
+// WithReplayBuffer sets how many delivered events each session keeps for replay when a
+// client resumes its stream with the Last-Event-ID header. Zero disables replay.
+func WithReplayBuffer(size int) ServerOption {
+	return func(s *Server) {
+		if size >= 0 {
+			s.replayBuffer = size
+		}
+	}
+}
+
+// streamEvent is a message delivered on a session's stream, remembered for replay.
+type streamEvent struct {
+	id    uint64
+	notif *protocol.Notification
+}
+
+// recordEvent assigns the next event id to notif and keeps it for replay.
+func (st *SessionState) recordEvent(notif *protocol.Notification, limit int) uint64 {
+	st.eventLock.Lock()
+	defer st.eventLock.Unlock()
+	st.lastEventID++
+	if limit > 0 {
+		if len(st.replay) >= limit {
+			st.replay = append(st.replay[:0], st.replay[len(st.replay)-limit+1:]...)
+		}
+		st.replay = append(st.replay, streamEvent{id: st.lastEventID, notif: notif})
+	}
+	return st.lastEventID
+}
+
+// eventsAfter returns the remembered events with ids greater than lastID, oldest first.
+func (st *SessionState) eventsAfter(lastID uint64) []streamEvent {
+	st.eventLock.Lock()
+	defer st.eventLock.Unlock()
+	var events []streamEvent
+	for _, event := range st.replay {
+		if event.id > lastID {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// newSessionState creates the state for a freshly initialized session.
+func (s *Server) newSessionState(capabilities protocol.ClientCapabilities) *SessionState {
+	state := &SessionState{
+		ClientCapabilities: capabilities,
+		disconnect:         make(chan struct{}, 1),
+		done:               make(chan struct{}),
+	}
+	// Without a GET stream nothing would ever drain the queue.
+	if !s.eventStreamDisabled {
+		state.notifications = make(chan *protocol.Notification, s.notificationBuffer)
+	}
+	return state
+}
+
+// enqueue adds a notification to the session's queue, applying policy if it is full.
+// It reports whether the notification was queued. Sessions of a server without a GET
+// stream have no queue, and their notifications are discarded.
+func (st *SessionState) enqueue(notif *protocol.Notification, policy OverflowPolicy) bool {
+	if st.notifications == nil {
+		return true
+	}
+	st.queueLock.Lock()
+	defer st.queueLock.Unlock()
+
+	select {
+	case st.notifications <- notif:
+		return true
+	default:
+	}
+
+	if policy == Disconnect {
+		select {
+		case st.disconnect <- struct{}{}:
+		default:
+		}
+		return false
+	}
+
+	// DropOldest: make room by discarding the head of the queue. The stream may
+	// have drained it concurrently, in which case the send succeeds anyway.
+	select {
+	case <-st.notifications:
+	default:
+	}
+	select {
+	case st.notifications <- notif:
+		return true
+	default:
+		return false
+	}
+}
+
+// broadcastNotification queues a notification for every connected session.
+// It never blocks on a slow client; overflow is handled by the configured policy.
+func (s *Server) broadcastNotification(method string, params interface{}) {
+	notif, err := newNotification(method, params)
+	if err != nil {
+		log.Errorf("Failed to build notification %s: %v", method, err)
+		return
+	}
+
+	s.sessionLock.RLock()
+	defer s.sessionLock.RUnlock()
+	for sessionID, session := range s.sessions {
+		if !session.enqueue(notif, s.overflowPolicy) {
+			log.Warnf("Notification queue full for session %s while sending %s", sessionID, method)
+		}
+	}
+}
+
+// WithStreamWriteTimeout bounds how long a single write to a client's SSE stream may
+// take. Notifications are queued for each session and written by its own stream, so a
+// client that does not keep up within timeout is disconnected instead of holding back
+// its stream, and may reconnect with Last-Event-ID to catch up. Zero, the default,
+// means no write timeout.
+func WithStreamWriteTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		if timeout >= 0 {
+			s.streamWriteTimeout = timeout
+		}
+	}
+}
+
+// List kinds that can emit "notifications/<kind>/list_changed".
+const (
+	listTools     = "tools"
+	listResources = "resources"
+	listPrompts   = "prompts"
+)
+
+// notifyListChanged tells clients that the tool, resource or prompt list changed,
+// if the server advertises listChanged for that kind.
+func (s *Server) notifyListChanged(kind string) {
+	var advertised bool
+	switch kind {
+	case listTools:
+		advertised = s.capabilities.Tools != nil && s.capabilities.Tools.ListChanged
+	case listResources:
+		advertised = s.capabilities.Resources != nil && s.capabilities.Resources.ListChanged
+	case listPrompts:
+		advertised = s.capabilities.Prompts != nil && s.capabilities.Prompts.ListChanged
+	}
+	if !advertised {
+		return
+	}
+	s.broadcastNotification("notifications/"+kind+"/list_changed", nil)
+}
+
+func newNotification(method string, params interface{}) (*protocol.Notification, error) {
+	notif := &protocol.Notification{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		paramsBytes, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		notif.Params = paramsBytes
+	}
+	return notif, nil
+}
+
+// writeStreamUnavailable answers a GET that cannot be given an SSE stream with 405 Method
+// Not Allowed and a JSON-RPC error explaining why, so the client does not wait on it.
+func (s *Server) writeStreamUnavailable(w http.ResponseWriter, reason string) {
+	log.Debugf("Refused SSE stream: %s", reason)
+	resp := protocol.Response{
+		JSONRPC: "2.0",
+		Error: &protocol.ErrorObject{
+			Code:    -32000,
+			Message: "SSE stream not supported: " + reason + "; send requests with POST",
+		},
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Allow", "POST, DELETE")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		log.Errorf("Error writing error response: %v", err)
+	}
+}
+
+// handleSSEStream serves the session's queued notifications as a Server-Sent Events stream
+// until the client disconnects.
+func (s *Server) handleSSEStream(w http.ResponseWriter, r *http.Request) {
+	if s.eventStreamDisabled {
+		s.writeStreamUnavailable(w, "this server does not offer an SSE stream")
+		return
+	}
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		http.Error(w, "Missing Mcp-Session-Id header", http.StatusBadRequest)
+		return
+	}
+	session := s.lookupSession(sessionID)
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeStreamUnavailable(w, "the connection cannot carry an SSE stream")
+		return
+	}
+
+	// With a write timeout, every write must finish by its deadline; a failed write or
+	// flush ends the stream.
+	controller := http.NewResponseController(w)
+	flush := func() error {
+		flusher.Flush()
+		return nil
+	}
+	if s.streamWriteTimeout > 0 {
+		flush = controller.Flush
+		defer controller.SetWriteDeadline(time.Time{})
+	}
+	setDeadline := func() {
+		if s.streamWriteTimeout <= 0 {
+			return
+		}
+		if err := controller.SetWriteDeadline(time.Now().Add(s.streamWriteTimeout)); err != nil {
+			log.Debugf("Cannot set write deadline for session %s: %v", sessionID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	log.Infof("Opened SSE stream for session %s", sessionID)
+	session.openStreams.Add(1)
+	defer session.openStreams.Add(-1)
+
+	// A reconnecting client names the last event it saw; replay whatever followed it.
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		lastID, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			log.Warnf("Ignoring invalid Last-Event-ID %q for session %s", lastEventID, sessionID)
+		} else {
+			events := session.eventsAfter(lastID)
+			log.Infof("Resuming SSE stream for session %s after event %d: replaying %d events", sessionID, lastID, len(events))
+			for _, event := range events {
+				setDeadline()
+				if err := writeSSEEvent(w, event.id, event.notif, s.maxEventSize); err != nil {
+					log.Errorf("Error replaying SSE event for session %s: %v", sessionID, err)
+					return
+				}
+			}
+			setDeadline()
+			if err := flush(); err != nil {
+				log.Warnf("Disconnecting SSE stream for session %s: %v", sessionID, err)
+				return
+			}
+		}
+	}
+
+	// With coalescing, events are written as they arrive but flushed together once the
+	// window that opened with the first of them has passed.
+	var flushTimer <-chan time.Time
+	for {
+		select {
+		case <-flushTimer:
+			flushTimer = nil
+			setDeadline()
+			if err := flush(); err != nil {
+				log.Warnf("Disconnecting SSE stream for session %s: %v", sessionID, err)
+				return
+			}
+		case <-r.Context().Done():
+			log.Infof("Closed SSE stream for session %s", sessionID)
+			return
+		case <-session.done:
+			log.Infof("Closed SSE stream for session %s: session ended", sessionID)
+			return
+		case <-session.disconnect:
+			log.Warnf("Disconnecting SSE stream for session %s: notification queue overflowed", sessionID)
+			return
+		case notif := <-session.notifications:
+			eventID := session.recordEvent(notif, s.replayBuffer)
+			setDeadline()
+			if err := writeSSEEvent(w, eventID, notif, s.maxEventSize); err != nil {
+				log.Errorf("Error writing SSE event for session %s: %v", sessionID, err)
+				return
+			}
+			if s.coalesceWindow <= 0 {
+				if err := flush(); err != nil {
+					log.Warnf("Disconnecting SSE stream for session %s: %v", sessionID, err)
+					return
+				}
+			} else if flushTimer == nil {
+				flushTimer = time.After(s.coalesceWindow)
+			}
+		}
+	}
+}
+
+// WithNotificationCoalescing batches notifications that arrive within window of each
+// other into a single write on the client's stream, which saves system calls when many
+// are sent in quick succession, such as rapid progress updates. Notifications keep their
+// order, and none is held back for longer than window. The default, zero, sends each
+// notification immediately.
+func WithNotificationCoalescing(window time.Duration) ServerOption {
+	return func(s *Server) {
+		if window >= 0 {
+			s.coalesceWindow = window
+		}
+	}
+}
+
+// WithMaxEventSize limits the encoded size of a single message sent on an SSE stream.
+// A larger message is not sent; the client receives an "error" event in its place.
+// Zero, the default, means no limit.
+func WithMaxEventSize(bytes int) ServerOption {
+	return func(s *Server) {
+		s.maxEventSize = bytes
+	}
+}
+
+// writeSSEEvent writes message as an SSE "message" event, with an id if id is non-zero.
+//
+// A message that cannot be encoded, or whose encoding exceeds maxSize (when positive),
+// is replaced by an "error" event describing the problem, so the stream stays well-formed
+// and the client learns that something was not delivered. Only failures to write to
+// the connection are returned.
+func writeSSEEvent(w io.Writer, id uint64, message interface{}, maxSize int) error {
+	data, err := json.Marshal(message)
+	if err == nil && maxSize > 0 && len(data) > maxSize {
+		err = fmt.Errorf("message of %d bytes exceeds the maximum event size of %d bytes", len(data), maxSize)
+	}
+	if err != nil {
+		log.Errorf("Could not send SSE event: %v", err)
+		errorData, _ := json.Marshal(protocol.ErrorObject{
+			Code:    -32603,
+			Message: "Internal error: message could not be delivered",
+			Data:    err.Error(),
+		})
+		_, err = fmt.Fprintf(w, "event: error\ndata: %s\n\n", errorData)
+		return err
+	}
+
+	if id != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,480 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// queuedParams drains the notifications waiting for a session's stream and returns
+// their params in order.
+func queuedParams(t testing.TB, s *Server, sessionID string) []string {
+	t.Helper()
+	session := s.lookupSession(sessionID)
+	var params []string
+	for {
+		select {
+		case notif := <-session.notifications:
+			params = append(params, string(notif.Params))
+		default:
+			return params
+		}
+	}
+}
+
+func TestNotificationBufferOverflow(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         OverflowPolicy
+		wantQueued     []string
+		wantDisconnect bool
+	}{
+		{"drop oldest", DropOldest, []string{`{"n":2}`, `{"n":3}`, `{"n":4}`}, false},
+		{"disconnect", Disconnect, []string{`{"n":0}`, `{"n":1}`, `{"n":2}`}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, WithNotificationBuffer(3, tt.policy))
+			sessionID := mcptest.NewClient(t, s).SessionID()
+			for i := 0; i < 5; i++ {
+				s.broadcastNotification("notifications/message", map[string]int{"n": i})
+			}
+
+			disconnected := false
+			select {
+			case <-s.lookupSession(sessionID).disconnect:
+				disconnected = true
+			default:
+			}
+			if disconnected != tt.wantDisconnect {
+				t.Errorf("disconnect signalled = %v, want %v", disconnected, tt.wantDisconnect)
+			}
+			got := queuedParams(t, s, sessionID)
+			if fmt.Sprint(got) != fmt.Sprint(tt.wantQueued) {
+				t.Errorf("queued %v, want %v", got, tt.wantQueued)
+			}
+		})
+	}
+}
+
+func TestNotificationBufferSize(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ServerOption
+		want int
+	}{
+		{"default", nil, defaultNotificationBuffer},
+		{"configured", []ServerOption{WithNotificationBuffer(8, DropOldest)}, 8},
+		{"non-positive keeps the default", []ServerOption{WithNotificationBuffer(0, Disconnect)}, defaultNotificationBuffer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			session := s.lookupSession(mcptest.NewClient(t, s).SessionID())
+			if got := cap(session.notifications); got != tt.want {
+				t.Errorf("buffer capacity = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListChangedNotifications(t *testing.T) {
+	prompt := PromptRegistration{Definition: protocol.Prompt{Name: "greeting"}, Handler: noPrompt}
+	resource := ResourceRegistration{Definition: protocol.Resource{URI: "file:///notes", Name: "notes"}, Handler: noResource}
+	tool := ToolRegistration{
+		Definition: protocol.Tool{Name: "echo", Description: "Echoes its input."},
+		Handler:    func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil },
+	}
+	tests := []struct {
+		name string
+		// change modifies the server's lists after a client has connected.
+		change     func(s *Server) error
+		advertised bool
+		want       []string
+	}{
+		{"prompt registered", func(s *Server) error { return s.RegisterPrompts([]PromptRegistration{prompt}) }, true,
+			[]string{"notifications/prompts/list_changed"}},
+		{"prompt unregistered", func(s *Server) error {
+			err := s.RegisterPrompts([]PromptRegistration{prompt})
+			s.UnregisterPrompts("greeting", "missing")
+			return err
+		}, true, []string{"notifications/prompts/list_changed", "notifications/prompts/list_changed"}},
+		{"unknown prompt unregistered", func(s *Server) error { s.UnregisterPrompts("missing"); return nil }, true, nil},
+		{"prompt registered without the capability", func(s *Server) error { return s.RegisterPrompts([]PromptRegistration{prompt}) }, false, nil},
+		{"resource registered", func(s *Server) error { return s.RegisterResources([]ResourceRegistration{resource}) }, true,
+			[]string{"notifications/resources/list_changed"}},
+		{"tool registered", func(s *Server) error { return s.RegisterTools([]ToolRegistration{tool}) }, true,
+			[]string{"notifications/tools/list_changed"}},
+		{"tool registered without the capability", func(s *Server) error { return s.RegisterTools([]ToolRegistration{tool}) }, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := protocol.ServerCapabilities{
+				Tools:     &protocol.ServerToolCapabilities{ListChanged: tt.advertised},
+				Resources: &protocol.ServerResourceCapabilities{ListChanged: tt.advertised},
+				Prompts:   &protocol.ServerPromptCapabilities{ListChanged: tt.advertised},
+			}
+			s := NewServer("test", "1.0.0", caps)
+			sessionID := mcptest.NewClient(t, s).SessionID()
+			if err := tt.change(s); err != nil {
+				t.Fatalf("changing lists: %v", err)
+			}
+			if got := queuedNotifications(t, s, sessionID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("notifications = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSEStreamEndsWithSession(t *testing.T) {
+	tests := []struct {
+		name string
+		// end finishes the session (or the client's connection) while the stream is open.
+		end func(s *Server, sessionID string, cancel context.CancelFunc)
+	}{
+		{"DELETE", func(s *Server, sessionID string, cancel context.CancelFunc) {
+			req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+			req.Header.Set("Mcp-Session-Id", sessionID)
+			s.ServeHTTP(httptest.NewRecorder(), req)
+		}},
+		{"session closed", func(s *Server, sessionID string, cancel context.CancelFunc) { s.closeSession(sessionID) }},
+		{"client disconnected", func(s *Server, sessionID string, cancel context.CancelFunc) { cancel() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			sessionID := mcptest.NewClient(t, s).SessionID()
+			session := s.lookupSession(sessionID)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req := httptest.NewRequest(http.MethodGet, "/mcp", nil).WithContext(ctx)
+			req.Header.Set("Mcp-Session-Id", sessionID)
+			exited := make(chan struct{})
+			go func() {
+				defer close(exited)
+				s.ServeHTTP(httptest.NewRecorder(), req)
+			}()
+			for deadline := time.Now().Add(time.Second); session.openStreams.Load() == 0; {
+				if time.Now().After(deadline) {
+					t.Fatal("stream did not open")
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			tt.end(s, sessionID, cancel)
+			select {
+			case <-exited:
+			case <-time.After(time.Second):
+				t.Fatal("stream goroutine did not exit")
+			}
+			if got := session.openStreams.Load(); got != 0 {
+				t.Errorf("openStreams = %d after the stream ended, want 0", got)
+			}
+		})
+	}
+}
+
+// streamRecorder is a ResponseWriter that can be read while a stream writes to it.
+type streamRecorder struct {
+	mu     sync.Mutex
+	header http.Header
+	body   bytes.Buffer
+	// gate, if set, holds every write until it is closed, like a client that stopped
+	// reading, or until the write deadline passes.
+	gate     chan struct{}
+	deadline time.Time
+}
+
+func (r *streamRecorder) Header() http.Header { return r.header }
+
+func (r *streamRecorder) WriteHeader(statusCode int) {}
+
+func (r *streamRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	gate, deadline := r.gate, r.deadline
+	r.mu.Unlock()
+	if gate != nil {
+		var expired <-chan time.Time
+		if !deadline.IsZero() {
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			expired = timer.C
+		}
+		select {
+		case <-gate:
+		case <-expired:
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.Write(p)
+}
+
+// stall makes writes wait until the returned function is called.
+func (r *streamRecorder) stall() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gate = make(chan struct{})
+	return func() { close(r.gate) }
+}
+
+func (r *streamRecorder) Flush() {}
+
+// SetWriteDeadline lets http.ResponseController bound writes, as on a real connection.
+func (r *streamRecorder) SetWriteDeadline(deadline time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deadline = deadline
+	return nil
+}
+
+func (r *streamRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.String()
+}
+
+// openStream opens the session's GET stream, sending lastEventID if it is not empty.
+// It returns what the stream has written so far and a function that closes the stream
+// and waits for it to end.
+func openStream(t *testing.T, s *Server, sessionID, lastEventID string) (*streamRecorder, func()) {
+	t.Helper()
+	session := s.lookupSession(sessionID)
+	before := session.openStreams.Load()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil).WithContext(ctx)
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	rec := &streamRecorder{header: http.Header{}}
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		s.ServeHTTP(rec, req)
+	}()
+	waitFor(t, "the stream to open", func() bool { return session.openStreams.Load() > before })
+	return rec, func() {
+		cancel()
+		<-exited
+	}
+}
+
+// eventIDs returns the ids of the events in an SSE body, in order.
+func eventIDs(body string) []string {
+	var ids []string
+	for _, line := range strings.Split(body, "\n") {
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestSSEStreamResumesAfterLastEventID(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ServerOption
+		lastEventID string
+		// wantIDs are the events the resumed stream delivers; event 4 is sent after it opens.
+		wantIDs []string
+	}{
+		{"no header", nil, "", []string{"4"}},
+		{"after the first", nil, "1", []string{"2", "3", "4"}},
+		{"after all", nil, "3", []string{"4"}},
+		{"from the start", nil, "0", []string{"1", "2", "3", "4"}},
+		{"invalid header", nil, "abc", []string{"4"}},
+		{"bounded replay", []ServerOption{WithReplayBuffer(2)}, "0", []string{"2", "3", "4"}},
+		{"replay disabled", []ServerOption{WithReplayBuffer(0)}, "0", []string{"4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			sessionID := mcptest.NewClient(t, s).SessionID()
+
+			first, closeFirst := openStream(t, s, sessionID, "")
+			for i := 1; i <= 3; i++ {
+				s.broadcastNotification("notifications/message", map[string]int{"n": i})
+			}
+			waitFor(t, "three events", func() bool { return len(eventIDs(first.String())) == 3 })
+			closeFirst()
+
+			resumed, closeResumed := openStream(t, s, sessionID, tt.lastEventID)
+			defer closeResumed()
+			s.broadcastNotification("notifications/message", map[string]int{"n": 4})
+			waitFor(t, "event 4", func() bool { return strings.Contains(resumed.String(), "id: 4\n") })
+			if got := eventIDs(resumed.String()); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("resumed stream delivered events %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestWriteSSEEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		message interface{}
+		maxSize int
+		want    string
+	}{
+		{"message", &protocol.Notification{JSONRPC: "2.0", Method: "notifications/message"},
+			0, "id: 7\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/message\"}\n\n"},
+		{"invalid params", &protocol.Notification{JSONRPC: "2.0", Method: "notifications/message", Params: json.RawMessage(`{"broken"`)},
+			0, "event: error\ndata: {\"code\":-32603,\"message\":\"Internal error: message could not be delivered\""},
+		{"unencodable", map[string]interface{}{"updates": make(chan int)},
+			0, "event: error\ndata: {\"code\":-32603,"},
+		{"oversized", &protocol.Notification{JSONRPC: "2.0", Method: "notifications/message", Params: json.RawMessage(`{"text":"` + strings.Repeat("x", 64) + `"}`)},
+			32, "event: error\ndata: {\"code\":-32603,\"message\":\"Internal error: message could not be delivered\",\"data\":\"message of 135 bytes exceeds the maximum event size of 32 bytes\"}\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeSSEEvent(&buf, 7, tt.message, tt.maxSize); err != nil {
+				t.Fatalf("writeSSEEvent: %v", err)
+			}
+			if !strings.HasPrefix(buf.String(), tt.want) || !strings.HasSuffix(buf.String(), "\n\n") {
+				t.Errorf("wrote %q, want it to start with %q and end the event", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSSEStreamSurvivesUnencodableNotification(t *testing.T) {
+	s := newTestServer(t, nil)
+	sessionID := mcptest.NewClient(t, s).SessionID()
+	session := s.lookupSession(sessionID)
+	rec, closeStream := openStream(t, s, sessionID, "")
+	defer closeStream()
+
+	session.enqueue(&protocol.Notification{JSONRPC: "2.0", Method: "notifications/message", Params: json.RawMessage(`{"broken"`)}, DropOldest)
+	s.broadcastNotification("notifications/message", map[string]string{"text": "after"})
+	waitFor(t, "the next notification", func() bool { return strings.Contains(rec.String(), "after") })
+
+	body := rec.String()
+	if errorAt, nextAt := strings.Index(body, "event: error\n"), strings.Index(body, "after"); errorAt < 0 || errorAt > nextAt {
+		t.Errorf("stream did not report the broken notification before the next one:\n%s", body)
+	}
+}
+
+func TestStreamWriteTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		timeout        time.Duration
+		wantDisconnect bool
+	}{
+		{"write timeout", 50 * time.Millisecond, true},
+		{"no write timeout", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, WithStreamWriteTimeout(tt.timeout))
+			fastID := mcptest.NewClient(t, s).SessionID()
+			slowID := mcptest.NewClient(t, s).SessionID()
+			fast, closeFast := openStream(t, s, fastID, "")
+			defer closeFast()
+			slow, closeSlow := openStream(t, s, slowID, "")
+			release := slow.stall()
+			defer func() {
+				release()
+				closeSlow()
+			}()
+
+			start := time.Now()
+			for i := 1; i <= 3; i++ {
+				s.broadcastNotification("notifications/message", map[string]int{"n": i})
+			}
+			if elapsed := time.Since(start); elapsed > 25*time.Millisecond {
+				t.Errorf("broadcasting took %v; it should not wait for the slow client", elapsed)
+			}
+			waitFor(t, "the fast client to receive every message", func() bool { return len(eventIDs(fast.String())) == 3 })
+			slowSession := s.lookupSession(slowID)
+			if tt.wantDisconnect {
+				waitFor(t, "the slow client to be disconnected", func() bool { return slowSession.openStreams.Load() == 0 })
+				return
+			}
+			time.Sleep(150 * time.Millisecond)
+			if slowSession.openStreams.Load() == 0 {
+				t.Error("the slow client was disconnected without a write timeout")
+			}
+		})
+	}
+}
+
+// unflushableWriter is a ResponseWriter that cannot flush, so it cannot carry a stream.
+type unflushableWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (u *unflushableWriter) Header() http.Header         { return u.header }
+func (u *unflushableWriter) WriteHeader(code int)        { u.code = code }
+func (u *unflushableWriter) Write(p []byte) (int, error) { return u.body.Write(p) }
+
+func TestEventStreamUnavailable(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ServerOption
+		wantReason string
+	}{
+		{"disabled", []ServerOption{WithoutEventStream()}, "does not offer an SSE stream"},
+		{"connection cannot stream", nil, "cannot carry an SSE stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "echo", Description: "Echoes a value."},
+				Handler:    replyWith("pong"),
+			}}, tt.opts...)
+			c := mcptest.NewClient(t, s)
+
+			req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+			req.Header.Set("Mcp-Session-Id", c.SessionID())
+			w := &unflushableWriter{header: http.Header{}}
+			s.ServeHTTP(w, req)
+
+			if w.code != http.StatusMethodNotAllowed {
+				t.Errorf("status = %d, want 405", w.code)
+			}
+			if allow := w.header.Get("Allow"); allow != "POST, DELETE" {
+				t.Errorf("Allow = %q, want %q", allow, "POST, DELETE")
+			}
+			var resp protocol.Response
+			if err := json.Unmarshal(w.body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding %q: %v", w.body.String(), err)
+			}
+			if resp.Error == nil || resp.Error.Code != -32000 || !strings.Contains(resp.Error.Message, tt.wantReason) {
+				t.Errorf("error = %+v, want -32000 saying it %s", resp.Error, tt.wantReason)
+			}
+
+			// Requests over POST are unaffected.
+			if got := textOf(t, c.CallTool("echo", map[string]string{"value": "ping"})); got != "pong" {
+				t.Errorf("tools/call = %q, want pong", got)
+			}
+		})
+	}
+
+	t.Run("disabled sessions queue nothing", func(t *testing.T) {
+		s := newTestServer(t, nil, WithoutEventStream())
+		c := mcptest.NewClient(t, s)
+		s.broadcastNotification("notifications/tools/list_changed", nil)
+		if session := s.lookupSession(c.SessionID()); session.notifications != nil {
+			t.Errorf("session has a notification queue of %d", cap(session.notifications))
+		}
+	})
+}
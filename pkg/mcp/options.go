@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// ServerOption configures optional behaviour of a Server.
+type ServerOption func(*Server)
+
+// WithTitle sets the human-readable display title reported in the server info,
+// separately from the machine-readable name passed to NewServer.
+func WithTitle(title string) ServerOption {
+	return func(s *Server) {
+		s.info.Title = title
+	}
+}
+
+// WithProtocolVersion pins the protocol version returned from "initialize".
+// When set, it takes precedence over the version requested by the client, as long as
+// the client requested a supported version; other clients are negotiated with as usual.
+func WithProtocolVersion(version string) ServerOption {
+	return func(s *Server) {
+		s.protocolVersion = version
+	}
+}
+
+// WithPositionalArguments allows "tools/call" arguments to be sent as a JSON array.
+// Elements are mapped to the input struct's fields in declaration order. This is off
+// by default because a short array cannot be told apart from deliberately omitted fields.
+func WithPositionalArguments() ServerOption {
+	return func(s *Server) {
+		s.positionalArguments = true
+	}
+}
+
+// ResultInterceptor inspects or rewrites a tool's result before it is sent to the client.
+// Returning nil keeps the original result.
+type ResultInterceptor func(ctx context.Context, tool string, result *protocol.CallToolResult) *protocol.CallToolResult
+
+// WithResultInterceptor runs interceptor on every tool result, including error results,
+// after the handler returns and before the response is serialized.
+func WithResultInterceptor(interceptor ResultInterceptor) ServerOption {
+	return func(s *Server) {
+		s.resultInterceptor = interceptor
+	}
+}
+
+// WithSchemaReferences makes generated input schemas define each named struct type once
+// under "$defs" and refer to it with "$ref", rather than inlining it at every use.
+// This keeps schemas small when many parameters share the same sub-objects.
+func WithSchemaReferences() ServerOption {
+	return func(s *Server) {
+		s.schemaOptions.UseReferences = true
+	}
+}
+
+// WithH2C lets ListenAndServe accept HTTP/2 over plaintext (h2c, with prior knowledge)
+// alongside HTTP/1.1, so clients can multiplex many calls over one connection.
+//
+// It uses the unencrypted HTTP/2 support built into net/http since Go 1.24 rather than
+// golang.org/x/net/http2/h2c, which would add a dependency for the same behavior. Only
+// prior knowledge is supported, not the HTTP/1.1 Upgrade to h2c; clients that speak h2c
+// start with the HTTP/2 preface. Servers mounted on another http.Server get h2c from that
+// server's Protocols instead.
+func WithH2C() ServerOption {
+	return func(s *Server) {
+		s.enableH2C = true
+	}
+}
+
+// WithKeepAlive configures HTTP keep-alives for ListenAndServe. idleTimeout bounds how long
+// an idle connection is kept open; zero uses the net/http default.
+func WithKeepAlive(enabled bool, idleTimeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.disableKeepAlives = !enabled
+		s.idleTimeout = idleTimeout
+	}
+}
+
+// WithLenientInitialization accepts requests from sessions that have not yet sent
+// "notifications/initialized". By default such requests are rejected, as the spec requires
+// the client to complete the handshake before issuing other calls.
+func WithLenientInitialization() ServerOption {
+	return func(s *Server) {
+		s.lenientInit = true
+	}
+}
+
+// WithOutputValidation checks each tool's structured content against its output schema
+// before sending it. A mismatch is logged and reported to the client as an internal error.
+func WithOutputValidation() ServerOption {
+	return func(s *Server) {
+		s.outputValidation = true
+	}
+}
+
+// WithPath sets the URL path the server handles, "/mcp" by default. Together with
+// Handler it lets several servers share one http.ServeMux, each at its own path
+// and with its own sessions. The optional endpoints are served below it: the manifest at
+// path + "/manifest", metrics at path + "/metrics" and query string tool calls at
+// path + "/tools/".
+func WithPath(path string) ServerOption {
+	return func(s *Server) {
+		s.path = path
+	}
+}
+
+// WithSessionIDGenerator replaces the function used to create session ids.
+// By default each session gets a random UUID. Generated ids must be unique and
+// hard to guess, since anyone holding an id can act within that session.
+func WithSessionIDGenerator(generate func() string) ServerOption {
+	return func(s *Server) {
+		if generate != nil {
+			s.sessionIDGenerator = generate
+		}
+	}
+}
+
+// WithErrorMessages replaces the message of JSON-RPC error responses by error code, for
+// example to localize them or to match the wording of other services. Codes without an
+// entry keep the server's default message. The error's data, which carries the details
+// of what went wrong, is sent unchanged; if there is none, the replaced message is sent
+// as the data instead so the details are not lost.
+func WithErrorMessages(messages map[int]string) ServerOption {
+	return func(s *Server) {
+		s.errorMessages = make(map[int]string, len(messages))
+		for code, message := range messages {
+			s.errorMessages[code] = message
+		}
+	}
+}
+
+// WithStrictToolValidation makes registering a tool without a description an error.
+// Without it such tools are registered with a warning in the log.
+func WithStrictToolValidation() ServerOption {
+	return func(s *Server) {
+		s.strictToolValidation = true
+	}
+}
+
+// WithMaxTools caps how many tools may be registered with the server, guarding against
+// runaway registration such as a bug registering tools in a loop. Once the limit is
+// reached, further registrations fail. Built-in tools, such as those added by
+// WithDebugTools, count towards the limit, while session tools and tools from a
+// ToolProvider do not. Zero, the default, means no limit.
+func WithMaxTools(n int) ServerOption {
+	return func(s *Server) {
+		if n >= 0 {
+			s.maxTools = n
+		}
+	}
+}
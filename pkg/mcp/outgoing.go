@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// serverRequestIDPrefix marks ids of server-initiated requests (sampling, elicitation, roots)
+// so they can never collide with ids chosen by the client.
+const serverRequestIDPrefix = "srv-"
+
+// outgoingRequests correlates server-initiated requests with the client's responses.
+type outgoingRequests struct {
+	nextID  atomic.Uint64
+	lock    sync.Mutex
+	pending map[string]chan *protocol.Response
+}
+
+// newRequestID returns a fresh, unique id for a server-initiated request.
+func (o *outgoingRequests) newRequestID() protocol.RequestID {
+	return protocol.NewRequestID(serverRequestIDPrefix + strconv.FormatUint(o.nextID.Add(1), 10))
+}
+
+// register allocates an id and returns it with a channel that receives the matching response.
+func (o *outgoingRequests) register() (protocol.RequestID, <-chan *protocol.Response) {
+	id := o.newRequestID()
+	ch := make(chan *protocol.Response, 1)
+
+	o.lock.Lock()
+	if o.pending == nil {
+		o.pending = make(map[string]chan *protocol.Response)
+	}
+	o.pending[id.String()] = ch
+	o.lock.Unlock()
+	return id, ch
+}
+
+// cancel forgets a pending request, e.g. when the caller stops waiting.
+func (o *outgoingRequests) cancel(id protocol.RequestID) {
+	o.lock.Lock()
+	delete(o.pending, id.String())
+	o.lock.Unlock()
+}
+
+// resolve delivers a client response to the waiting request. It reports whether anyone was waiting.
+func (o *outgoingRequests) resolve(resp *protocol.Response) bool {
+	o.lock.Lock()
+	ch, ok := o.pending[resp.ID.String()]
+	delete(o.pending, resp.ID.String())
+	o.lock.Unlock()
+	if !ok {
+		return false
+	}
+	ch <- resp
+	return true
+}
+
+// handleClientResponse routes a response sent by the client to a server-initiated request.
+func (s *Server) handleClientResponse(resp *protocol.Response) {
+	if !s.outgoing.resolve(resp) {
+		log.Warnf("Received response for unknown request ID=%s", resp.ID.String())
+	}
+}
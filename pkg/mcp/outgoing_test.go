@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestOutgoingRequestIDsAreUnique(t *testing.T) {
+	const goroutines, perGoroutine = 16, 500
+	var o outgoingRequests
+	ids := make(chan string, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				id, _ := o.register()
+				ids <- id.String()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if !strings.HasPrefix(id, serverRequestIDPrefix) {
+			t.Errorf("id %q lacks the %q prefix", id, serverRequestIDPrefix)
+		}
+		if seen[id] {
+			t.Fatalf("id %q was issued twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != goroutines*perGoroutine {
+		t.Errorf("issued %d ids, want %d", len(seen), goroutines*perGoroutine)
+	}
+}
+
+func TestOutgoingRequestsResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		// respond returns the id of the response delivered after a request was registered as id.
+		respond     func(o *outgoingRequests, id protocol.RequestID) protocol.RequestID
+		wantWaiting bool
+	}{
+		{"matching response", func(o *outgoingRequests, id protocol.RequestID) protocol.RequestID { return id }, true},
+		{"client id", func(o *outgoingRequests, id protocol.RequestID) protocol.RequestID {
+			return protocol.NewNumericRequestID(1)
+		}, false},
+		{"cancelled request", func(o *outgoingRequests, id protocol.RequestID) protocol.RequestID {
+			o.cancel(id)
+			return id
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o outgoingRequests
+			id, ch := o.register()
+			resp := &protocol.Response{JSONRPC: "2.0", ID: tt.respond(&o, id)}
+			if got := o.resolve(resp); got != tt.wantWaiting {
+				t.Fatalf("resolve = %v, want %v", got, tt.wantWaiting)
+			}
+			select {
+			case got := <-ch:
+				if !tt.wantWaiting {
+					t.Errorf("received %+v, but nobody was waiting", got)
+				} else if got != resp {
+					t.Errorf("received %+v, want %+v", got, resp)
+				}
+			default:
+				if tt.wantWaiting {
+					t.Error("response was not delivered")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go-mcp-sdk/internal/jsonschema"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// DecodeParams decodes a request's params into dst, which must be a pointer.
+//
+// JSON-RPC allows params to be given either by name (an object) or by position
+// (an array). Objects are decoded as usual. Arrays are mapped onto the fields of
+// the struct dst points to, in declaration order, using each field's JSON name.
+// Absent or null params leave dst unchanged.
+//
+// On failure the returned error is a *protocol.ErrorObject with code -32602
+// (invalid params), ready to be sent back to the client.
+func DecodeParams(req *protocol.Request, dst interface{}) error {
+	params := bytes.TrimSpace(req.Params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return nil
+	}
+
+	switch params[0] {
+	case '{':
+		if err := json.Unmarshal(params, dst); err != nil {
+			return invalidParams(req.Method, err)
+		}
+		return nil
+	case '[':
+		target := reflect.TypeOf(dst)
+		if target == nil || target.Kind() != reflect.Ptr {
+			return invalidParams(req.Method, fmt.Errorf("positional params require a pointer destination, got %T", dst))
+		}
+		var positional []json.RawMessage
+		if err := json.Unmarshal(params, &positional); err != nil {
+			return invalidParams(req.Method, err)
+		}
+		args := make([]interface{}, len(positional))
+		for i, p := range positional {
+			args[i] = p
+		}
+		named, err := positionalToNamed(target.Elem(), args, jsonschema.NameAsDeclared)
+		if err != nil {
+			return invalidParams(req.Method, err)
+		}
+		namedBytes, err := json.Marshal(named)
+		if err != nil {
+			return invalidParams(req.Method, err)
+		}
+		if err := json.Unmarshal(namedBytes, dst); err != nil {
+			return invalidParams(req.Method, err)
+		}
+		return nil
+	default:
+		return invalidParams(req.Method, fmt.Errorf("params must be an object or an array"))
+	}
+}
+
+func invalidParams(method string, err error) *protocol.ErrorObject {
+	return &protocol.ErrorObject{
+		Code:    -32602,
+		Message: fmt.Sprintf("Invalid params for %s", method),
+		Data:    err.Error(),
+	}
+}
+
+// WithRequiredArgumentChecks rejects tool calls that leave out, or send null for, an
+// argument the tool's input schema lists as required, before the handler runs. The
+// error names each offending argument and says whether it was missing or null. Without
+// this option both cases decode to the field's zero value. Optional arguments, such as
+// pointer fields, are unaffected: null and absence both leave them nil.
+func WithRequiredArgumentChecks() ServerOption {
+	return func(s *Server) {
+		s.requiredArgumentChecks = true
+	}
+}
+
+// requiredArguments returns the names listed in the top-level "required" keyword of an
+// object schema.
+func requiredArguments(schema json.RawMessage) []string {
+	var parsed struct {
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(schema, &parsed); err != nil {
+		return nil
+	}
+	return parsed.Required
+}
+
+// checkRequiredArguments reports every name in required that args, a JSON object, does
+// not contain or sets to null.
+func checkRequiredArguments(args json.RawMessage, required []string) error {
+	var fields map[string]json.RawMessage
+	if trimmed := bytes.TrimSpace(args); len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return err
+		}
+	}
+
+	var problems []string
+	for _, name := range required {
+		value, present := fields[name]
+		switch {
+		case !present:
+			problems = append(problems, fmt.Sprintf("missing required argument %q", name))
+		case bytes.Equal(bytes.TrimSpace(value), []byte("null")):
+			problems = append(problems, fmt.Sprintf("required argument %q must not be null", name))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type pageParams struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+func TestDecodeParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  string
+		want    pageParams
+		wantErr bool
+	}{
+		{"object", `{"cursor":"abc","limit":10}`, pageParams{"abc", 10}, false},
+		{"array", `["abc",10]`, pageParams{"abc", 10}, false},
+		{"short array", `["abc"]`, pageParams{"abc", 0}, false},
+		{"absent", ``, pageParams{}, false},
+		{"null", `null`, pageParams{}, false},
+		{"object with wrong type", `{"limit":"ten"}`, pageParams{}, true},
+		{"array with wrong type", `["abc","ten"]`, pageParams{}, true},
+		{"too many positional params", `["abc",10,true]`, pageParams{}, true},
+		{"scalar", `"abc"`, pageParams{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &protocol.Request{JSONRPC: "2.0", Method: "items/list", Params: json.RawMessage(tt.params)}
+			var got pageParams
+			err := DecodeParams(req, &got)
+			if tt.wantErr {
+				var errObj *protocol.ErrorObject
+				if !errors.As(err, &errObj) || errObj.Code != -32602 {
+					t.Fatalf("error = %v, want a -32602 error object", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeParams: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("decoded %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type contactInput struct {
+	Name  string `json:"name" jsonschema:"required"`
+	Email string `json:"email" jsonschema:"required"`
+	Age   *int   `json:"age"`
+}
+
+func TestRequiredArgumentChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		checks    bool
+		arguments string
+		wantErr   []string
+		want      string
+	}{
+		{"all present", true, `{"name":"Ada","email":"ada@example.com","age":36}`, nil, "Ada ada@example.com 36"},
+		{"optional null", true, `{"name":"Ada","email":"ada@example.com","age":null}`, nil, "Ada ada@example.com nil"},
+		{"optional missing", true, `{"name":"Ada","email":"ada@example.com"}`, nil, "Ada ada@example.com nil"},
+		{"required missing", true, `{"email":"ada@example.com"}`, []string{`missing required argument "name"`}, ""},
+		{"required null", true, `{"name":null,"email":"ada@example.com"}`, []string{`required argument "name" must not be null`}, ""},
+		{"missing and null", true, `{"email":null}`, []string{`missing required argument "name"`, `required argument "email" must not be null`}, ""},
+		{"no arguments", true, `null`, []string{`missing required argument "name"`, `missing required argument "email"`}, ""},
+		{"checks off", false, `{"name":null}`, nil, "  nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []ServerOption
+			if tt.checks {
+				opts = append(opts, WithRequiredArgumentChecks())
+			}
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "contact", Description: "Saves a contact."},
+				Handler: func(ctx context.Context, in *contactInput) (string, error) {
+					age := "nil"
+					if in.Age != nil {
+						age = fmt.Sprint(*in.Age)
+					}
+					return in.Name + " " + in.Email + " " + age, nil
+				},
+			}}, opts...)
+			resp := mcptest.NewClient(t, s).Call("tools/call", json.RawMessage(`{"name":"contact","arguments":`+tt.arguments+`}`))
+			if len(tt.wantErr) > 0 {
+				if resp.Error == nil || resp.Error.Code != -32602 {
+					t.Fatalf("error = %+v, want -32602", resp.Error)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(resp.Error.Message, want) {
+						t.Errorf("message %q does not mention %q", resp.Error.Message, want)
+					}
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("tools/call failed: %+v", resp.Error)
+			}
+			var result protocol.CallToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatal(err)
+			}
+			if got := textOf(t, &result); got != tt.want {
+				t.Errorf("result = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"go-mcp-sdk/internal/jsonschema"
+)
+
+// inputPatterns compiles the regular expressions in the 'pattern' tags of an input
+// type's fields, including the fields of nested structs, keyed by the tag. It returns
+// nil if the type has none.
+func inputPatterns(t reflect.Type) (map[string]*regexp.Regexp, error) {
+	var patterns map[string]*regexp.Regexp
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type) error
+	walk = func(t reflect.Type) error {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			return walk(t.Elem())
+		case reflect.Struct:
+		default:
+			return nil
+		}
+		if seen[t] {
+			return nil
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if pattern := field.Tag.Get("pattern"); pattern != "" && patterns[pattern] == nil {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return fmt.Errorf("invalid pattern for field %s: %w", field.Name, err)
+				}
+				if patterns == nil {
+					patterns = make(map[string]*regexp.Regexp)
+				}
+				patterns[pattern] = re
+			}
+			if err := walk(field.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(t); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// checkPatterns reports the first string in v, a decoded input, that does not match
+// the 'pattern' tag of its field. path names v in the error.
+func checkPatterns(v reflect.Value, patterns map[string]*regexp.Regexp, naming jsonschema.FieldNaming, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return checkPatterns(v.Elem(), patterns, naming, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkPatterns(v.Index(i), patterns, naming, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := checkPatterns(iter.Value(), patterns, naming, fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			fieldPath := path
+			if name, _, ok := jsonschema.FieldName(field, naming); ok {
+				if fieldPath != "" {
+					fieldPath += "."
+				}
+				fieldPath += name
+			}
+			if pattern := field.Tag.Get("pattern"); pattern != "" {
+				if err := matchPattern(v.Field(i), patterns[pattern], fieldPath); err != nil {
+					return err
+				}
+			}
+			if err := checkPatterns(v.Field(i), patterns, naming, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchPattern checks the string held by v, directly, through a pointer, or as the
+// elements of a slice or array, against re.
+func matchPattern(v reflect.Value, re *regexp.Regexp, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return matchPattern(v.Elem(), re, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := matchPattern(v.Index(i), re, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if !re.MatchString(v.String()) {
+			return fmt.Errorf("argument '%s' must match the pattern %s", path, re)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type flightInput struct {
+	From    string   `json:"from" pattern:"^[A-Z]{3}$"`
+	Via     []string `json:"via" pattern:"^[A-Z]{3}$"`
+	Carrier *string  `json:"carrier" pattern:"^[A-Z0-9]{2}$"`
+	Seat    struct {
+		Row string `json:"row" pattern:"^[0-9]{1,2}[A-F]$"`
+	} `json:"seat"`
+}
+
+func TestPatternTags(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		wantErr   string
+	}{
+		{"all match", `{"from":"OSL","via":["CPH","AMS"],"carrier":"SK","seat":{"row":"12C"}}`, ""},
+		{"optional field absent", `{"from":"OSL","seat":{"row":"1A"}}`, ""},
+		{"field", `{"from":"osl","seat":{"row":"1A"}}`, "argument 'from' must match the pattern ^[A-Z]{3}$"},
+		{"slice element", `{"from":"OSL","via":["CPH","amsterdam"],"seat":{"row":"1A"}}`, "argument 'via[1]' must match"},
+		{"pointer", `{"from":"OSL","carrier":"SAS","seat":{"row":"1A"}}`, "argument 'carrier' must match"},
+		{"nested field", `{"from":"OSL","seat":{"row":"13G"}}`, "argument 'seat.row' must match"},
+	}
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "book", Description: "Books a flight."},
+		Handler:    func(ctx context.Context, in *flightInput) (string, error) { return "booked", nil },
+	}})
+	c := mcptest.NewClient(t, s)
+
+	tool, _ := s.lookupTool(context.Background(), "book")
+	var schema bytes.Buffer
+	if err := json.Compact(&schema, tool.Definition.InputSchema); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"pattern":"^[A-Z]{3}$"`, `"pattern":"^[0-9]{1,2}[A-F]$"`} {
+		if !strings.Contains(schema.String(), want) {
+			t.Errorf("input schema does not carry %s: %s", want, schema.String())
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := c.Call("tools/call", json.RawMessage(`{"name":"book","arguments":`+tt.arguments+`}`))
+			if tt.wantErr == "" {
+				if resp.Error != nil {
+					t.Errorf("matching arguments rejected: %+v", resp.Error)
+				}
+				return
+			}
+			if resp.Error == nil || resp.Error.Code != -32602 {
+				t.Fatalf("error = %+v, want -32602", resp.Error)
+			}
+			if detail := resp.Error.Message + " " + resp.Error.Data.(string); !strings.Contains(detail, tt.wantErr) {
+				t.Errorf("error %q does not mention %q", detail, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPatternTagsMustCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler interface{}
+		wantErr string
+	}{
+		{"invalid regex", func(ctx context.Context, in *struct {
+			Code string `json:"code" pattern:"^[A-Z"`
+		}) (string, error) {
+			return "", nil
+		}, "invalid pattern for field Code"},
+		{"not a string", func(ctx context.Context, in *struct {
+			Count int `json:"count" pattern:"^[0-9]+$"`
+		}) (string, error) {
+			return "", nil
+		}, "does not hold strings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewServer("test", "1.0.0", testCapabilities).RegisterTools([]ToolRegistration{{
+				Definition: protocol.Tool{Name: "code", Description: "Checks a code."},
+				Handler:    tt.handler,
+			}})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RegisterTools error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
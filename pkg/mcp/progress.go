@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// contextWithProgressToken returns a copy of ctx carrying the progress token of the current request.
+func contextWithProgressToken(ctx context.Context, token json.RawMessage) context.Context {
+	return context.WithValue(ctx, progressTokenKey, token)
+}
+
+// ProgressTokenFromContext returns the progress token the client sent in the current
+// request's "_meta.progressToken", exactly as sent: a JSON string or number. It returns
+// nil if the client did not ask for progress. ReportProgress uses this token; handlers
+// only need it to send progress by other means, such as from a background job.
+func ProgressTokenFromContext(ctx context.Context) json.RawMessage {
+	token, _ := ctx.Value(progressTokenKey).(json.RawMessage)
+	return token
+}
+
+// progressToken extracts "_meta.progressToken" from a request's params. Params that are
+// not an object, or carry no token, yield nil.
+func progressToken(params json.RawMessage) json.RawMessage {
+	var envelope struct {
+		Meta *protocol.RequestMeta `json:"_meta"`
+	}
+	if len(params) == 0 || json.Unmarshal(params, &envelope) != nil || envelope.Meta == nil {
+		return nil
+	}
+	token := bytes.TrimSpace(envelope.Meta.ProgressToken)
+	if bytes.Equal(token, []byte("null")) {
+		return nil
+	}
+	return token
+}
+
+// ReportProgress tells the client how far the current tool call has got. progress should
+// increase with each call; total may be zero if unknown. It does nothing unless the client
+// sent a progress token with the request.
+//
+// When the client accepts an SSE response, progress is streamed ahead of the result on the
+// call's own response; otherwise it is queued for the session's GET stream.
+func ReportProgress(ctx context.Context, progress, total float64, message string) {
+	token := ProgressTokenFromContext(ctx)
+	server, _ := ctx.Value(serverKey).(*Server)
+	if len(token) == 0 || server == nil {
+		return
+	}
+
+	notif, err := newNotification("notifications/progress", protocol.ProgressNotification{
+		ProgressToken: token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+	if err != nil {
+		log.Errorf("Failed to build progress notification: %v", err)
+		return
+	}
+	server.sendNotification(ctx, notif)
+}
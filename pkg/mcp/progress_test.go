@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// newProgressServer serves a tool that reports three steps of progress before answering.
+func newProgressServer(t testing.TB) *Server {
+	t.Helper()
+	return newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "import", Description: "Imports records."},
+		Handler: func(ctx context.Context, in *echoInput) (string, error) {
+			for step := 1; step <= 3; step++ {
+				ReportProgress(ctx, float64(step), 3, "")
+			}
+			return "imported", nil
+		},
+	}})
+}
+
+func TestProgressIsStreamedBeforeTheResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		// accept is the request's Accept header.
+		accept          string
+		wantContentType string
+		wantStreamed    []string
+		wantQueued      []string
+	}{
+		{"progress token, SSE accepted", `{"name":"import","arguments":{"value":"x"},"_meta":{"progressToken":"job-1"}}`,
+			"application/json, text/event-stream", "text/event-stream",
+			[]string{"notifications/progress", "notifications/progress", "notifications/progress"}, nil},
+		{"progress token, JSON only", `{"name":"import","arguments":{"value":"x"},"_meta":{"progressToken":7}}`,
+			"application/json", "application/json",
+			nil, []string{"notifications/progress", "notifications/progress", "notifications/progress"}},
+		{"no progress token", `{"name":"import","arguments":{"value":"x"}}`,
+			"application/json, text/event-stream", "application/json", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newProgressServer(t)
+			sessionID := mcptest.NewClient(t, s).SessionID()
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+tt.params+`}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", tt.accept)
+			req.Header.Set("Mcp-Session-Id", sessionID)
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantContentType) {
+				t.Fatalf("Content-Type = %q, want %s", got, tt.wantContentType)
+			}
+			var streamed []string
+			var last string
+			for _, line := range strings.Split(rec.Body.String(), "\n") {
+				data, ok := strings.CutPrefix(line, "data: ")
+				if !ok {
+					continue
+				}
+				var notif protocol.Notification
+				if json.Unmarshal([]byte(data), &notif) == nil && notif.Method != "" {
+					streamed = append(streamed, notif.Method)
+				}
+				last = data
+			}
+			if tt.wantContentType == "application/json" {
+				last = rec.Body.String()
+			}
+			if !reflect.DeepEqual(streamed, tt.wantStreamed) {
+				t.Errorf("streamed %v, want %v", streamed, tt.wantStreamed)
+			}
+			if !strings.Contains(last, `"result"`) || !strings.Contains(last, "imported") {
+				t.Errorf("last message is not the result: %s", last)
+			}
+			if got := queuedNotifications(t, s, sessionID); !reflect.DeepEqual(got, tt.wantQueued) {
+				t.Errorf("queued %v for the session stream, want %v", got, tt.wantQueued)
+			}
+		})
+	}
+}
+
+func TestProgressThroughMcptest(t *testing.T) {
+	c := mcptest.NewClient(t, newProgressServer(t))
+	resp := c.Call("tools/call", json.RawMessage(`{"name":"import","arguments":{"value":"x"},"_meta":{"progressToken":"job-1"}}`))
+	if resp.Error != nil {
+		t.Fatalf("tools/call failed: %d %s", resp.Error.Code, resp.Error.Message)
+	}
+	var progress []float64
+	for _, notif := range c.Notifications() {
+		var p protocol.ProgressNotification
+		if err := json.Unmarshal(notif.Params, &p); err != nil {
+			t.Fatalf("decoding progress: %v", err)
+		}
+		if string(p.ProgressToken) != `"job-1"` {
+			t.Errorf("progress token = %s, want \"job-1\"", p.ProgressToken)
+		}
+		progress = append(progress, p.Progress)
+	}
+	if want := []float64{1, 2, 3}; !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+}
+
+func TestProgressTokenFromContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		want   string
+	}{
+		{"string token", `{"name":"token","_meta":{"progressToken":"job-1"}}`, `"job-1"`},
+		{"number token", `{"name":"token","_meta":{"progressToken":42}}`, `42`},
+		{"null token", `{"name":"token","_meta":{"progressToken":null}}`, ``},
+		{"meta without a token", `{"name":"token","_meta":{}}`, ``},
+		{"no meta", `{"name":"token"}`, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got json.RawMessage
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "token", Description: "Reports its progress token."},
+				Handler: func(ctx context.Context, in *echoInput) error {
+					got = ProgressTokenFromContext(ctx)
+					return nil
+				},
+			}})
+			sessionID := mcptest.NewClient(t, s).SessionID()
+			rec := post(t, s, sessionID, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+tt.params+`}`)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+			}
+			if string(got) != tt.want {
+				t.Errorf("ProgressTokenFromContext = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PromptHandler renders a prompt's messages from the arguments supplied by the client.
+type PromptHandler func(ctx context.Context, args map[string]string) ([]protocol.PromptMessage, error)
+
+// PromptRegistration is a struct to define and register prompts.
+type PromptRegistration struct {
+	Definition protocol.Prompt
+	Handler    PromptHandler
+}
+
+// RegisterPrompts registers a slice of prompts, making them available to clients.
+// It stops at the first prompt that fails to register; the prompts before it stay
+// registered, and clients are told about them.
+func (s *Server) RegisterPrompts(registrations []PromptRegistration) error {
+	registered := 0
+	defer func() {
+		if registered > 0 {
+			s.notifyListChanged(listPrompts)
+		}
+	}()
+	for _, reg := range registrations {
+		if err := s.registerSinglePrompt(reg); err != nil {
+			return fmt.Errorf("failed to register prompt '%s': %w", reg.Definition.Name, err)
+		}
+		registered++
+	}
+	return nil
+}
+
+// UnregisterPrompts removes the prompts with the given names.
+// Names that are not registered are ignored.
+func (s *Server) UnregisterPrompts(names ...string) {
+	removed := 0
+	s.promptLock.Lock()
+	for _, name := range names {
+		if _, exists := s.prompts[name]; exists {
+			delete(s.prompts, name)
+			removed++
+			log.Infof("Unregistered prompt: %s", name)
+		}
+	}
+	s.promptLock.Unlock()
+
+	if removed > 0 {
+		s.notifyListChanged(listPrompts)
+	}
+}
+
+// registerSinglePrompt is the internal helper that processes one prompt registration.
+func (s *Server) registerSinglePrompt(reg PromptRegistration) error {
+	if reg.Definition.Name == "" {
+		return fmt.Errorf("prompt definition must include a name")
+	}
+	if reg.Handler == nil {
+		return fmt.Errorf("prompt handler must not be nil")
+	}
+
+	s.promptLock.Lock()
+	defer s.promptLock.Unlock()
+
+	if _, exists := s.prompts[reg.Definition.Name]; exists {
+		return fmt.Errorf("prompt with name '%s' already registered", reg.Definition.Name)
+	}
+	s.prompts[reg.Definition.Name] = reg
+
+	log.Infof("Registered prompt: %s", reg.Definition.Name)
+	return nil
+}
+
+// validatePromptMessages checks that the messages produced by a prompt handler are well-formed.
+// Custom content types are checked by their registered validators, and embedded resources
+// must carry their contents and declare a MIME type so clients can render them.
+func validatePromptMessages(messages []protocol.PromptMessage) error {
+	for i, msg := range messages {
+		if err := protocol.ValidateContentBlock(msg.Content); err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+		if msg.Content.Type != "resource" {
+			continue
+		}
+		if msg.Content.Resource == nil {
+			return fmt.Errorf("message %d has type 'resource' but no embedded resource", i)
+		}
+		if msg.Content.Resource.URI == "" {
+			return fmt.Errorf("message %d embeds a resource without a URI", i)
+		}
+		if msg.Content.Resource.MIMEType == "" {
+			return fmt.Errorf("message %d embeds resource '%s' without a MIME type", i, msg.Content.Resource.URI)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func noPrompt(ctx context.Context, args map[string]string) ([]protocol.PromptMessage, error) {
+	return nil, nil
+}
+
+func TestListPromptsIsSorted(t *testing.T) {
+	s := newTestServer(t, nil)
+	var regs []PromptRegistration
+	for _, name := range []string{"zeta", "alpha", "mu", "beta", "omega"} {
+		regs = append(regs, PromptRegistration{Definition: protocol.Prompt{Name: name}, Handler: noPrompt})
+	}
+	if err := s.RegisterPrompts(regs); err != nil {
+		t.Fatalf("RegisterPrompts: %v", err)
+	}
+	c := mcptest.NewClient(t, s)
+
+	want := []string{"alpha", "beta", "mu", "omega", "zeta"}
+	for i := 0; i < 5; i++ {
+		var result protocol.ListPromptsResult
+		if err := json.Unmarshal(c.Call("prompts/list", nil).Result, &result); err != nil {
+			t.Fatalf("decoding prompts/list: %v", err)
+		}
+		var got []string
+		for _, prompt := range result.Prompts {
+			got = append(got, prompt.Name)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("prompts/list = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRegisterPromptsNotifiesForInstalledPrompts(t *testing.T) {
+	tests := []struct {
+		name    string
+		batch   []PromptRegistration
+		wantErr bool
+		want    []string
+	}{
+		{"all registered", []PromptRegistration{
+			{Definition: protocol.Prompt{Name: "a"}, Handler: noPrompt},
+			{Definition: protocol.Prompt{Name: "b"}, Handler: noPrompt},
+		}, false, []string{"notifications/prompts/list_changed"}},
+		{"second fails", []PromptRegistration{
+			{Definition: protocol.Prompt{Name: "a"}, Handler: noPrompt},
+			{Definition: protocol.Prompt{Name: "b"}},
+		}, true, []string{"notifications/prompts/list_changed"}},
+		{"first fails", []PromptRegistration{
+			{Definition: protocol.Prompt{Name: ""}, Handler: noPrompt},
+		}, true, nil},
+	}
+	caps := testCapabilities
+	caps.Prompts = &protocol.ServerPromptCapabilities{ListChanged: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("test", "1.0.0", caps)
+			sessionID := mcptest.NewClient(t, s).SessionID()
+			if err := s.RegisterPrompts(tt.batch); (err != nil) != tt.wantErr {
+				t.Fatalf("RegisterPrompts error = %v, want error: %v", err, tt.wantErr)
+			}
+			if got := queuedNotifications(t, s, sessionID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("notifications = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ToolProvider supplies tools that are looked up on demand instead of being registered
+// up front, e.g. tools backed by a database or a remote service.
+// Implementations must be safe for concurrent use.
+type ToolProvider interface {
+	// List returns the definitions of the tools currently offered. Only their names are
+	// used: "tools/list" resolves each tool and sends the definition built from its
+	// registration, exactly as "tools/get" does.
+	List(ctx context.Context) []protocol.Tool
+	// Resolve returns the registration for the named tool, or false if there is no such tool.
+	// The registration is processed exactly as one passed to RegisterTools.
+	Resolve(ctx context.Context, name string) (ToolRegistration, bool)
+}
+
+// WithToolProvider consults provider for tools in addition to those registered with
+// RegisterTools, which remain the default registry: a registered tool takes precedence
+// over a provided tool with the same name.
+func WithToolProvider(provider ToolProvider) ServerOption {
+	return func(s *Server) {
+		s.toolProvider = provider
+	}
+}
+
+// providedTool is a tool built from a provider's registration, kept so that later calls
+// with the same registration skip schema generation.
+type providedTool struct {
+	// fingerprint identifies the registration the tool was built from; see registrationFingerprint.
+	fingerprint string
+	handlerType reflect.Type
+	tool        internalRegisteredTool
+}
+
+// resolveProvidedTool asks the tool provider, if any, for the named tool. The tool is
+// built once per registration: while the provider keeps returning the same definition
+// and settings for a name, with a handler of the same type, the built tool is reused,
+// including its concurrency limit. The registration's functions are taken afresh each
+// time, so a provider may return a new handler or closure on every call.
+func (s *Server) resolveProvidedTool(ctx context.Context, name string) (internalRegisteredTool, bool) {
+	if s.toolProvider == nil {
+		return internalRegisteredTool{}, false
+	}
+	reg, ok := s.toolProvider.Resolve(ctx, name)
+	if !ok {
+		return internalRegisteredTool{}, false
+	}
+	if reg.Definition.Name == "" {
+		reg.Definition.Name = name
+	}
+
+	fingerprint := registrationFingerprint(reg)
+	handlerType := reflect.TypeOf(reg.Handler)
+	s.providedLock.Lock()
+	cached, exists := s.providedTools[name]
+	s.providedLock.Unlock()
+	if exists && fingerprint != "" && cached.fingerprint == fingerprint && cached.handlerType == handlerType {
+		tool := cached.tool
+		tool.handlerValue = reflect.ValueOf(reg.Handler)
+		tool.validate = reg.Validate
+		tool.visible = reg.Visible
+		tool.selfTest = reg.SelfTest
+		return tool, true
+	}
+
+	tool, err := s.buildTool(reg)
+	if err == nil {
+		err = s.checkDescription(tool.Definition)
+	}
+	if err != nil {
+		log.Errorf("Tool provider returned an invalid registration for '%s': %v", name, err)
+		return internalRegisteredTool{}, false
+	}
+	if fingerprint != "" {
+		// Only the latest registration for each name is kept, so the cache grows no
+		// larger than the provider's catalog.
+		s.providedLock.Lock()
+		if s.providedTools == nil {
+			s.providedTools = make(map[string]providedTool)
+		}
+		s.providedTools[name] = providedTool{fingerprint: fingerprint, handlerType: handlerType, tool: tool}
+		s.providedLock.Unlock()
+	}
+	return tool, true
+}
+
+// registrationFingerprint encodes the parts of a registration that buildTool derives the
+// tool from, other than its functions. It returns "" if they cannot be encoded, in which
+// case the tool is not cached.
+func registrationFingerprint(reg ToolRegistration) string {
+	fingerprint, err := json.Marshal(struct {
+		Definition    protocol.Tool
+		Version       string
+		MaxInputBytes int64
+		Cacheable     bool
+		Timeout       time.Duration
+		Descriptions  map[string]string
+		MaxConcurrent int
+		MaxQueued     int
+		Localized     map[string]LocalizedText
+	}{reg.Definition, reg.Version, reg.MaxInputBytes, reg.Cacheable, reg.Timeout, reg.Descriptions, reg.MaxConcurrent, reg.MaxQueued, reg.Localized})
+	if err != nil {
+		return ""
+	}
+	return string(fingerprint)
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// stubProvider serves one tool per name, whose registration can be swapped between calls.
+type stubProvider struct {
+	mu   sync.Mutex
+	regs map[string]ToolRegistration
+}
+
+func (p *stubProvider) List(ctx context.Context) []protocol.Tool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var defs []protocol.Tool
+	for _, reg := range p.regs {
+		defs = append(defs, reg.Definition)
+	}
+	return defs
+}
+
+func (p *stubProvider) Resolve(ctx context.Context, name string) (ToolRegistration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	reg, ok := p.regs[name]
+	return reg, ok
+}
+
+func (p *stubProvider) set(reg ToolRegistration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.regs == nil {
+		p.regs = make(map[string]ToolRegistration)
+	}
+	p.regs[reg.Definition.Name] = reg
+}
+
+func replyWith(text string) func(ctx context.Context, in *echoInput) (string, error) {
+	return func(ctx context.Context, in *echoInput) (string, error) { return text, nil }
+}
+
+func TestProvidedToolsAreBuiltOncePerRegistration(t *testing.T) {
+	tests := []struct {
+		name string
+		// second is the registration the provider returns for the second call.
+		second     ToolRegistration
+		wantBuilds int64
+		wantText   string
+	}{
+		{"same registration", ToolRegistration{Definition: protocol.Tool{Name: "lookup", Description: "Looks up."}, Handler: replyWith("first")}, 1, "first"},
+		{"new closure", ToolRegistration{Definition: protocol.Tool{Name: "lookup", Description: "Looks up."}, Handler: replyWith("second")}, 1, "second"},
+		{"new description", ToolRegistration{Definition: protocol.Tool{Name: "lookup", Description: "Looks something up."}, Handler: replyWith("first")}, 2, "first"},
+		{"new handler type", ToolRegistration{Definition: protocol.Tool{Name: "lookup", Description: "Looks up."}, Handler: func(ctx context.Context, in *orderInput) (string, error) {
+			return "typed", nil
+		}}, 2, "typed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var builds atomic.Int64
+			generator := SchemaGeneratorFunc(func(t reflect.Type) (json.RawMessage, error) {
+				builds.Add(1)
+				return json.RawMessage(`{"type":"object"}`), nil
+			})
+			provider := &stubProvider{}
+			provider.set(ToolRegistration{Definition: protocol.Tool{Name: "lookup", Description: "Looks up."}, Handler: replyWith("first")})
+			s := newTestServer(t, nil, WithToolProvider(provider), WithSchemaGenerator(generator))
+			c := mcptest.NewClient(t, s)
+
+			c.CallTool("lookup", map[string]string{})
+			provider.set(tt.second)
+			result := c.CallTool("lookup", map[string]string{})
+			c.CallTool("lookup", map[string]string{})
+
+			if got := builds.Load(); got != tt.wantBuilds {
+				t.Errorf("schema generated %d times, want %d", got, tt.wantBuilds)
+			}
+			if got := textOf(t, result); got != tt.wantText {
+				t.Errorf("second call answered %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestProvidedToolKeepsConcurrencyLimit(t *testing.T) {
+	provider := &stubProvider{}
+	provider.set(ToolRegistration{
+		Definition:    protocol.Tool{Name: "serial", Description: "Uses a serial device."},
+		Handler:       replyWith("ok"),
+		MaxConcurrent: 1,
+	})
+	s := newTestServer(t, nil, WithToolProvider(provider))
+	first, _ := s.lookupTool(context.Background(), "serial")
+	second, _ := s.lookupTool(context.Background(), "serial")
+	if first.limiter == nil || first.limiter != second.limiter {
+		t.Errorf("limiters %p and %p, want one shared limiter", first.limiter, second.limiter)
+	}
+}
+
+func TestToolProvider(t *testing.T) {
+	provider := &stubProvider{}
+	provider.set(ToolRegistration{Definition: protocol.Tool{Name: "lookup", Description: "Looks up."}, Handler: replyWith("provided lookup")})
+	provider.set(ToolRegistration{Definition: protocol.Tool{Name: "shared", Description: "Provided twin."}, Handler: replyWith("provided shared")})
+	// An invalid registration cannot be called, so it is not listed either.
+	provider.set(ToolRegistration{Definition: protocol.Tool{Name: "broken", Description: "Cannot be built."}, Handler: "not a function"})
+	s := newTestServer(t, []ToolRegistration{{
+		Definition: protocol.Tool{Name: "shared", Description: "Registered twin."},
+		Handler:    replyWith("registered shared"),
+	}}, WithToolProvider(provider))
+	c := mcptest.NewClient(t, s)
+
+	var list protocol.ListToolsResult
+	if err := json.Unmarshal(c.Call("tools/list", nil).Result, &list); err != nil {
+		t.Fatalf("decoding tools/list: %v", err)
+	}
+	listed := map[string]string{}
+	for _, tool := range list.Tools {
+		if _, dup := listed[tool.Name]; dup {
+			t.Errorf("tools/list lists %s twice", tool.Name)
+		}
+		listed[tool.Name] = tool.Description
+	}
+	if want := map[string]string{"lookup": "Looks up.", "shared": "Registered twin."}; !reflect.DeepEqual(listed, want) {
+		t.Errorf("tools/list = %v, want %v", listed, want)
+	}
+
+	tests := []struct {
+		tool     string
+		wantText string
+		wantErr  bool
+	}{
+		{"lookup", "provided lookup", false},
+		{"shared", "registered shared", false},
+		{"missing", "", true},
+		{"broken", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			resp := c.Call("tools/call", map[string]interface{}{"name": tt.tool, "arguments": map[string]string{}})
+			if tt.wantErr {
+				if resp.Error == nil {
+					t.Errorf("calling %s succeeded, want an error", tt.tool)
+				}
+				return
+			}
+			var result protocol.CallToolResult
+			if resp.Error != nil || json.Unmarshal(resp.Result, &result) != nil {
+				t.Fatalf("calling %s: %+v", tt.tool, resp.Error)
+			}
+			if got := textOf(t, &result); got != tt.wantText {
+				t.Errorf("%s answered %q, want %q", tt.tool, got, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go-mcp-sdk/internal/jsonschema"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// queryCallID is the JSON-RPC id of the responses to query string tool calls.
+var queryCallID = protocol.NewRequestID("query")
+
+// WithQueryToolCalls serves GET <path>/tools/{name}, which calls a tool with arguments
+// taken from the query string: GET /mcp/tools/add?a=1&b=2 calls "add" with {"a": 1, "b": 2}.
+// It is meant for webhooks and quick checks with curl, and is not part of the MCP
+// protocol; clients should keep using tools/call on the MCP endpoint.
+//
+// Each value is converted to the type of the input field it names. A parameter given
+// more than once fills a slice field, and a struct or map field takes its value as JSON.
+// The call goes through the same checks as tools/call and is answered with the JSON-RPC
+// response tools/call would get. A call without an Mcp-Session-Id header has no session,
+// so it only reaches tools registered on the server itself; a call with one must name an
+// initialized session, as on the MCP endpoint.
+func WithQueryToolCalls() ServerOption {
+	return func(s *Server) {
+		s.queryToolCalls = true
+	}
+}
+
+func (s *Server) handleQueryToolCall(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, s.path+"/tools/")
+	ctx := contextWithSessionID(r.Context(), r.Header.Get("Mcp-Session-Id"))
+
+	// Values for an unknown tool are passed on as strings; tools/call reports the tool
+	// as not found.
+	var tool *internalRegisteredTool
+	if found, exists := s.lookupTool(ctx, name); exists {
+		tool = &found
+	}
+	args, err := s.queryArguments(r.URL.Query(), tool)
+	if err != nil {
+		s.writeErrorResponse(w, queryCallID, -32602, fmt.Sprintf("Invalid arguments for tool %s", name), err)
+		return
+	}
+	params, err := json.Marshal(protocol.CallToolRequest{Name: name, Arguments: args})
+	if err != nil {
+		s.writeErrorResponse(w, queryCallID, -32602, fmt.Sprintf("Invalid arguments for tool %s", name), err)
+		return
+	}
+
+	ctx, hw := withResponseHeaders(ctx, w)
+	req := &protocol.Request{
+		JSONRPC: "2.0",
+		ID:      queryCallID,
+		Method:  "tools/call",
+		Params:  params,
+	}
+	// A call without a session is answered outside of one; a call naming a session
+	// is checked like any other request in it.
+	if SessionIDFromContext(ctx) == "" {
+		s.dispatchRequest(ctx, hw, req)
+		return
+	}
+	s.handleRequest(ctx, hw, req)
+}
+
+// queryArguments converts query parameters into tool call arguments, typed after the
+// fields of tool's input struct. Parameters that match no field are passed as strings.
+func (s *Server) queryArguments(query url.Values, tool *internalRegisteredTool) (json.RawMessage, error) {
+	fieldTypes := make(map[string]reflect.Type)
+	if tool != nil && tool.inputType.Elem().Kind() == reflect.Struct {
+		naming := s.fieldNaming.schemaNaming()
+		for _, field := range jsonschema.EncodedFields(tool.inputType.Elem()) {
+			if name, _, ok := jsonschema.FieldName(field, naming); ok {
+				fieldTypes[name] = field.Type
+			}
+		}
+		for oldName, alias := range tool.aliases {
+			if fieldType, ok := fieldTypes[alias.name]; ok {
+				fieldTypes[oldName] = fieldType
+			}
+		}
+	}
+
+	args := make(map[string]interface{}, len(query))
+	for key, values := range query {
+		fieldType, ok := fieldTypes[key]
+		if !ok {
+			if len(values) == 1 {
+				args[key] = values[0]
+			} else {
+				args[key] = values
+			}
+			continue
+		}
+		value, err := queryValue(values, fieldType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for argument %s: %w", key, err)
+		}
+		args[key] = value
+	}
+	return json.Marshal(args)
+}
+
+// queryValue converts the values of one query parameter to the JSON form of t.
+func queryValue(values []string, t reflect.Type) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			item, err := queryValue([]string{value}, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+
+	value := values[len(values)-1]
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, t.Bits())
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		// Types that decode from a JSON string, such as time.Time, are given the
+		// value as a string.
+		if json.Valid([]byte(value)) {
+			return json.RawMessage(value), nil
+		}
+	}
+	return value, nil
+}
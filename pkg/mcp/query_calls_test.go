@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+type queryInput struct {
+	Count  int      `json:"count"`
+	Ratio  float64  `json:"ratio"`
+	Dry    bool     `json:"dry"`
+	Tags   []string `json:"tags"`
+	Limit  *int     `json:"limit"`
+	Filter struct {
+		Owner string `json:"owner"`
+	} `json:"filter"`
+	Note string `json:"note"`
+}
+
+func TestQueryToolCalls(t *testing.T) {
+	tests := []struct {
+		name       string
+		disabled   bool
+		method     string
+		target     string
+		wantStatus int
+		wantText   string
+		wantCode   int
+		// session is sent as the Mcp-Session-Id header, if set.
+		session string
+	}{
+		{"coerced values", false, http.MethodGet, "/mcp/tools/query?count=3&ratio=0.5&dry=true&limit=7&note=12", http.StatusOK,
+			"3 0.5 true [] 7  12", 0, ""},
+		{"repeated values fill a slice", false, http.MethodGet, "/mcp/tools/query?tags=a&tags=b", http.StatusOK,
+			"0 0 false [a b] nil  ", 0, ""},
+		{"struct as JSON", false, http.MethodGet, `/mcp/tools/query?filter=` + `%7B%22owner%22%3A%22kim%22%7D`, http.StatusOK,
+			"0 0 false [] nil kim ", 0, ""},
+		{"no arguments", false, http.MethodGet, "/mcp/tools/query", http.StatusOK,
+			"0 0 false [] nil  ", 0, ""},
+		{"invalid number", false, http.MethodGet, "/mcp/tools/query?count=three", http.StatusBadRequest, "", -32602, ""},
+		{"invalid bool", false, http.MethodGet, "/mcp/tools/query?dry=maybe", http.StatusBadRequest, "", -32602, ""},
+		{"unknown tool", false, http.MethodGet, "/mcp/tools/missing?a=1", http.StatusBadRequest, "", -32602, ""},
+		{"POST", false, http.MethodPost, "/mcp/tools/query?count=1", http.StatusMethodNotAllowed, "", 0, ""},
+		{"disabled", true, http.MethodGet, "/mcp/tools/query?count=1", http.StatusNotFound, "", 0, ""},
+		{"unknown session", false, http.MethodGet, "/mcp/tools/query?count=1", http.StatusBadRequest, "", -32600, "bogus"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []ServerOption
+			if !tt.disabled {
+				opts = append(opts, WithQueryToolCalls())
+			}
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "query", Description: "Reports its arguments."},
+				Handler: func(ctx context.Context, in *queryInput) (string, error) {
+					limit := "nil"
+					if in.Limit != nil {
+						limit = fmt.Sprint(*in.Limit)
+					}
+					tags := in.Tags
+					if tags == nil {
+						tags = []string{}
+					}
+					return fmt.Sprintf("%d %v %v %v %s %s %s", in.Count, in.Ratio, in.Dry, tags, limit, in.Filter.Owner, in.Note), nil
+				},
+			}}, opts...)
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.session != "" {
+				req.Header.Set("Mcp-Session-Id", tt.session)
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK && tt.wantStatus != http.StatusBadRequest {
+				return
+			}
+			var resp protocol.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding %s: %v", rec.Body.String(), err)
+			}
+			if tt.wantCode != 0 {
+				if resp.Error == nil || resp.Error.Code != tt.wantCode {
+					t.Errorf("error = %+v, want code %d", resp.Error, tt.wantCode)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("call failed: %+v", resp.Error)
+			}
+			var result protocol.CallToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatal(err)
+			}
+			if got := textOf(t, &result); got != tt.wantText {
+				t.Errorf("result = %q, want %q", got, tt.wantText)
+			}
+			if !strings.Contains(rec.Body.String(), `"id":"query"`) {
+				t.Errorf("response %s does not carry the query call id", rec.Body.String())
+			}
+		})
+	}
+}
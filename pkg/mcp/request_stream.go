@@ -0,0 +1,251 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// requestStream is the http.ResponseWriter for a POST whose client accepts both JSON
+// and an SSE stream. It answers with plain JSON unless a notification related to the
+// request is sent while the request is being handled; the response then switches to
+// text/event-stream, carrying those notifications and finally the response itself as
+// SSE events, as the Streamable HTTP transport allows.
+type requestStream struct {
+	w            http.ResponseWriter
+	flusher      http.Flusher
+	maxEventSize int
+	// coalesce delays flushing notifications by up to this long so that bursts share a
+	// single write; zero flushes every notification at once.
+	coalesce time.Duration
+
+	mu sync.Mutex
+	// committed is set once a plain JSON response has started, after which the
+	// response can no longer switch to a stream.
+	committed bool
+	streaming bool
+	// body collects the response written by handlers while streaming.
+	body bytes.Buffer
+	// flushPending is set while a coalesced flush is scheduled; finished once the
+	// final event is written, after which a late flush must not touch the writer.
+	flushPending bool
+	finished     bool
+	// flushTimer runs the pending coalesced flush; finish stops it.
+	flushTimer *time.Timer
+	// direct is set while a result written piece by piece goes straight to the
+	// connection as the data of the final event, instead of being collected in body.
+	direct bool
+}
+
+// acceptsEventStream reports whether the request's Accept header allows an SSE response.
+func acceptsEventStream(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err == nil && mediaType == "text/event-stream" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newRequestStream wraps w, or returns nil if w cannot be flushed and so cannot stream.
+func newRequestStream(w http.ResponseWriter, maxEventSize int, coalesce time.Duration) *requestStream {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil
+	}
+	return &requestStream{w: w, flusher: flusher, maxEventSize: maxEventSize, coalesce: coalesce}
+}
+
+func (rs *requestStream) Header() http.Header {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.streaming {
+		// Headers set for the final response cannot be sent once the stream has begun.
+		return http.Header{}
+	}
+	return rs.w.Header()
+}
+
+func (rs *requestStream) WriteHeader(statusCode int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.streaming {
+		// The stream has already been answered with 200; errors travel in the final event.
+		return
+	}
+	rs.committed = true
+	rs.w.WriteHeader(statusCode)
+}
+
+func (rs *requestStream) Write(p []byte) (int, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.direct {
+		return rs.w.Write(p)
+	}
+	if rs.streaming {
+		return rs.body.Write(p)
+	}
+	rs.committed = true
+	return rs.w.Write(p)
+}
+
+// startResult prepares for a result written piece by piece. If the response is already
+// a stream, it opens the final event so that the result is written through to the
+// connection as its data, and reports true; the data must not contain newlines other
+// than a trailing one. It reports false for a plain JSON response, whose writes already
+// pass through, and for a stream with a maximum event size, whose response must be
+// collected to be measured.
+func (rs *requestStream) startResult() bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if !rs.streaming || rs.maxEventSize > 0 || rs.body.Len() > 0 {
+		return false
+	}
+	if _, err := rs.w.Write([]byte("event: message\ndata: ")); err != nil {
+		log.Errorf("Error writing response to request stream: %v", err)
+	}
+	rs.direct = true
+	return true
+}
+
+// start switches the response to an SSE stream. It reports false if a JSON response
+// has already begun.
+func (rs *requestStream) start() bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.startLocked()
+}
+
+func (rs *requestStream) startLocked() bool {
+	if rs.streaming {
+		return true
+	}
+	if rs.committed {
+		return false
+	}
+	header := rs.w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Del("Content-Length")
+	rs.w.WriteHeader(http.StatusOK)
+	rs.streaming = true
+	return true
+}
+
+// send writes a notification as an SSE event, starting the stream if needed.
+// It reports false if the response has already been committed as plain JSON.
+func (rs *requestStream) send(notif *protocol.Notification) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.direct || !rs.startLocked() {
+		// Once the final event has begun, notifications go to the session's stream.
+		return false
+	}
+	if err := writeSSEEvent(rs.w, 0, notif, rs.maxEventSize); err != nil {
+		log.Errorf("Error writing notification to request stream: %v", err)
+		return true
+	}
+	if rs.coalesce <= 0 {
+		rs.flusher.Flush()
+	} else if !rs.flushPending {
+		rs.flushPending = true
+		rs.flushTimer = time.AfterFunc(rs.coalesce, rs.flushCoalesced)
+	}
+	return true
+}
+
+// flushCoalesced flushes notifications held back by coalescing, unless the stream has
+// finished in the meantime.
+func (rs *requestStream) flushCoalesced() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.flushPending = false
+	if !rs.finished {
+		rs.flusher.Flush()
+	}
+}
+
+// finish emits the response written by the handler as the stream's last event.
+// It does nothing if the request was answered with plain JSON. Once it returns, the
+// underlying writer is no longer touched, even by a coalesced flush still pending.
+func (rs *requestStream) finish() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.finished = true
+	if rs.flushTimer != nil {
+		rs.flushTimer.Stop()
+		rs.flushTimer = nil
+	}
+	if !rs.streaming {
+		return
+	}
+	if rs.direct {
+		// The result's data has already been written; the blank line ends the event.
+		if _, err := rs.w.Write([]byte("\n\n")); err != nil {
+			log.Errorf("Error writing response to request stream: %v", err)
+			return
+		}
+		rs.flusher.Flush()
+		return
+	}
+	data := bytes.TrimSpace(rs.body.Bytes())
+	if len(data) == 0 {
+		rs.flusher.Flush()
+		return
+	}
+	if err := writeSSEEvent(rs.w, 0, json.RawMessage(data), rs.maxEventSize); err != nil {
+		log.Errorf("Error writing response to request stream: %v", err)
+		return
+	}
+	rs.flusher.Flush()
+}
+
+// abort gives up on the response after the handler panicked, including to abort a
+// result written piece by piece. It reports whether the stream had begun, in which case
+// the writer is no longer touched: the last event may hold part of a result and is left
+// unterminated, so that the client cannot take it for a complete response, and no error
+// response can be mixed into the stream. A response that has not begun is left to be
+// answered as usual.
+func (rs *requestStream) abort() bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if !rs.streaming {
+		return false
+	}
+	rs.finished = true
+	if rs.flushTimer != nil {
+		rs.flushTimer.Stop()
+		rs.flushTimer = nil
+	}
+	return true
+}
+
+// sendNotification delivers a notification that relates to the request carried by ctx.
+// It goes out on the request's own stream when the client accepts one, and otherwise
+// is queued for the session's GET stream.
+func (s *Server) sendNotification(ctx context.Context, notif *protocol.Notification) {
+	if rs, ok := ctx.Value(requestStreamKey).(*requestStream); ok && rs.send(notif) {
+		return
+	}
+	sessionID := SessionIDFromContext(ctx)
+	session := s.lookupSession(sessionID)
+	if session == nil {
+		return
+	}
+	if !session.enqueue(notif, s.overflowPolicy) {
+		log.Warnf("Notification queue full for session %s while sending %s", sessionID, notif.Method)
+	}
+}
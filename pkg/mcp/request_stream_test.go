@@ -0,0 +1,263 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// closingRecorder fails the test if it is written to or flushed after close.
+type closingRecorder struct {
+	*httptest.ResponseRecorder
+	t      *testing.T
+	mu     sync.Mutex
+	closed atomic.Bool
+}
+
+func (c *closingRecorder) Write(p []byte) (int, error) {
+	if c.closed.Load() {
+		c.t.Error("Write after the handler returned")
+		return 0, errors.New("closed")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ResponseRecorder.Write(p)
+}
+
+func (c *closingRecorder) Flush() {
+	if c.closed.Load() {
+		c.t.Error("Flush after the handler returned")
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ResponseRecorder.Flush()
+}
+
+func TestRequestStreamFinishStopsCoalescedFlush(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(ctx context.Context, in echoInput) (string, error)
+	}{
+		{"handler returns", func(ctx context.Context, in echoInput) (string, error) {
+			ReportProgress(ctx, 1, 2, "half way")
+			return "done", nil
+		}},
+		{"handler panics", func(ctx context.Context, in echoInput) (string, error) {
+			ReportProgress(ctx, 1, 2, "half way")
+			panic("boom")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "work", Description: "Reports progress."},
+				Handler:    tt.handler,
+			}}, WithNotificationCoalescing(20*time.Millisecond))
+			c := mcptest.NewClient(t, s)
+
+			body := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"work","arguments":{},"_meta":{"progressToken":"p"}}}`
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			req.Header.Set("Mcp-Session-Id", c.SessionID())
+			rec := &closingRecorder{ResponseRecorder: httptest.NewRecorder(), t: t}
+			func() {
+				defer func() { recover() }()
+				s.ServeHTTP(rec, req)
+			}()
+			rec.closed.Store(true)
+
+			// Give a flush that was not stopped time to fire.
+			time.Sleep(50 * time.Millisecond)
+			if !strings.Contains(rec.Body.String(), "notifications/progress") {
+				t.Errorf("progress notification missing from stream: %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+// countingFlusher counts the flushes of a response.
+type countingFlusher struct {
+	*httptest.ResponseRecorder
+	mu      sync.Mutex
+	flushes int
+}
+
+func (c *countingFlusher) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ResponseRecorder.Write(p)
+}
+
+func (c *countingFlusher) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushes++
+}
+
+func (c *countingFlusher) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushes
+}
+
+func TestRequestStreamCoalescing(t *testing.T) {
+	tests := []struct {
+		name        string
+		window      time.Duration
+		wantFlushes int
+	}{
+		{"disabled", 0, 5},
+		{"window", 30 * time.Millisecond, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &countingFlusher{ResponseRecorder: httptest.NewRecorder()}
+			rs := newRequestStream(w, 0, tt.window)
+			for i := 0; i < 5; i++ {
+				rs.send(&protocol.Notification{JSONRPC: "2.0", Method: fmt.Sprintf("notifications/n%d", i)})
+			}
+			// The burst is flushed once the window has passed, without waiting for finish.
+			time.Sleep(tt.window + 50*time.Millisecond)
+			if got := w.count(); got != tt.wantFlushes {
+				t.Errorf("flushes = %d, want %d", got, tt.wantFlushes)
+			}
+			rs.finish()
+
+			body := w.Body.String()
+			last := -1
+			for i := 0; i < 5; i++ {
+				at := strings.Index(body, fmt.Sprintf("notifications/n%d", i))
+				if at < last {
+					t.Fatalf("notification %d out of order in %q", i, body)
+				}
+				last = at
+			}
+		})
+	}
+}
+
+func BenchmarkNotificationCoalescing(b *testing.B) {
+	notif := &protocol.Notification{JSONRPC: "2.0", Method: "notifications/progress", Params: []byte(`{"progressToken":"p","progress":1}`)}
+	for _, window := range []time.Duration{0, time.Millisecond} {
+		b.Run(fmt.Sprintf("window=%v", window), func(b *testing.B) {
+			w := &countingFlusher{ResponseRecorder: httptest.NewRecorder()}
+			rs := newRequestStream(w, 0, window)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rs.send(notif)
+				if i%1024 == 0 {
+					w.Body.Reset()
+				}
+			}
+			rs.finish()
+			b.ReportMetric(float64(w.count())/float64(b.N), "flushes/op")
+		})
+	}
+}
+
+// cuttingRecorder disconnects the client, by cancelling the request's context, once the
+// response written so far contains cut.
+type cuttingRecorder struct {
+	*httptest.ResponseRecorder
+	cut        string
+	disconnect func()
+}
+
+func (c *cuttingRecorder) Write(p []byte) (int, error) {
+	n, err := c.ResponseRecorder.Write(p)
+	if strings.Contains(c.Body.String(), c.cut) {
+		c.disconnect()
+	}
+	return n, err
+}
+
+func TestRequestStreamAbort(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler interface{}
+		// cut disconnects the client once written; empty keeps it connected.
+		cut string
+	}{
+		{"client gone mid-result", func(ctx context.Context, in echoInput) (string, error) {
+			ReportProgress(ctx, 1, 2, "half way")
+			return strings.Repeat("line of output\n", 4096), nil
+		}, `"content":[`},
+		{"client gone mid-reader", func(ctx context.Context, in echoInput) (io.Reader, error) {
+			ReportProgress(ctx, 1, 2, "half way")
+			return endlessReader{new(atomic.Int64)}, nil
+		}, `"text":"`},
+		{"handler panics", func(ctx context.Context, in echoInput) (string, error) {
+			ReportProgress(ctx, 1, 2, "half way")
+			panic("boom")
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "work", Description: "Reports progress."},
+				Handler:    tt.handler,
+			}}, WithStreamedResults(1024))
+			c := mcptest.NewClient(t, s)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			body := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"work","arguments":{},"_meta":{"progressToken":"p"}}}`
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)).WithContext(ctx)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			req.Header.Set("Mcp-Session-Id", c.SessionID())
+			rec := &cuttingRecorder{ResponseRecorder: httptest.NewRecorder(), cut: "\x00", disconnect: cancel}
+			if tt.cut != "" {
+				rec.cut = tt.cut
+			}
+
+			aborted := func() (aborted bool) {
+				defer func() {
+					if r := recover(); r != nil {
+						if r != http.ErrAbortHandler {
+							panic(r)
+						}
+						aborted = true
+					}
+				}()
+				s.ServeHTTP(rec, req)
+				return false
+			}()
+			if !aborted {
+				t.Error("response was not aborted")
+			}
+
+			stream := rec.Body.String()
+			if !strings.Contains(stream, "notifications/progress") {
+				t.Fatalf("progress notification missing from stream: %.200q", stream)
+			}
+			// Every event the client sees as complete must carry a whole message.
+			events := strings.Split(stream, "\n\n")
+			for _, event := range events[:len(events)-1] {
+				for _, line := range strings.Split(event, "\n") {
+					if data, ok := strings.CutPrefix(line, "data: "); ok && !json.Valid([]byte(data)) {
+						t.Errorf("complete event carries truncated data: %.80q", data)
+					}
+				}
+			}
+			if rest := events[len(events)-1]; strings.Contains(rest, `"error"`) {
+				t.Errorf("an error response was written onto the stream: %.200q", rest)
+			}
+		})
+	}
+}
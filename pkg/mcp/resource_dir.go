@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"mime"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// extraMIMETypes covers common text formats that the system MIME tables often lack.
+var extraMIMETypes = map[string]string{
+	".md":   "text/markdown",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+}
+
+// RegisterResourceDir registers every regular file in fsys as a resource, so that a
+// directory, or files embedded with go:embed, can be served without writing handlers.
+// A file's URI is prefix followed by its slash-separated path in fsys, such as
+// "file:///docs/guides/intro.md" for prefix "file:///docs/"; a "/" is added after
+// prefix if it does not end with one. Its name is its path and its MIME type is
+// inferred from its extension.
+//
+// Files are read with fs.ReadFile each time they are requested, so changes to their
+// contents are picked up; files added or removed later are not. Contents that are valid
+// UTF-8 and of a textual type are sent as text; anything else is sent as a base64 blob.
+// Files whose extension gives no MIME type have one sniffed from their contents.
+func (s *Server) RegisterResourceDir(prefix string, fsys fs.FS) error {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var registrations []ResourceRegistration
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		registrations = append(registrations, ResourceRegistration{
+			Definition: protocol.Resource{
+				URI:      prefix + name,
+				Name:     name,
+				MIMEType: mimeTypeOf(name),
+			},
+			Handler: fileResourceHandler(fsys, name),
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk resource directory: %w", err)
+	}
+	return s.RegisterResources(registrations)
+}
+
+// fileResourceHandler serves the file at name in fsys.
+func fileResourceHandler(fsys fs.FS, name string) ResourceHandler {
+	return func(ctx context.Context, uri string) ([]protocol.ResourceContents, error) {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		mimeType := mimeTypeOf(name)
+		contents := protocol.ResourceContents{URI: uri, MIMEType: mimeType}
+		if (mimeType == "" || isTextual(mimeType)) && utf8.Valid(data) {
+			contents.Text = string(data)
+		} else {
+			contents.Blob = base64.StdEncoding.EncodeToString(data)
+		}
+		return []protocol.ResourceContents{contents}, nil
+	}
+}
+
+// mimeTypeOf infers a file's media type from its extension, without parameters such as
+// the charset. It returns "" if the extension is unknown.
+func mimeTypeOf(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if mimeType, ok := extraMIMETypes[ext]; ok {
+		return mimeType
+	}
+	mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(ext))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// isTextual reports whether a media type describes text.
+func isTextual(mimeType string) bool {
+	switch {
+	case strings.HasPrefix(mimeType, "text/"),
+		strings.HasSuffix(mimeType, "+json"),
+		strings.HasSuffix(mimeType, "+xml"):
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/xml", "application/yaml", "application/javascript":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// pngHeader is the start of a PNG file, enough for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// readResource reads uri through c and returns its only contents.
+func readResource(t *testing.T, c *mcptest.Client, uri string) protocol.ResourceContents {
+	t.Helper()
+	resp := c.Call("resources/read", protocol.ReadResourceRequest{URI: uri})
+	if resp.Error != nil {
+		t.Fatalf("resources/read %s: %+v", uri, resp.Error)
+	}
+	var result protocol.ReadResourceResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("decoding resources/read: %v", err)
+	}
+	if len(result.Contents) != 1 {
+		t.Fatalf("resources/read %s returned %d contents, want 1", uri, len(result.Contents))
+	}
+	return result.Contents[0]
+}
+
+func TestRegisterResourceDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"intro.md":          {Data: []byte("# Intro\n")},
+		"guides/setup.yaml": {Data: []byte("steps: []\n")},
+		"data/config.json":  {Data: []byte(`{"debug":true}`)},
+		"img/logo.png":      {Data: pngHeader},
+		"NOTES":             {Data: []byte("plain notes\n")},
+		"empty":             {Mode: fs.ModeDir},
+	}
+	tests := []struct {
+		path     string
+		wantMIME string
+		wantText string
+		wantBlob []byte
+	}{
+		{"intro.md", "text/markdown", "# Intro\n", nil},
+		{"guides/setup.yaml", "application/yaml", "steps: []\n", nil},
+		{"data/config.json", "application/json", `{"debug":true}`, nil},
+		{"img/logo.png", "image/png", "", pngHeader},
+		{"NOTES", "text/plain", "plain notes\n", nil},
+	}
+	s := newTestServer(t, nil)
+	if err := s.RegisterResourceDir("file:///docs", fsys); err != nil {
+		t.Fatalf("RegisterResourceDir: %v", err)
+	}
+	c := mcptest.NewClient(t, s)
+
+	var list protocol.ListResourcesResult
+	if err := json.Unmarshal(c.Call("resources/list", nil).Result, &list); err != nil {
+		t.Fatalf("decoding resources/list: %v", err)
+	}
+	var listed []string
+	for _, resource := range list.Resources {
+		listed = append(listed, resource.URI)
+	}
+	want := []string{"file:///docs/NOTES", "file:///docs/data/config.json", "file:///docs/guides/setup.yaml", "file:///docs/img/logo.png", "file:///docs/intro.md"}
+	if !reflect.DeepEqual(listed, want) {
+		t.Errorf("resources/list = %v, want %v", listed, want)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			contents := readResource(t, c, "file:///docs/"+tt.path)
+			if contents.MIMEType != tt.wantMIME {
+				t.Errorf("mimeType = %q, want %q", contents.MIMEType, tt.wantMIME)
+			}
+			if contents.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", contents.Text, tt.wantText)
+			}
+			if want := base64.StdEncoding.EncodeToString(tt.wantBlob); contents.Blob != want {
+				t.Errorf("blob = %q, want %q", contents.Blob, want)
+			}
+		})
+	}
+}
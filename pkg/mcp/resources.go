@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ResourceHandler returns the current contents of a resource.
+type ResourceHandler func(ctx context.Context, uri string) ([]protocol.ResourceContents, error)
+
+// ResourceRegistration is a struct to define and register resources.
+type ResourceRegistration struct {
+	Definition protocol.Resource
+	Handler    ResourceHandler
+	// InlineUpdates includes the resource's new contents in the update notifications
+	// sent to subscribers, sparing them a "resources/read". It suits small resources;
+	// by default subscribers are only told that the resource changed.
+	InlineUpdates bool
+}
+
+// RegisterResources registers a slice of resources, making them available to clients.
+// It stops at the first resource that fails to register; the resources before it stay
+// registered, and clients are told about them.
+func (s *Server) RegisterResources(registrations []ResourceRegistration) error {
+	registered := 0
+	defer func() {
+		if registered > 0 {
+			s.notifyListChanged(listResources)
+		}
+	}()
+	for _, reg := range registrations {
+		if err := s.registerSingleResource(reg); err != nil {
+			return fmt.Errorf("failed to register resource '%s': %w", reg.Definition.URI, err)
+		}
+		registered++
+	}
+	return nil
+}
+
+// UnregisterResources removes the resources with the given URIs.
+// URIs that are not registered are ignored.
+func (s *Server) UnregisterResources(uris ...string) {
+	removed := 0
+	s.resourceLock.Lock()
+	for _, uri := range uris {
+		if _, exists := s.resources[uri]; exists {
+			delete(s.resources, uri)
+			removed++
+			log.Infof("Unregistered resource: %s", uri)
+		}
+	}
+	s.resourceLock.Unlock()
+
+	if removed > 0 {
+		s.notifyListChanged(listResources)
+	}
+}
+
+// registerSingleResource is the internal helper that processes one resource registration.
+func (s *Server) registerSingleResource(reg ResourceRegistration) error {
+	if reg.Definition.URI == "" {
+		return fmt.Errorf("resource definition must include a URI")
+	}
+	if reg.Definition.Name == "" {
+		return fmt.Errorf("resource definition must include a name")
+	}
+	if reg.Handler == nil {
+		return fmt.Errorf("resource handler must not be nil")
+	}
+
+	s.resourceLock.Lock()
+	defer s.resourceLock.Unlock()
+
+	if _, exists := s.resources[reg.Definition.URI]; exists {
+		return fmt.Errorf("resource with URI '%s' already registered", reg.Definition.URI)
+	}
+	s.resources[reg.Definition.URI] = reg
+
+	log.Infof("Registered resource: %s", reg.Definition.URI)
+	return nil
+}
+
+// typeResourceContents fills in the MIME type of contents that lack one: with declared,
+// the type in the resource's definition, if set, and otherwise by sniffing the data with
+// http.DetectContentType. Sniffed contents are then sent as text if they are textual and
+// valid UTF-8, or as a base64 blob if not. Blobs that are not valid base64 are left as-is.
+// The handler's slice is not modified, as it may be shared.
+func typeResourceContents(contents []protocol.ResourceContents, declared string) []protocol.ResourceContents {
+	contents = append([]protocol.ResourceContents(nil), contents...)
+	for i := range contents {
+		c := &contents[i]
+		if c.MIMEType != "" {
+			continue
+		}
+		if declared != "" {
+			c.MIMEType = declared
+			continue
+		}
+
+		data := []byte(c.Text)
+		if c.Blob != "" {
+			decoded, err := base64.StdEncoding.DecodeString(c.Blob)
+			if err != nil {
+				continue
+			}
+			data = decoded
+		}
+		c.MIMEType = sniffContentType(data)
+		if isTextual(c.MIMEType) && utf8.Valid(data) {
+			c.Text, c.Blob = string(data), ""
+		} else {
+			c.Text, c.Blob = "", base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	return contents
+}
+
+// sniffContentType guesses the media type of data, without parameters. JSON, which
+// http.DetectContentType reports as plain text, is recognised separately.
+func sniffContentType(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return "application/json"
+	}
+	mediaType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	return mediaType
+}
+
+// sortResources orders resources by name, and resources sharing a name by URI, so
+// that lists are stable across calls.
+func sortResources(resources []protocol.Resource) {
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Name != resources[j].Name {
+			return resources[i].Name < resources[j].Name
+		}
+		return resources[i].URI < resources[j].URI
+	})
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func noResource(ctx context.Context, uri string) ([]protocol.ResourceContents, error) {
+	return nil, nil
+}
+
+func TestListResourcesIsSorted(t *testing.T) {
+	s := newTestServer(t, nil)
+	resources := []protocol.Resource{
+		{URI: "file:///z", Name: "zeta"},
+		{URI: "file:///b", Name: "alpha"},
+		{URI: "file:///m", Name: "mu"},
+		{URI: "file:///a", Name: "alpha"},
+		{URI: "file:///o", Name: "omega"},
+	}
+	var regs []ResourceRegistration
+	for _, resource := range resources {
+		regs = append(regs, ResourceRegistration{Definition: resource, Handler: noResource})
+	}
+	if err := s.RegisterResources(regs); err != nil {
+		t.Fatalf("RegisterResources: %v", err)
+	}
+	c := mcptest.NewClient(t, s)
+
+	want := []string{"file:///a", "file:///b", "file:///m", "file:///o", "file:///z"}
+	for i := 0; i < 5; i++ {
+		var result protocol.ListResourcesResult
+		if err := json.Unmarshal(c.Call("resources/list", nil).Result, &result); err != nil {
+			t.Fatalf("decoding resources/list: %v", err)
+		}
+		var got []string
+		for _, resource := range result.Resources {
+			got = append(got, resource.URI)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("resources/list = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestResourceContentSniffing(t *testing.T) {
+	blob := func(data []byte) string { return base64.StdEncoding.EncodeToString(data) }
+	tests := []struct {
+		name     string
+		declared string
+		contents protocol.ResourceContents
+		want     protocol.ResourceContents
+	}{
+		{"text", "", protocol.ResourceContents{Text: "hello, world\n"},
+			protocol.ResourceContents{MIMEType: "text/plain", Text: "hello, world\n"}},
+		{"PNG blob", "", protocol.ResourceContents{Blob: blob(pngHeader)},
+			protocol.ResourceContents{MIMEType: "image/png", Blob: blob(pngHeader)}},
+		{"PNG sent as text", "", protocol.ResourceContents{Text: string(pngHeader)},
+			protocol.ResourceContents{MIMEType: "image/png", Blob: blob(pngHeader)}},
+		{"JSON text", "", protocol.ResourceContents{Text: `{"ok":true}`},
+			protocol.ResourceContents{MIMEType: "application/json", Text: `{"ok":true}`}},
+		{"JSON blob", "", protocol.ResourceContents{Blob: blob([]byte(` [1, 2] `))},
+			protocol.ResourceContents{MIMEType: "application/json", Text: ` [1, 2] `}},
+		{"declared by the definition", "text/csv", protocol.ResourceContents{Text: "a,b\n"},
+			protocol.ResourceContents{MIMEType: "text/csv", Text: "a,b\n"}},
+		{"declared by the contents", "", protocol.ResourceContents{MIMEType: "text/x-go", Text: "package main\n"},
+			protocol.ResourceContents{MIMEType: "text/x-go", Text: "package main\n"}},
+		{"invalid base64", "", protocol.ResourceContents{Blob: "not base64!"},
+			protocol.ResourceContents{Blob: "not base64!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const uri = "mem:///item"
+			s := newTestServer(t, nil)
+			err := s.RegisterResources([]ResourceRegistration{{
+				Definition: protocol.Resource{URI: uri, Name: "item", MIMEType: tt.declared},
+				Handler: func(ctx context.Context, uri string) ([]protocol.ResourceContents, error) {
+					contents := tt.contents
+					contents.URI = uri
+					return []protocol.ResourceContents{contents}, nil
+				},
+			}})
+			if err != nil {
+				t.Fatalf("RegisterResources: %v", err)
+			}
+			want := tt.want
+			want.URI = uri
+			if got := readResource(t, mcptest.NewClient(t, s), uri); !reflect.DeepEqual(got, want) {
+				t.Errorf("contents = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
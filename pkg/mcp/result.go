@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// ResultBuilder assembles a protocol.CallToolResult one content block at a time.
+//
+//	result := mcp.NewResult().AddText("Rendered chart:").AddImage(png, "image/png").Build()
+type ResultBuilder struct {
+	result protocol.CallToolResult
+}
+
+// NewResult starts an empty tool result.
+func NewResult() *ResultBuilder {
+	return &ResultBuilder{result: protocol.CallToolResult{Content: []protocol.ContentBlock{}}}
+}
+
+// AddText appends a text content block.
+func (b *ResultBuilder) AddText(text string) *ResultBuilder {
+	return b.AddBlock(protocol.ContentBlock{Type: "text", Text: text})
+}
+
+// AddImage appends an image content block, base64-encoding data.
+func (b *ResultBuilder) AddImage(data []byte, mimeType string) *ResultBuilder {
+	return b.AddBlock(protocol.ContentBlock{
+		Type:     "image",
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MIMEType: mimeType,
+	})
+}
+
+// AddResource appends an embedded resource content block.
+func (b *ResultBuilder) AddResource(contents protocol.ResourceContents) *ResultBuilder {
+	return b.AddBlock(protocol.ContentBlock{Type: "resource", Resource: &contents})
+}
+
+// AddBlock appends an arbitrary content block, such as one of a custom type.
+func (b *ResultBuilder) AddBlock(block protocol.ContentBlock) *ResultBuilder {
+	b.result.Content = append(b.result.Content, block)
+	return b
+}
+
+// AddError marks the result as a tool error and appends err's message as a text block,
+// followed by an "error" block carrying err itself for clients that render errors apart.
+func (b *ResultBuilder) AddError(err *protocol.ToolError) *ResultBuilder {
+	b.result.IsError = true
+	return b.AddText(err.Message).AddBlock(protocol.ContentBlock{Type: "error", Error: err})
+}
+
+// SetStructuredContent sets the machine-readable form of the result.
+func (b *ResultBuilder) SetStructuredContent(structured interface{}) *ResultBuilder {
+	b.result.StructuredContent = structured
+	return b
+}
+
+// SetError marks whether the result reports a tool-level error.
+func (b *ResultBuilder) SetError(isError bool) *ResultBuilder {
+	b.result.IsError = isError
+	return b
+}
+
+// Build returns the assembled result. The builder may continue to be used afterwards
+// without affecting results it has already built.
+func (b *ResultBuilder) Build() *protocol.CallToolResult {
+	result := b.result
+	result.Content = append([]protocol.ContentBlock(nil), b.result.Content...)
+	if result.Content == nil {
+		result.Content = []protocol.ContentBlock{}
+	}
+	return &result
+}
+
+// WithStructuredErrors adds an "error" content block after the plain-text message of
+// every tool error result, so clients can tell errors apart from normal output. A handler
+// error that is, or wraps, a *protocol.ToolError is sent with its code and details;
+// any other error is sent with just its message. Clients that do not understand the
+// block still see the text.
+func WithStructuredErrors() ServerOption {
+	return func(s *Server) {
+		s.structuredErrors = true
+	}
+}
+
+// toolErrorBlock returns the "error" content block describing err.
+func toolErrorBlock(err error) protocol.ContentBlock {
+	var toolErr *protocol.ToolError
+	if !errors.As(err, &toolErr) {
+		toolErr = &protocol.ToolError{Message: err.Error()}
+	}
+	return protocol.ContentBlock{Type: "error", Error: toolErr}
+}
+
+// WithEmptyResultText sets the text returned by a successful tool whose handler returns
+// only an error. By default such results have an empty content array, leaving clients to
+// decide how to show an action that produced no output; earlier versions of this package
+// sent "Operation completed successfully.", which this option can restore.
+func WithEmptyResultText(text string) ServerOption {
+	return func(s *Server) {
+		s.emptyResultText = text
+	}
+}
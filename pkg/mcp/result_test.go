@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestStructuredErrors(t *testing.T) {
+	notFound := &protocol.ToolError{Code: "not_found", Message: "no such order", Details: map[string]interface{}{"id": "42"}}
+	tests := []struct {
+		name string
+		opts []ServerOption
+		err  error
+		want []protocol.ContentBlock
+	}{
+		{"plain text by default", nil, notFound,
+			[]protocol.ContentBlock{{Type: "text", Text: "no such order"}}},
+		{"tool error", []ServerOption{WithStructuredErrors()}, notFound,
+			[]protocol.ContentBlock{{Type: "text", Text: "no such order"}, {Type: "error", Error: notFound}}},
+		{"wrapped tool error", []ServerOption{WithStructuredErrors()}, fmt.Errorf("lookup: %w", notFound),
+			[]protocol.ContentBlock{{Type: "text", Text: "lookup: no such order"}, {Type: "error", Error: notFound}}},
+		{"other error", []ServerOption{WithStructuredErrors()}, errors.New("database down"),
+			[]protocol.ContentBlock{{Type: "text", Text: "database down"}, {Type: "error", Error: &protocol.ToolError{Message: "database down"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "order", Description: "Finds an order."},
+				Handler:    func(ctx context.Context, in *echoInput) (string, error) { return "", tt.err },
+			}}, tt.opts...)
+			result := mcptest.CallTool(t, s, "order", map[string]string{"value": "42"})
+			if !result.IsError {
+				t.Error("IsError = false, want true")
+			}
+			if !reflect.DeepEqual(result.Content, tt.want) {
+				t.Errorf("content = %+v, want %+v", result.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultBuilderAddError(t *testing.T) {
+	toolErr := &protocol.ToolError{Code: "quota", Message: "quota exceeded"}
+	result := NewResult().AddText("partial output").AddError(toolErr).Build()
+	want := []protocol.ContentBlock{
+		{Type: "text", Text: "partial output"},
+		{Type: "text", Text: "quota exceeded"},
+		{Type: "error", Error: toolErr},
+	}
+	if !result.IsError || !reflect.DeepEqual(result.Content, want) {
+		t.Errorf("result = %+v, want an error result with content %+v", result, want)
+	}
+}
+
+func TestHandlerReturnsCallToolResult(t *testing.T) {
+	partial := NewResult().AddText("imported 3 of 5 rows").Build()
+	tests := []struct {
+		name        string
+		result      *protocol.CallToolResult
+		err         error
+		wantContent []protocol.ContentBlock
+		wantError   bool
+	}{
+		{"success", NewResult().AddText("imported 5 rows").Build(), nil,
+			[]protocol.ContentBlock{{Type: "text", Text: "imported 5 rows"}}, false},
+		{"partial output and an error", partial, errors.New("row 4 is malformed"),
+			[]protocol.ContentBlock{{Type: "text", Text: "imported 3 of 5 rows"}, {Type: "text", Text: "row 4 is malformed"}}, true},
+		{"error result built by the handler", NewResult().AddText("nothing to import").SetError(true).Build(), nil,
+			[]protocol.ContentBlock{{Type: "text", Text: "nothing to import"}}, true},
+		{"nil result", nil, nil, []protocol.ContentBlock{}, false},
+		{"nil result and an error", nil, errors.New("source unavailable"),
+			[]protocol.ContentBlock{{Type: "text", Text: "source unavailable"}}, true},
+	}
+	for _, tt := range tests {
+		for _, dynamic := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s dynamic=%v", tt.name, dynamic), func(t *testing.T) {
+				def := protocol.Tool{Name: "import", Description: "Imports rows."}
+				s := newTestServer(t, []ToolRegistration{{
+					Definition: def,
+					Handler: func(ctx context.Context, in *echoInput) (*protocol.CallToolResult, error) {
+						return tt.result, tt.err
+					},
+				}})
+				if dynamic {
+					// Dynamic handlers return interface{}, so the result is told apart by its value.
+					s = newTestServer(t, nil)
+					if err := s.RegisterDynamicTools([]protocol.Tool{def}, func(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
+						return tt.result, tt.err
+					}); err != nil {
+						t.Fatalf("RegisterDynamicTools: %v", err)
+					}
+				}
+				c := mcptest.NewClient(t, s)
+				// A second call shows the handler's result is not modified in place.
+				for call := 0; call < 2; call++ {
+					result := c.CallTool("import", map[string]string{})
+					if result.IsError != tt.wantError {
+						t.Errorf("IsError = %v, want %v", result.IsError, tt.wantError)
+					}
+					if !reflect.DeepEqual(result.Content, tt.wantContent) {
+						t.Errorf("content = %+v, want %+v", result.Content, tt.wantContent)
+					}
+				}
+			})
+		}
+	}
+	if len(partial.Content) != 1 || partial.IsError {
+		t.Errorf("handler's result was modified: %+v", partial)
+	}
+}
@@ -1,9 +1,13 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"runtime/debug"
 
 	"go-mcp-sdk/pkg/protocol"
 
@@ -12,8 +16,11 @@ import (
 
 func (s *Server) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
-		log.Println("Received GET request for SSE stream (not yet implemented). Returning OK.")
-		w.WriteHeader(http.StatusOK)
+		s.handleSSEStream(w, r)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		s.handleSessionDelete(w, r)
 		return
 	}
 	if r.Method != http.MethodPost {
@@ -29,48 +36,151 @@ func (s *Server) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	var rawMessage map[string]json.RawMessage
-	if err := json.Unmarshal(body, &rawMessage); err != nil {
-		writeErrorResponse(w, protocol.RequestID{}, -32700, "Parse error: Invalid JSON", err)
+	if err := s.codec.Unmarshal(body, &rawMessage); err != nil {
+		s.writeErrorResponse(w, protocol.RequestID{}, -32700, "Parse error: Invalid JSON", err)
+		return
+	}
+
+	ctx := contextWithSessionID(r.Context(), r.Header.Get("Mcp-Session-Id"))
+	ctx = contextWithAcceptLanguage(ctx, r.Header.Get("Accept-Language"))
+
+	_, hasID := rawMessage["id"]
+	_, hasMethod := rawMessage["method"]
+	if hasID && !hasMethod {
+		var resp protocol.Response
+		if err := s.codec.Unmarshal(body, &resp); err != nil {
+			s.writeErrorResponse(w, protocol.RequestID{}, -32700, "Parse error: Invalid Response structure", err)
+			return
+		}
+		s.handleClientResponse(&resp)
+		w.WriteHeader(http.StatusAccepted)
 		return
 	}
 
-	if _, ok := rawMessage["id"]; ok {
+	if hasID {
 		var req protocol.Request
-		if err := json.Unmarshal(body, &req); err != nil {
-			writeErrorResponse(w, protocol.RequestID{}, -32700, "Parse error: Invalid Request structure", err)
+		if err := s.codec.Unmarshal(body, &req); err != nil {
+			s.writeErrorResponse(w, protocol.RequestID{}, -32700, "Parse error: Invalid Request structure", err)
 			return
 		}
-		s.handleRequest(w, &req)
+		// Handlers may add headers to the HTTP response with SetResponseHeader.
+		ctx, hw := withResponseHeaders(ctx, w)
+		// Clients that accept SSE may receive the response as a stream, so that
+		// notifications produced while handling the request can precede it.
+		// The stream always carries JSON, so this needs the JSON codec.
+		if _, isJSON := s.codec.(JSONCodec); isJSON && acceptsEventStream(r) {
+			if stream := newRequestStream(hw, s.maxEventSize, s.coalesceWindow); stream != nil {
+				// Deferred so that no coalesced flush runs after the handler returns, even
+				// if it panics. A panic once the stream has begun cannot be answered with
+				// an error response, so the connection is aborted instead.
+				defer func() {
+					if rec := recover(); rec != nil {
+						if !stream.abort() {
+							panic(rec)
+						}
+						if rec != http.ErrAbortHandler {
+							log.Errorf("Recovered from panic while streaming the response to %s: %v\n%s", req.Method, rec, debug.Stack())
+						}
+						panic(http.ErrAbortHandler)
+					}
+					stream.finish()
+				}()
+				s.handleRequest(context.WithValue(ctx, requestStreamKey, stream), stream, &req)
+				return
+			}
+		}
+		s.handleRequest(ctx, hw, &req)
 	} else {
 		var notif protocol.Notification
-		if err := json.Unmarshal(body, &notif); err != nil {
+		if err := s.codec.Unmarshal(body, &notif); err != nil {
 			log.Printf("Error parsing notification: %v", err)
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		s.handleNotification(w, &notif)
+		s.handleNotification(ctx, w, &notif)
 	}
 }
 
-func (s *Server) handleRequest(w http.ResponseWriter, req *protocol.Request) {
+// handleSessionDelete lets a client explicitly terminate its session.
+func (s *Server) handleSessionDelete(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		http.Error(w, "Missing Mcp-Session-Id header", http.StatusBadRequest)
+		return
+	}
+	if !s.closeSession(sessionID) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRequest(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	if req.Method != "initialize" {
+		if message := s.sessionNotReady(ctx); message != "" {
+			log.Warnf("Rejected %s request from session '%s': %s", req.Method, SessionIDFromContext(ctx), message)
+			s.writeErrorResponse(w, req.ID, -32600, message, nil)
+			return
+		}
+	}
+	s.dispatchRequest(ctx, w, req)
+}
+
+// dispatchRequest answers a request whose session, if it needs one, has been checked.
+func (s *Server) dispatchRequest(ctx context.Context, w http.ResponseWriter, req *protocol.Request) {
+	if s.metrics != nil {
+		s.metrics.countRequest(req.Method)
+	}
+	ctx = contextWithRequest(ctx, s, req.ID)
+	if token := progressToken(req.Params); len(token) > 0 {
+		ctx = contextWithProgressToken(ctx, token)
+	}
 	switch req.Method {
 	case "initialize":
-		s.handleInitialize(w, req)
+		s.handleInitialize(ctx, w, req)
 	case "tools/list":
-		s.handleListTools(w, req)
+		s.handleListTools(ctx, w, req)
 	case "tools/call":
-		s.handleCallTool(w, req)
+		s.handleCallTool(ctx, w, req)
+	case "tools/get":
+		s.handleGetTool(ctx, w, req)
+	case "resources/list":
+		s.handleListResources(ctx, w, req)
+	case "resources/read":
+		s.handleReadResource(ctx, w, req)
+	case "resources/subscribe":
+		s.handleSubscribe(ctx, w, req, true)
+	case "resources/unsubscribe":
+		s.handleSubscribe(ctx, w, req, false)
+	case "prompts/list":
+		s.handleListPrompts(ctx, w, req)
+	case "prompts/get":
+		s.handleGetPrompt(ctx, w, req)
+	case "logging/setLevel":
+		s.handleSetLevel(ctx, w, req)
 	default:
+		if handler := s.unknownMethod(); handler != nil {
+			result, err := handler(ctx, req)
+			if err != nil {
+				s.writeRPCError(w, req.ID, err)
+				return
+			}
+			s.writeSuccessResponse(w, req.ID, result)
+			return
+		}
 		log.Infof("Unknown method: %s", req.Method)
-		writeErrorResponse(w, req.ID, -32601, "Method not found", nil)
+		s.writeErrorResponse(w, req.ID, -32601, "Method not found", nil)
 	}
 }
 
-func (s *Server) handleNotification(w http.ResponseWriter, n *protocol.Notification) {
+func (s *Server) handleNotification(ctx context.Context, w http.ResponseWriter, n *protocol.Notification) {
 	log.Infof("Received notification: Method=%s", n.Method)
 	switch n.Method {
 	case "notifications/initialized":
 		log.Infof("Client confirmed initialization.")
+		if session := s.lookupSession(SessionIDFromContext(ctx)); session != nil {
+			session.initialized.Store(true)
+		}
 		w.WriteHeader(http.StatusAccepted)
 	default:
 		log.Infof("Received unhandled notification: %s", n.Method)
@@ -78,10 +188,10 @@ func (s *Server) handleNotification(w http.ResponseWriter, n *protocol.Notificat
 	}
 }
 
-func writeSuccessResponse(w http.ResponseWriter, id protocol.RequestID, result interface{}) {
+func (s *Server) writeSuccessResponse(w http.ResponseWriter, id protocol.RequestID, result interface{}) {
 	resultBytes, err := json.Marshal(result)
 	if err != nil {
-		writeErrorResponse(w, id, -32603, "Internal server error: failed to marshal result", err)
+		s.writeErrorResponse(w, id, -32603, "Internal server error: failed to marshal result", err)
 		return
 	}
 	resp := protocol.Response{
@@ -89,34 +199,67 @@ func writeSuccessResponse(w http.ResponseWriter, id protocol.RequestID, result i
 		ID:      id,
 		Result:  resultBytes,
 	}
-	w.Header().Set("Content-Type", "application/json")
+	respBytes, err := s.codec.Marshal(resp)
+	if err != nil {
+		s.writeErrorResponse(w, id, -32603, "Internal server error: failed to encode response", err)
+		return
+	}
+	w.Header().Set("Content-Type", s.codec.ContentType())
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
+	if _, err := w.Write(append(respBytes, '\n')); err != nil {
 		log.Errorf("Error writing success response: %v", err)
 	}
 }
 
-func writeErrorResponse(w http.ResponseWriter, id protocol.RequestID, code int, message string, data error) {
+// writeRPCError writes err as a JSON-RPC error response. A *protocol.ErrorObject is
+// sent as-is; any other error is reported as an internal error.
+func (s *Server) writeRPCError(w http.ResponseWriter, id protocol.RequestID, err error) {
+	var rpcErr *protocol.ErrorObject
+	if !errors.As(err, &rpcErr) {
+		s.writeErrorResponse(w, id, -32603, "Internal error", err)
+		return
+	}
+	var data error
+	if rpcErr.Data != nil {
+		data = fmt.Errorf("%v", rpcErr.Data)
+	}
+	s.writeErrorResponse(w, id, rpcErr.Code, rpcErr.Message, data)
+}
+
+func (s *Server) writeErrorResponse(w http.ResponseWriter, id protocol.RequestID, code int, message string, data error) {
 	var dataStr string
 	if data != nil {
 		dataStr = data.Error()
 	}
+	if custom, ok := s.errorMessages[code]; ok {
+		// Keep the default message's details when there is no other data to carry them.
+		if dataStr == "" && message != custom {
+			dataStr = message
+		}
+		message = custom
+	}
 	errorObj := &protocol.ErrorObject{Code: code, Message: message}
 	if dataStr != "" {
 		errorObj.Data = dataStr
 	}
 	resp := protocol.Response{JSONRPC: "2.0", ID: id, Error: errorObj}
+	respBytes, err := s.codec.Marshal(resp)
+	if err != nil {
+		log.Errorf("Error encoding error response: %v", err)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", s.codec.ContentType())
 	switch code {
 	case -32700, -32600, -32602:
 		w.WriteHeader(http.StatusBadRequest)
-	case -32601:
+	case -32601, -32002:
 		w.WriteHeader(http.StatusNotFound)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
+	if _, err := w.Write(append(respBytes, '\n')); err != nil {
 		log.Errorf("Error writing error response: %v", err)
 	}
 }
@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// post sends a raw JSON-RPC message to the server on the given session.
+func post(t testing.TB, s http.Handler, sessionID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResponsesEchoRequestIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"beyond float64 precision", `9007199254740993`},
+		{"large integer", `10000000000000001`},
+		{"fraction", `0.1`},
+		{"string", `"req-9007199254740993"`},
+	}
+	s := newTestServer(t, nil)
+	sessionID := mcptest.NewClient(t, s).SessionID()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post(t, s, sessionID, `{"jsonrpc":"2.0","id":`+tt.id+`,"method":"ping"}`)
+			if want := `"id":` + tt.id + `,`; !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("response %s does not echo id %s", rec.Body.String(), tt.id)
+			}
+		})
+	}
+}
+
+func TestErrorMessages(t *testing.T) {
+	messages := map[int]string{
+		-32700: "Requête illisible",
+		-32602: "Paramètres invalides",
+	}
+	tests := []struct {
+		name        string
+		body        string
+		wantCode    int
+		wantMessage string
+		wantData    string
+	}{
+		{"parse error", `{"jsonrpc":`, -32700, "Requête illisible", ""},
+		{"default message kept as data", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"missing"}}`, -32602, "Paramètres invalides", "Tool not found: missing"},
+		{"code without an override", `{"jsonrpc":"2.0","id":1,"method":"no/such/method"}`, -32601, "Method not found", ""},
+	}
+	s := newTestServer(t, nil, WithErrorMessages(messages))
+	sessionID := mcptest.NewClient(t, s).SessionID()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post(t, s, sessionID, tt.body)
+			var resp protocol.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding %s: %v", rec.Body.String(), err)
+			}
+			if resp.Error == nil {
+				t.Fatalf("response %s has no error", rec.Body.String())
+			}
+			if resp.Error.Code != tt.wantCode || resp.Error.Message != tt.wantMessage {
+				t.Errorf("error = %d %q, want %d %q", resp.Error.Code, resp.Error.Message, tt.wantCode, tt.wantMessage)
+			}
+			if tt.wantData != "" && resp.Error.Data != tt.wantData {
+				t.Errorf("data = %v, want %q", resp.Error.Data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestSessionGate(t *testing.T) {
+	const listTools = `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+	tests := []struct {
+		name string
+		opts []ServerOption
+		// session picks the Mcp-Session-Id header, given a freshly initialized session.
+		session     func(id string) string
+		initialized bool
+		wantError   string
+	}{
+		{"initialized session", nil, func(id string) string { return id }, true, ""},
+		{"missing header", nil, func(string) string { return "" }, true, "missing Mcp-Session-Id"},
+		{"unknown session", nil, func(string) string { return "bogus" }, true, "unknown session"},
+		{"handshake not completed", nil, func(id string) string { return id }, false, "not initialized"},
+		{"lenient, handshake not completed", []ServerOption{WithLenientInitialization()}, func(id string) string { return id }, false, ""},
+		{"lenient, missing header", []ServerOption{WithLenientInitialization()}, func(string) string { return "" }, false, "missing Mcp-Session-Id"},
+		{"lenient, unknown session", []ServerOption{WithLenientInitialization()}, func(string) string { return "bogus" }, false, "unknown session"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			rec := post(t, s, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"c","version":"1"}}}`)
+			sessionID := rec.Header().Get("Mcp-Session-Id")
+			if sessionID == "" {
+				t.Fatalf("initialize without a session was not answered with one: %s", rec.Body.String())
+			}
+			if tt.initialized {
+				post(t, s, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+			}
+
+			var resp protocol.Response
+			rec = post(t, s, tt.session(sessionID), listTools)
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding %s: %v", rec.Body.String(), err)
+			}
+			if tt.wantError == "" {
+				if resp.Error != nil {
+					t.Errorf("request was rejected: %s", resp.Error.Message)
+				}
+				return
+			}
+			if resp.Error == nil || resp.Error.Code != -32600 || !strings.Contains(resp.Error.Message, tt.wantError) {
+				t.Errorf("response = %s, want a -32600 error about %q", rec.Body.String(), tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"go-mcp-sdk/internal/jsonschema"
+)
+
+// SchemaGenerator produces the JSON schema advertised for a tool's input or structured
+// output type. t is the type as declared by the handler, which may be a pointer.
+type SchemaGenerator interface {
+	Generate(t reflect.Type) (json.RawMessage, error)
+}
+
+// SchemaGeneratorFunc adapts an ordinary function to the SchemaGenerator interface.
+type SchemaGeneratorFunc func(t reflect.Type) (json.RawMessage, error)
+
+// Generate calls f(t).
+func (f SchemaGeneratorFunc) Generate(t reflect.Type) (json.RawMessage, error) {
+	return f(t)
+}
+
+// WithSchemaGenerator replaces the built-in schema generator, for example with a
+// hand-written one or one backed by a different library. Options that tune the
+// built-in generator, such as WithSchemaReferences, have no effect on a replacement.
+func WithSchemaGenerator(generator SchemaGenerator) ServerOption {
+	return func(s *Server) {
+		s.schemaGenerator = generator
+	}
+}
+
+// WithMaxSchemaDepth limits how deeply objects, arrays and maps may nest in generated
+// schemas. Registering a tool whose input or structured output type nests deeper fails,
+// rather than advertising an enormous schema. The default allows 32 levels; recursive
+// types count only up to where they refer back to themselves. A generator set with
+// WithSchemaGenerator is not limited.
+func WithMaxSchemaDepth(depth int) ServerOption {
+	return func(s *Server) {
+		if depth > 0 {
+			s.schemaOptions.MaxDepth = depth
+		}
+	}
+}
+
+// defaultSchemaGenerator is the built-in generator, based on invopop/jsonschema.
+type defaultSchemaGenerator struct {
+	options jsonschema.Options
+}
+
+func (g defaultSchemaGenerator) Generate(t reflect.Type) (json.RawMessage, error) {
+	return jsonschema.GenerateSchemaWithOptions(t, g.options)
+}
+
+// schemaGeneratorOrDefault returns the configured generator, or the built-in one
+// honouring the server's schema options.
+func (s *Server) schemaGeneratorOrDefault() SchemaGenerator {
+	if s.schemaGenerator != nil {
+		return s.schemaGenerator
+	}
+	return defaultSchemaGenerator{options: s.schemaOptions}
+}
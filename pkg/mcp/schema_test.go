@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// stubGenerator answers every type with a schema naming it, and records what it was asked for.
+type stubGenerator struct {
+	types []reflect.Type
+	err   error
+}
+
+func (g *stubGenerator) Generate(t reflect.Type) (json.RawMessage, error) {
+	g.types = append(g.types, t)
+	if g.err != nil {
+		return nil, g.err
+	}
+	return json.RawMessage(`{"type":"object","title":"` + t.String() + `"}`), nil
+}
+
+func TestSchemaGenerator(t *testing.T) {
+	tests := []struct {
+		name         string
+		reg          ToolRegistration
+		generatorErr error
+		wantInput    string
+		wantOutput   string
+		wantTypes    []reflect.Type
+		wantErr      string
+	}{
+		{
+			name: "input schema",
+			reg: ToolRegistration{
+				Definition: protocol.Tool{Name: "check", Description: "Checks a code."},
+				Handler:    func(ctx context.Context, in *codeInput) (string, error) { return "", nil },
+			},
+			wantInput: `{"type":"object","title":"*mcp.codeInput"}`,
+			wantTypes: []reflect.Type{reflect.TypeOf(&codeInput{})},
+		},
+		{
+			name: "output schema",
+			reg: ToolRegistration{
+				Definition: protocol.Tool{Name: "check", Description: "Checks a code."},
+				Handler:    func(ctx context.Context, in *codeInput) (string, *codeOutput, error) { return "", nil, nil },
+			},
+			wantInput:  `{"type":"object","title":"*mcp.codeInput"}`,
+			wantOutput: `{"type":"object","title":"mcp.codeOutput"}`,
+			wantTypes:  []reflect.Type{reflect.TypeOf(&codeInput{}), reflect.TypeOf(codeOutput{})},
+		},
+		{
+			name: "generator error",
+			reg: ToolRegistration{
+				Definition: protocol.Tool{Name: "check", Description: "Checks a code."},
+				Handler:    func(ctx context.Context, in *codeInput) (string, error) { return "", nil },
+			},
+			generatorErr: errors.New("unsupported type"),
+			wantErr:      "unsupported type",
+		},
+		{
+			name: "descriptions",
+			reg: ToolRegistration{
+				Definition:   protocol.Tool{Name: "check", Description: "Checks a code."},
+				Handler:      func(ctx context.Context, in *codeInput) (string, error) { return "", nil },
+				Descriptions: map[string]string{"code": "A currency code."},
+			},
+			wantErr: "custom schema generator",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := &stubGenerator{err: tt.generatorErr}
+			s := NewServer("test", "1.0.0", testCapabilities, WithSchemaGenerator(generator))
+			err := s.RegisterTools([]ToolRegistration{tt.reg})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RegisterTools error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterTools: %v", err)
+			}
+			tool, _ := s.lookupTool(context.Background(), "check")
+			if got := string(tool.Definition.InputSchema); got != tt.wantInput {
+				t.Errorf("input schema = %s, want %s", got, tt.wantInput)
+			}
+			if got := string(tool.Definition.OutputSchema); got != tt.wantOutput {
+				t.Errorf("output schema = %s, want %s", got, tt.wantOutput)
+			}
+			if !reflect.DeepEqual(generator.types, tt.wantTypes) {
+				t.Errorf("generator was asked for %v, want %v", generator.types, tt.wantTypes)
+			}
+		})
+	}
+}
+
+func TestWithMaxSchemaDepth(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ServerOption
+		wantErr bool
+	}{
+		{"default", nil, false},
+		{"deep enough", []ServerOption{WithMaxSchemaDepth(4)}, false},
+		{"too shallow", []ServerOption{WithMaxSchemaDepth(3)}, true},
+		{"custom generator", []ServerOption{WithMaxSchemaDepth(1), WithSchemaGenerator(&stubGenerator{})}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("test", "1.0.0", testCapabilities, tt.opts...)
+			err := s.RegisterTools([]ToolRegistration{{
+				Definition: protocol.Tool{Name: "order", Description: "Places an order."},
+				Handler:    func(ctx context.Context, in *struct{ Lines []orderInput }) (string, error) { return "", nil },
+			}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterTools error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
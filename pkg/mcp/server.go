@@ -1,46 +1,379 @@
 package mcp
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
 	"net/http"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"go-mcp-sdk/internal/jsonschema"
+	"go-mcp-sdk/internal/logger"
 	"go-mcp-sdk/pkg/protocol"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// defaultPath is the URL path a server handles unless WithPath is used.
+const defaultPath = "/mcp"
+
 // Server holds the state and logic for an MCP server.
 type Server struct {
-	serverMux    *http.ServeMux
+	serverMux *http.ServeMux
+	// path is the URL path the MCP endpoint is served at.
+	path         string
+	infoLock     sync.RWMutex
 	info         protocol.ImplementationInfo
 	capabilities protocol.ServerCapabilities
 	sessionLock  sync.RWMutex
 	sessions     map[string]*SessionState
-	toolLock     sync.RWMutex
+	// sessionIDGenerator produces the id for each new session.
+	sessionIDGenerator func() string
+	toolLock           sync.RWMutex
 	// tools stores the internal representation of registered tools.
-	tools map[string]internalRegisteredTool
+	tools      map[string]internalRegisteredTool
+	promptLock sync.RWMutex
+	prompts    map[string]PromptRegistration
+	// resources is keyed by resource URI.
+	resourceLock sync.RWMutex
+	resources    map[string]ResourceRegistration
+	// protocolVersion, if set, is returned from initialize instead of the client's version.
+	protocolVersion string
+	// supportedVersions overrides supportedProtocolVersions, newest first.
+	supportedVersions []string
+	// versionAliases maps requested version names onto the versions they stand for.
+	versionAliases map[string]string
+	// positionalArguments enables mapping array-form tool arguments onto struct fields.
+	positionalArguments bool
+	auditSink           AuditSink
+	resultInterceptor   ResultInterceptor
+	schemaOptions       jsonschema.Options
+	// auditKey is the HMAC key of audited argument hashes; see WithAuditKey.
+	auditKey []byte
+	// fieldNaming names input fields that have no name in their json tag.
+	fieldNaming FieldNaming
+	// schemaGenerator, if set, replaces the built-in schema generation.
+	schemaGenerator  SchemaGenerator
+	outputValidation bool
+	// structuredErrors adds an "error" content block to tool error results.
+	structuredErrors bool
+	// emptyResultText, if set, is the text of results from error-only handlers.
+	emptyResultText string
+	// requiredArgumentChecks rejects calls missing, or nulling, required arguments.
+	requiredArgumentChecks bool
+	// errorMessages overrides the message of error responses by error code.
+	errorMessages map[int]string
+	// codec encodes and decodes request and response bodies.
+	codec Codec
+	// metrics, if set, collects the metrics served by WithPrometheusMetrics.
+	metrics *serverMetrics
+	// unknownMethodHandler, if set, serves requests for methods without a built-in handler.
+	unknownMethodLock    sync.RWMutex
+	unknownMethodHandler MethodHandler
+	// toolProvider, if set, supplies tools beyond the registered ones.
+	toolProvider ToolProvider
+	// providedTools caches the tools built from toolProvider's registrations, by name.
+	providedLock  sync.Mutex
+	providedTools map[string]providedTool
+	// resultCache holds results of cacheable tools; nil disables caching.
+	resultCache *resultCache
+	// toolTimeout bounds every tool call; zero means no server-wide limit.
+	toolTimeout time.Duration
+	// maxTools caps the number of registered tools; zero means unlimited.
+	maxTools int
+	// strictToolValidation rejects tools without a description instead of warning.
+	strictToolValidation bool
+	// limiter bounds concurrent tool executions; nil means unlimited.
+	limiter *executionLimiter
+	// debugTools registers the built-in "mcp/" diagnostic tools.
+	debugTools bool
+	// streamThreshold is the content size above which tool results are streamed; zero disables streaming.
+	streamThreshold int
+	manifestEnabled bool
+	// queryToolCalls serves GET <path>/tools/{name}; see WithQueryToolCalls.
+	queryToolCalls bool
+	// outgoing tracks requests the server sends to clients.
+	outgoing outgoingRequests
+	// lenientInit allows requests from sessions that have not sent notifications/initialized.
+	lenientInit bool
+	// httpServer is the server started by ListenAndServe, stopped by Shutdown.
+	httpServerLock sync.Mutex
+	httpServer     *http.Server
+	// eventStreamDisabled refuses GET streams; see WithoutEventStream.
+	eventStreamDisabled bool
+	// HTTP transport settings used by ListenAndServe.
+	enableH2C          bool
+	disableKeepAlives  bool
+	idleTimeout        time.Duration
+	notificationBuffer int
+	replayBuffer       int
+	maxEventSize       int
+	coalesceWindow     time.Duration
+	streamWriteTimeout time.Duration
+	overflowPolicy     OverflowPolicy
 }
 
 // SessionState holds state for a connected client.
 type SessionState struct {
 	ClientCapabilities protocol.ClientCapabilities
+	// ProtocolVersion is the protocol version agreed during initialize.
+	ProtocolVersion string
+	// Negotiated is what the session may use, worked out during initialize.
+	Negotiated Negotiated
+	// Locale is the language the client asked for during initialize, with the "locale"
+	// parameter or the Accept-Language header; it is empty if it gave none.
+	Locale string
+	// notifications queues server-initiated messages until the session's stream sends them.
+	notifications chan *protocol.Notification
+	// disconnect is signalled when the queue overflows under the Disconnect policy.
+	disconnect chan struct{}
+	queueLock  sync.Mutex
+	// initialized is set once the client sends "notifications/initialized".
+	initialized atomic.Bool
+	// done is closed when the session ends, terminating any open stream.
+	done      chan struct{}
+	closeOnce sync.Once
+	// openStreams counts the GET streams currently serving this session.
+	openStreams atomic.Int32
+	// Delivered stream events, kept for replay to clients resuming with Last-Event-ID.
+	eventLock   sync.Mutex
+	lastEventID uint64
+	replay      []streamEvent
+	// values holds application data attached with SetValue.
+	valuesLock sync.RWMutex
+	values     map[string]interface{}
+	// tools holds tools registered for this session only; see RegisterSessionTools.
+	toolLock sync.RWMutex
+	tools    map[string]internalRegisteredTool
+	// subscriptions holds the URIs of resources the client subscribed to.
+	subscriptionLock sync.Mutex
+	subscriptions    map[string]bool
+	// logLevel holds the minimum protocol.LoggingLevel the client asked to receive.
+	// Until the client sends "logging/setLevel", no log messages are forwarded.
+	logLevel atomic.Value
 }
 
-// NewServer creates a new MCP Server.
-func NewServer(name, version string, capabilities protocol.ServerCapabilities) *Server {
+// NewServer creates a new MCP Server. Creating the first server also sets up logrus's
+// standard logger, unless the application has configured it already, and applies the
+// MCP_LOG_LEVEL and MCP_LOG_FORMAT environment variables.
+func NewServer(name, version string, capabilities protocol.ServerCapabilities, opts ...ServerOption) *Server {
+	logger.Configure()
 	s := &Server{
-		serverMux:    http.NewServeMux(),
-		info:         protocol.ImplementationInfo{Name: name, Version: version},
-		capabilities: capabilities,
-		sessions:     make(map[string]*SessionState),
-		tools:        make(map[string]internalRegisteredTool),
+		serverMux:          http.NewServeMux(),
+		path:               defaultPath,
+		info:               protocol.ImplementationInfo{Name: name, Version: version},
+		capabilities:       capabilities,
+		sessions:           make(map[string]*SessionState),
+		tools:              make(map[string]internalRegisteredTool),
+		prompts:            make(map[string]PromptRegistration),
+		resources:          make(map[string]ResourceRegistration),
+		notificationBuffer: defaultNotificationBuffer,
+		replayBuffer:       defaultReplayBuffer,
+		sessionIDGenerator: newSessionID,
+		codec:              JSONCodec{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.auditSink != nil && len(s.auditKey) == 0 {
+		s.auditKey = newAuditKey()
+	}
+	if s.debugTools {
+		s.registerDebugTools()
+	}
+	s.serverMux.HandleFunc(s.path, s.handleMCPRequest)
+	if s.manifestEnabled {
+		s.serverMux.HandleFunc(s.path+"/manifest", s.handleManifest)
+	}
+	if s.queryToolCalls {
+		s.serverMux.HandleFunc(s.path+"/tools/", s.handleQueryToolCall)
+	}
+	if s.metrics != nil {
+		s.serverMux.HandleFunc(s.path+metricsPath, s.handleMetrics)
+	}
+	return s
+}
+
+// newSessionID returns a random (version 4) UUID. Session ids act as bearer tokens
+// for a session, so they must not be guessable.
+func newSessionID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("mcp: could not generate session id: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// maxSessionIDAttempts bounds how often createSession retries a generator that keeps
+// returning ids already in use.
+const maxSessionIDAttempts = 8
+
+// createSession registers a new session under a freshly generated id and returns the id.
+// An id that is already in use is never reused: the existing session would otherwise be
+// silently replaced, so a new id is requested instead.
+func (s *Server) createSession(capabilities protocol.ClientCapabilities, protocolVersion, locale string) (string, error) {
+	state := s.newSessionState(capabilities)
+	state.ProtocolVersion = protocolVersion
+	state.Locale = locale
+	state.Negotiated = s.negotiate(capabilities, protocolVersion)
+
+	s.sessionLock.Lock()
+	defer s.sessionLock.Unlock()
+	for attempt := 0; attempt < maxSessionIDAttempts; attempt++ {
+		sessionID := s.sessionIDGenerator()
+		if sessionID == "" {
+			continue
+		}
+		if _, exists := s.sessions[sessionID]; exists {
+			log.Warnf("Generated session id %s is already in use, generating another", sessionID)
+			continue
+		}
+		s.sessions[sessionID] = state
+		return sessionID, nil
+	}
+	return "", fmt.Errorf("no unused session id after %d attempts", maxSessionIDAttempts)
+}
+
+// lookupSession returns the state for sessionID, or nil if there is no such session.
+func (s *Server) lookupSession(sessionID string) *SessionState {
+	if sessionID == "" {
+		return nil
+	}
+	s.sessionLock.RLock()
+	defer s.sessionLock.RUnlock()
+	return s.sessions[sessionID]
+}
+
+// closeSession removes a session and terminates its streams. It reports whether the session existed.
+func (s *Server) closeSession(sessionID string) bool {
+	s.sessionLock.Lock()
+	session, exists := s.sessions[sessionID]
+	delete(s.sessions, sessionID)
+	s.sessionLock.Unlock()
+	if !exists {
+		return false
 	}
-	s.serverMux.HandleFunc("/mcp", s.handleMCPRequest)
+	session.close()
+	log.Infof("Closed session: %s", sessionID)
+	return true
+}
+
+// close signals the end of the session to anything waiting on it and drops its session tools.
+// It is safe to call more than once.
+func (st *SessionState) close() {
+	st.closeOnce.Do(func() {
+		close(st.done)
+		st.toolLock.Lock()
+		st.tools = nil
+		st.toolLock.Unlock()
+	})
+}
+
+// sessionNotReady explains why the calling session may not make requests other than
+// initialize, or returns "" if it may. The session must be known to the server and,
+// unless WithLenientInitialization is set, have completed the initialize handshake.
+func (s *Server) sessionNotReady(ctx context.Context) string {
+	sessionID := SessionIDFromContext(ctx)
+	if sessionID == "" {
+		return "Invalid Request: missing Mcp-Session-Id header, send initialize first"
+	}
+	session := s.lookupSession(sessionID)
+	if session == nil {
+		return "Invalid Request: unknown session, send initialize to start a new one"
+	}
+	if !s.lenientInit && !session.initialized.Load() {
+		return "Invalid Request: session is not initialized, send notifications/initialized first"
+	}
+	return ""
+}
+
+// ServerInfo returns the name, version and title reported to clients during initialize.
+func (s *Server) ServerInfo() protocol.ImplementationInfo {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+	return s.info
+}
+
+// SetServerInfo replaces the name, version and title reported to clients.
+// Sessions initialized before the change keep the info they were given.
+func (s *Server) SetServerInfo(info protocol.ImplementationInfo) {
+	s.infoLock.Lock()
+	defer s.infoLock.Unlock()
+	s.info = info
+}
+
+// ServeHTTP implements http.Handler, allowing the server to be mounted on any mux
+// or driven directly in tests.
+//
+// A panic anywhere while serving a request is recovered, logged with its stack trace,
+// and reported as a JSON-RPC internal error so other requests are unaffected. If the
+// response has already begun as an SSE stream, the connection is aborted instead.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			log.Errorf("Recovered from panic while serving %s %s: %v\n%s", r.Method, r.URL.Path, rec, debug.Stack())
+			s.writeErrorResponse(w, protocol.RequestID{}, -32603, "Internal error", nil)
+		}
+	}()
+	s.serverMux.ServeHTTP(w, r)
+}
+
+// Handler returns the server as an http.Handler. Mount it on a shared mux at the
+// server's Path, and at the tree below it where the server's other endpoints live,
+// to host several servers in one process:
+//
+//	calc := mcp.NewServer("calc", "1.0.0", caps, mcp.WithPath("/mcp/calc"))
+//	files := mcp.NewServer("files", "1.0.0", caps, mcp.WithPath("/mcp/files"))
+//	mux := http.NewServeMux()
+//	mux.Handle(calc.Path(), calc.Handler())
+//	mux.Handle(calc.Path()+"/", calc.Handler())
+//	mux.Handle(files.Path(), files.Handler())
+//	mux.Handle(files.Path()+"/", files.Handler())
+//
+// Each server keeps its own tools and sessions; a session id issued by one server
+// is unknown to the others. The optional endpoints, such as the manifest, metrics and
+// query string tool calls, are served below Path, so they do not collide either.
+func (s *Server) Handler() http.Handler {
 	return s
 }
 
-// ListenAndServe starts the HTTP server.
+// Path returns the URL path the server handles.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// ListenAndServe starts the HTTP server. It returns http.ErrServerClosed after Shutdown.
 func (s *Server) ListenAndServe(addr string) error {
-	log.Infof("MCP Server '%s' version '%s' listening on %s", s.info.Name, s.info.Version, addr)
-	return http.ListenAndServe(addr, s.serverMux)
-}
\ No newline at end of file
+	info := s.ServerInfo()
+	log.Infof("MCP Server '%s' version '%s' listening on %s", info.Name, info.Version, addr)
+	httpServer := s.newHTTPServer(addr)
+	s.httpServerLock.Lock()
+	s.httpServer = httpServer
+	s.httpServerLock.Unlock()
+	return httpServer.ListenAndServe()
+}
+
+// newHTTPServer builds the http.Server used by ListenAndServe from the configured options.
+func (s *Server) newHTTPServer(addr string) *http.Server {
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     s,
+		IdleTimeout: s.idleTimeout,
+	}
+	srv.SetKeepAlivesEnabled(!s.disableKeepAlives)
+	if s.enableH2C {
+		var protocols http.Protocols
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+		srv.Protocols = &protocols
+	}
+	return srv
+}
@@ -0,0 +1,265 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestH2CPriorKnowledge(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ServerOption
+		wantHTTP2 bool
+	}{
+		{"h2c enabled", []ServerOption{WithH2C()}, true},
+		{"HTTP/1.1 only", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Listen: %v", err)
+			}
+			srv := s.newHTTPServer(ln.Addr().String())
+			go srv.Serve(ln)
+			defer srv.Close()
+
+			// The client speaks HTTP/2 from the first byte, without negotiating it.
+			var protocols http.Protocols
+			protocols.SetUnencryptedHTTP2(true)
+			client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+			defer client.CloseIdleConnections()
+
+			body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"h2","version":"0"}}}`
+			resp, err := client.Post("http://"+ln.Addr().String()+"/mcp", "application/json", strings.NewReader(body))
+			if !tt.wantHTTP2 {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("HTTP/2 request succeeded with %s, want failure", resp.Proto)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Post: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.ProtoMajor != 2 {
+				t.Errorf("response protocol = %s, want HTTP/2", resp.Proto)
+			}
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Mcp-Session-Id") == "" {
+				t.Errorf("initialize over HTTP/2: status %d, session %q", resp.StatusCode, resp.Header.Get("Mcp-Session-Id"))
+			}
+		})
+	}
+}
+
+// panickyCodec panics while decoding any request for the method named in trigger.
+type panickyCodec struct {
+	JSONCodec
+	trigger string
+}
+
+func (c panickyCodec) Unmarshal(data []byte, v interface{}) error {
+	if strings.Contains(string(data), c.trigger) {
+		panic("codec failure")
+	}
+	return c.JSONCodec.Unmarshal(data, v)
+}
+
+func TestServeHTTPRecoversPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ServerOption
+	}{
+		{"result interceptor", []ServerOption{WithResultInterceptor(func(ctx context.Context, tool string, result *protocol.CallToolResult) *protocol.CallToolResult {
+			if tool == "explode" {
+				panic("interceptor failure")
+			}
+			return nil
+		})}},
+		{"codec", []ServerOption{WithCodec(panickyCodec{trigger: `"explode"`})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			echo := func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil }
+			s := newTestServer(t, []ToolRegistration{
+				{Definition: protocol.Tool{Name: "explode", Description: "Triggers a panic."}, Handler: echo},
+				{Definition: protocol.Tool{Name: "echo", Description: "Echoes its input."}, Handler: echo},
+			}, tt.opts...)
+			c := mcptest.NewClient(t, s)
+
+			rec := post(t, s, c.SessionID(), `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"explode","arguments":{"value":"x"}}}`)
+			if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), `"code":-32603`) {
+				t.Errorf("panicking request answered %d %s, want 500 with -32603", rec.Code, rec.Body.String())
+			}
+			if got := textOf(t, c.CallTool("echo", map[string]string{"value": "still up"})); got != "still up" {
+				t.Errorf("next call answered %q after the panic", got)
+			}
+		})
+	}
+}
+
+func TestMountedServersKeepSeparateSessions(t *testing.T) {
+	mux := http.NewServeMux()
+	servers := map[string]*Server{}
+	for _, name := range []string{"calc", "files"} {
+		s := newTestServer(t, []ToolRegistration{{
+			Definition: protocol.Tool{Name: name + "_echo", Description: "Echoes its input."},
+			Handler:    func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil },
+		}}, WithPath("/mcp/"+name))
+		mux.Handle(s.Path(), s.Handler())
+		servers[name] = s
+	}
+	clients := map[string]*mcptest.Client{
+		"calc":  mcptest.NewClientAt(t, mux, "/mcp/calc"),
+		"files": mcptest.NewClientAt(t, mux, "/mcp/files"),
+	}
+	if clients["calc"].SessionID() == clients["files"].SessionID() {
+		t.Fatalf("both servers issued session id %s", clients["calc"].SessionID())
+	}
+
+	tests := []struct {
+		name        string
+		server      string
+		client      string
+		tool        string
+		wantSession bool
+		wantCall    bool
+	}{
+		{"calc client on calc", "calc", "calc", "calc_echo", true, true},
+		{"files client on files", "files", "files", "files_echo", true, true},
+		{"calc client on files", "files", "calc", "files_echo", false, true},
+		{"files client on calc", "calc", "files", "calc_echo", false, true},
+		{"files tool on calc", "calc", "calc", "files_echo", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := servers[tt.server], clients[tt.client]
+			if got := s.lookupSession(c.SessionID()) != nil; got != tt.wantSession {
+				t.Errorf("%s knows the %s session: %v, want %v", tt.server, tt.client, got, tt.wantSession)
+			}
+			resp := mcptest.NewClientAt(t, mux, s.Path()).Call("tools/call", map[string]interface{}{"name": tt.tool, "arguments": map[string]string{"value": "hi"}})
+			if got := resp.Error == nil; got != tt.wantCall {
+				t.Errorf("calling %s on %s: error = %+v, want success %v", tt.tool, tt.server, resp.Error, tt.wantCall)
+			}
+		})
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestSessionIDGenerator(t *testing.T) {
+	var counter atomic.Int64
+	tests := []struct {
+		name  string
+		opts  []ServerOption
+		match func(id string) bool
+	}{
+		{"default random UUID", nil, uuidPattern.MatchString},
+		{"custom generator", []ServerOption{WithSessionIDGenerator(func() string {
+			return fmt.Sprintf("tenant-%d", counter.Add(1))
+		})}, func(id string) bool { return strings.HasPrefix(id, "tenant-") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			if id := mcptest.NewClient(t, s).SessionID(); !tt.match(id) {
+				t.Errorf("session id %q was not made by the expected generator", id)
+			}
+		})
+	}
+}
+
+func TestNewSessionIDIsUniqueUnderConcurrency(t *testing.T) {
+	const goroutines, perGoroutine = 16, 1000
+	ids := make(chan string, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				ids <- newSessionID()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if !uuidPattern.MatchString(id) {
+			t.Fatalf("session id %q is not a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Fatalf("session id %q was generated twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestConcurrentInitializeNeverSharesSessions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ServerOption
+		// wantAll is whether every initialize should succeed.
+		wantAll bool
+	}{
+		{"default generator", nil, true},
+		// A coarse clock, like the nanosecond timestamps ids were once made from, hands
+		// out the same id to initializes that arrive together.
+		{"coarse clock", []ServerOption{WithSessionIDGenerator(func() string {
+			return fmt.Sprintf("session-%d", time.Now().Unix())
+		})}, false},
+	}
+	const clients = 64
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			ids := make(chan string, clients)
+			var wg sync.WaitGroup
+			for i := 0; i < clients; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					rec := post(t, s, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"c","version":"1"}}}`)
+					ids <- rec.Header().Get("Mcp-Session-Id")
+				}()
+			}
+			wg.Wait()
+			close(ids)
+
+			issued := make(map[string]bool)
+			for id := range ids {
+				if id == "" {
+					continue
+				}
+				if issued[id] {
+					t.Fatalf("session id %s was issued to two clients", id)
+				}
+				issued[id] = true
+			}
+			if tt.wantAll && len(issued) != clients {
+				t.Errorf("%d of %d initializes got a session", len(issued), clients)
+			}
+			s.sessionLock.RLock()
+			sessions := len(s.sessions)
+			s.sessionLock.RUnlock()
+			if sessions != len(issued) {
+				t.Errorf("server holds %d sessions for %d issued ids", sessions, len(issued))
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ServiceDescriber is implemented by a service passed to RegisterService to describe
+// its tools. ToolDescriptions maps method names to the descriptions of their tools.
+type ServiceDescriber interface {
+	ToolDescriptions() map[string]string
+}
+
+// RegisterService registers each exported method of svc that has a valid handler
+// signature as a tool named "prefix/MethodName", similar to net/rpc.
+// Methods that do not match are skipped with a warning. Pass a pointer to also
+// include methods with pointer receivers. Tools are described by svc's
+// ToolDescriptions if it implements ServiceDescriber; under WithStrictToolValidation
+// every tool must have a description there.
+func (s *Server) RegisterService(prefix string, svc interface{}) error {
+	if prefix == "" {
+		return fmt.Errorf("service prefix must not be empty")
+	}
+	svcVal := reflect.ValueOf(svc)
+	if !svcVal.IsValid() {
+		return fmt.Errorf("service must not be nil")
+	}
+	svcType := svcVal.Type()
+	var descriptions map[string]string
+	if describer, ok := svc.(ServiceDescriber); ok {
+		descriptions = describer.ToolDescriptions()
+	}
+
+	var registrations []ToolRegistration
+	for i := 0; i < svcType.NumMethod(); i++ {
+		method := svcType.Method(i)
+		toolName := prefix + "/" + method.Name
+		handler := svcVal.Method(i)
+		if _, ok := svc.(ServiceDescriber); ok && method.Name == "ToolDescriptions" {
+			continue
+		}
+
+		if _, _, err := inspectHandler(handler); err != nil {
+			log.Warnf("Skipping method %s.%s for service '%s': %v", svcType, method.Name, prefix, err)
+			continue
+		}
+		registrations = append(registrations, ToolRegistration{
+			Definition: protocol.Tool{Name: toolName, Description: descriptions[method.Name]},
+			Handler:    handler.Interface(),
+		})
+	}
+
+	if len(registrations) == 0 {
+		return fmt.Errorf("service %s has no methods with a valid handler signature", svcType)
+	}
+	return s.RegisterTools(registrations)
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+)
+
+type mathInput struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+type mathService struct{}
+
+func (mathService) Add(ctx context.Context, in *mathInput) (int, error) { return in.A + in.B, nil }
+func (mathService) Sub(ctx context.Context, in *mathInput) (int, error) { return in.A - in.B, nil }
+
+type describedMathService struct{ mathService }
+
+func (describedMathService) ToolDescriptions() map[string]string {
+	return map[string]string{
+		"Add": "Adds b to a.",
+		"Sub": "Subtracts b from a.",
+	}
+}
+
+func TestRegisterServiceStrictToolValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     interface{}
+		opts    []ServerOption
+		wantErr string
+	}{
+		{"undescribed", mathService{}, nil, ""},
+		{"undescribed strict", mathService{}, []ServerOption{WithStrictToolValidation()}, "must include a description"},
+		{"described", describedMathService{}, nil, ""},
+		{"described strict", describedMathService{}, []ServerOption{WithStrictToolValidation()}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			err := s.RegisterService("math", tt.svc)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RegisterService error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterService: %v", err)
+			}
+			c := mcptest.NewClient(t, s)
+			if got := textOf(t, c.CallTool("math/Sub", map[string]int{"a": 5, "b": 3})); got != "2" {
+				t.Errorf("math/Sub = %q, want 2", got)
+			}
+			if _, exists := s.lookupTool(context.Background(), "math/ToolDescriptions"); exists {
+				t.Error("ToolDescriptions was registered as a tool")
+			}
+		})
+	}
+}
+
+func TestGenericToolsStrictToolValidation(t *testing.T) {
+	add := func(ctx context.Context, in *mathInput) (string, error) { return "", nil }
+	sum := func(ctx context.Context, in *mathInput) (map[string]int, error) { return nil, nil }
+	tests := []struct {
+		name    string
+		reg     ToolRegistration
+		wantErr bool
+	}{
+		{"Tool", Tool("add", add, WithDescription("Adds b to a.")), false},
+		{"Tool without description", Tool("add", add), true},
+		{"TypedTool", TypedTool("sum", sum, WithDescription("Sums a and b.")), false},
+		{"TypedTool without description", TypedTool("sum", sum), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, WithStrictToolValidation())
+			err := s.RegisterTools([]ToolRegistration{tt.reg})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterTools error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RegisterSessionTools registers tools that only the given session can see and call.
+// A session tool with the same name as a global tool overrides it for that session,
+// which lets multi-tenant servers swap in a tenant-scoped implementation without
+// running separate servers. Session tools are discarded when the session ends.
+func (s *Server) RegisterSessionTools(sessionID string, registrations []ToolRegistration) error {
+	session := s.lookupSession(sessionID)
+	if session == nil {
+		return fmt.Errorf("session '%s' not found", sessionID)
+	}
+
+	built := make([]internalRegisteredTool, 0, len(registrations))
+	for _, reg := range registrations {
+		tool, err := s.buildTool(reg)
+		if err == nil {
+			err = s.checkDescription(tool.Definition)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to register tool '%s': %w", reg.Definition.Name, err)
+		}
+		built = append(built, tool)
+	}
+
+	session.toolLock.Lock()
+	if session.tools == nil {
+		session.tools = make(map[string]internalRegisteredTool)
+	}
+	for _, tool := range built {
+		session.tools[tool.Definition.Name] = tool
+		log.Infof("Registered tool %s for session %s", tool.Definition.Name, sessionID)
+	}
+	session.toolLock.Unlock()
+
+	if len(built) > 0 {
+		s.notifySessionToolsChanged(session)
+	}
+	return nil
+}
+
+// UnregisterSessionTools removes session tools by name, restoring any global tool they overrode.
+// Names that are not registered for the session are ignored.
+func (s *Server) UnregisterSessionTools(sessionID string, names ...string) {
+	session := s.lookupSession(sessionID)
+	if session == nil {
+		return
+	}
+
+	removed := 0
+	session.toolLock.Lock()
+	for _, name := range names {
+		if _, exists := session.tools[name]; exists {
+			delete(session.tools, name)
+			removed++
+			log.Infof("Unregistered tool %s for session %s", name, sessionID)
+		}
+	}
+	session.toolLock.Unlock()
+
+	if removed > 0 {
+		s.notifySessionToolsChanged(session)
+	}
+}
+
+// toolOrigin records which registry lookupTool found a tool in.
+type toolOrigin int
+
+const (
+	originGlobal toolOrigin = iota
+	originSession
+	originProvider
+)
+
+// lookupTool resolves a tool for the calling session, preferring its session tools
+// over the global registry, and the global registry over the tool provider.
+func (s *Server) lookupTool(ctx context.Context, name string) (internalRegisteredTool, bool) {
+	if session := s.lookupSession(SessionIDFromContext(ctx)); session != nil {
+		session.toolLock.RLock()
+		tool, exists := session.tools[name]
+		session.toolLock.RUnlock()
+		if exists {
+			tool.origin = originSession
+			return tool, true
+		}
+	}
+
+	s.toolLock.RLock()
+	tool, exists := s.tools[name]
+	s.toolLock.RUnlock()
+	if exists {
+		return tool, true
+	}
+	tool, exists = s.resolveProvidedTool(ctx, name)
+	tool.origin = originProvider
+	return tool, exists
+}
+
+// listTools returns the definitions of the tools visible to the calling session, sorted
+// by name so that the list is stable from one call to the next.
+func (s *Server) listTools(ctx context.Context) []protocol.Tool {
+	var overrides map[string]internalRegisteredTool
+	if session := s.lookupSession(SessionIDFromContext(ctx)); session != nil {
+		session.toolLock.RLock()
+		overrides = make(map[string]internalRegisteredTool, len(session.tools))
+		for name, tool := range session.tools {
+			overrides[name] = tool
+		}
+		session.toolLock.RUnlock()
+	}
+
+	languages := s.preferredLanguages(ctx)
+	s.toolLock.RLock()
+	toolList := make([]protocol.Tool, 0, len(s.tools)+len(overrides))
+	listed := make(map[string]bool, len(s.tools)+len(overrides))
+	for name, tool := range s.tools {
+		listed[name] = true
+		if _, overridden := overrides[name]; overridden {
+			continue
+		}
+		if tool.visibleTo(ctx) {
+			toolList = append(toolList, tool.definitionFor(languages))
+		}
+	}
+	s.toolLock.RUnlock()
+	for name, tool := range overrides {
+		listed[name] = true
+		if tool.visibleTo(ctx) {
+			toolList = append(toolList, tool.definitionFor(languages))
+		}
+	}
+
+	if s.toolProvider != nil {
+		// Provided tools are listed as tools/get describes them: built from the
+		// provider's registration, checked for visibility and localized.
+		for _, def := range s.toolProvider.List(ctx) {
+			if listed[def.Name] {
+				continue
+			}
+			listed[def.Name] = true
+			if tool, ok := s.resolveProvidedTool(ctx, def.Name); ok && tool.visibleTo(ctx) {
+				toolList = append(toolList, tool.definitionFor(languages))
+			}
+		}
+	}
+	sort.Slice(toolList, func(i, j int) bool { return toolList[i].Name < toolList[j].Name })
+	return toolList
+}
+
+// notifySessionToolsChanged tells a single session that its tool list changed,
+// if the server advertises listChanged for tools.
+func (s *Server) notifySessionToolsChanged(session *SessionState) {
+	if s.capabilities.Tools == nil || !s.capabilities.Tools.ListChanged {
+		return
+	}
+	notif, err := newNotification("notifications/tools/list_changed", nil)
+	if err != nil {
+		log.Errorf("Failed to build notification: %v", err)
+		return
+	}
+	if !session.enqueue(notif, s.overflowPolicy) {
+		log.Warnf("Notification queue full while sending notifications/tools/list_changed")
+	}
+}
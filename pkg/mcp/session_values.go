@@ -0,0 +1,66 @@
+package mcp
+
+import "context"
+
+// Session returns the state of the session with the given id, or nil if there is none.
+// HTTP middleware can use it, with the request's Mcp-Session-Id header, to attach
+// application data before the request reaches the server.
+func (s *Server) Session(sessionID string) *SessionState {
+	return s.lookupSession(sessionID)
+}
+
+// Value returns the application value stored on the session under key.
+func (st *SessionState) Value(key string) (interface{}, bool) {
+	st.valuesLock.RLock()
+	defer st.valuesLock.RUnlock()
+	value, ok := st.values[key]
+	return value, ok
+}
+
+// SetValue stores an application value, such as a tenant id or auth claims, on the
+// session. It is safe to call concurrently with Value and with other SetValue calls.
+func (st *SessionState) SetValue(key string, value interface{}) {
+	st.valuesLock.Lock()
+	defer st.valuesLock.Unlock()
+	if st.values == nil {
+		st.values = make(map[string]interface{})
+	}
+	st.values[key] = value
+}
+
+// DeleteValue removes the value stored under key, if any.
+func (st *SessionState) DeleteValue(key string) {
+	st.valuesLock.Lock()
+	defer st.valuesLock.Unlock()
+	delete(st.values, key)
+}
+
+// SessionFromContext returns the state of the session that issued the current request,
+// or nil outside a request handler or session.
+func SessionFromContext(ctx context.Context) *SessionState {
+	server, _ := ctx.Value(serverKey).(*Server)
+	if server == nil {
+		return nil
+	}
+	return server.lookupSession(SessionIDFromContext(ctx))
+}
+
+// SessionValue returns the value stored under key on the calling session.
+func SessionValue(ctx context.Context, key string) (interface{}, bool) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return nil, false
+	}
+	return session.Value(key)
+}
+
+// SetSessionValue stores a value on the calling session. It reports false if the
+// request was not made within a session.
+func SetSessionValue(ctx context.Context, key string, value interface{}) bool {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return false
+	}
+	session.SetValue(key, value)
+	return true
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestSessionValuesConcurrentAccess(t *testing.T) {
+	const writers = 16
+	session := &SessionState{}
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			own := fmt.Sprintf("writer-%d", i)
+			for j := 0; j < 100; j++ {
+				session.SetValue(own, j)
+				session.SetValue("shared", i)
+				session.Value("shared")
+				session.SetValue("scratch", j)
+				session.DeleteValue("scratch")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < writers; i++ {
+		if got, ok := session.Value(fmt.Sprintf("writer-%d", i)); !ok || got != 99 {
+			t.Errorf("writer-%d = %v, %v; want 99", i, got, ok)
+		}
+	}
+	if got, ok := session.Value("shared"); !ok || got.(int) < 0 || got.(int) >= writers {
+		t.Errorf("shared = %v, %v; want one writer's index", got, ok)
+	}
+	if got, ok := session.Value("scratch"); ok {
+		t.Errorf("scratch = %v after every writer deleted it", got)
+	}
+}
+
+func TestSessionValuesFromContext(t *testing.T) {
+	// count adds one to a per-session counter and reports its new value. The handler
+	// serializes its own read-modify-write; the session only guards each access.
+	var countLock sync.Mutex
+	count := func(ctx context.Context, in *echoInput) (string, error) {
+		countLock.Lock()
+		defer countLock.Unlock()
+		value, _ := SessionValue(ctx, "count")
+		n, _ := value.(int)
+		SetSessionValue(ctx, "count", n+1)
+		return fmt.Sprint(n + 1), nil
+	}
+	tenant := func(ctx context.Context, in *echoInput) (string, error) {
+		if in.Value != "" && !SetSessionValue(ctx, "tenant", in.Value) {
+			return "", fmt.Errorf("no session")
+		}
+		value, _ := SessionValue(ctx, "tenant")
+		return fmt.Sprint(value), nil
+	}
+	s := newTestServer(t, []ToolRegistration{
+		{Definition: protocol.Tool{Name: "count", Description: "Counts calls in this session."}, Handler: count},
+		{Definition: protocol.Tool{Name: "tenant", Description: "Sets or reads this session's tenant."}, Handler: tenant},
+	})
+
+	tests := []struct {
+		name  string
+		calls int
+	}{
+		{"first session", 20},
+		{"second session", 35},
+	}
+	var wg sync.WaitGroup
+	clients := make([]*mcptest.Client, len(tests))
+	for i, tt := range tests {
+		clients[i] = mcptest.NewClient(t, s)
+		for j := 0; j < tt.calls; j++ {
+			wg.Add(1)
+			go func(sessionID string) {
+				defer wg.Done()
+				post(t, s, sessionID, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"count","arguments":{"value":""}}}`)
+			}(clients[i].SessionID())
+		}
+	}
+	wg.Wait()
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := clients[i]
+			if got, ok := s.Session(c.SessionID()).Value("count"); !ok || got != tt.calls {
+				t.Errorf("count = %v, want %d", got, tt.calls)
+			}
+			name := strings.ReplaceAll(tt.name, " ", "-")
+			c.CallTool("tenant", map[string]string{"value": name})
+			if got := textOf(t, c.CallTool("tenant", map[string]string{"value": ""})); got != name {
+				t.Errorf("tenant = %q, want %q", got, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ShutdownNotification is sent to every session with an open stream when the server shuts down,
+// so clients can fail over before their streams close.
+const ShutdownNotification = "notifications/server/shutdown"
+
+// drainPollInterval is how often Shutdown checks whether streams have sent their queued messages.
+const drainPollInterval = 10 * time.Millisecond
+
+// Shutdown gracefully stops the server. It notifies connected clients with
+// ShutdownNotification, waits for open streams to deliver their queued messages, then
+// ends every session, closing their streams, and finally shuts down the HTTP server
+// started by ListenAndServe, if any. If ctx expires first, the remaining steps still run
+// but without waiting, and ctx's error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	log.Infof("Shutting down MCP server: notifying connected clients")
+	s.broadcastNotification(ShutdownNotification, nil)
+	drainErr := s.drainStreams(ctx)
+	if drainErr != nil {
+		log.Warnf("Shutdown deadline reached before all streams drained: %v", drainErr)
+	}
+
+	s.sessionLock.RLock()
+	sessionIDs := make([]string, 0, len(s.sessions))
+	for sessionID := range s.sessions {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	s.sessionLock.RUnlock()
+	for _, sessionID := range sessionIDs {
+		s.closeSession(sessionID)
+	}
+
+	s.httpServerLock.Lock()
+	httpServer := s.httpServer
+	s.httpServerLock.Unlock()
+	if httpServer != nil {
+		if err := httpServer.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	return drainErr
+}
+
+// drainStreams waits until every session with an open stream has sent all queued
+// notifications, or until ctx is done.
+func (s *Server) drainStreams(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		if !s.hasUndeliveredNotifications() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// hasUndeliveredNotifications reports whether any session with an open stream still has
+// notifications queued. Sessions without a stream are not waited for.
+func (s *Server) hasUndeliveredNotifications() bool {
+	s.sessionLock.RLock()
+	defer s.sessionLock.RUnlock()
+	for _, session := range s.sessions {
+		if session.openStreams.Load() > 0 && len(session.notifications) > 0 {
+			return true
+		}
+	}
+	return false
+}
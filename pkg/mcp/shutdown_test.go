@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-mcp-sdk/pkg/mcptest"
+)
+
+func TestShutdownNotifiesClients(t *testing.T) {
+	tests := []struct {
+		name string
+		// stream opens the session's GET stream; stalled streams stop accepting writes.
+		stream, stalled bool
+		wantErr         error
+	}{
+		{"open stream", true, false, nil},
+		{"no stream", false, false, nil},
+		{"stalled stream", true, true, context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			sessionID := mcptest.NewClient(t, s).SessionID()
+			session := s.lookupSession(sessionID)
+			var rec *streamRecorder
+			closeStream, release := func() {}, func() {}
+			if tt.stream {
+				rec, closeStream = openStream(t, s, sessionID, "")
+			}
+			if tt.stalled {
+				release = rec.stall()
+				// The stream picks this up and blocks writing it, so the shutdown
+				// notification stays queued.
+				s.broadcastNotification("notifications/message", nil)
+				waitFor(t, "the stream to take the message", func() bool { return len(session.notifications) == 0 })
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			if err := s.Shutdown(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Shutdown = %v, want %v", err, tt.wantErr)
+			}
+			release()
+			closeStream()
+
+			if s.lookupSession(sessionID) != nil {
+				t.Error("session still exists after Shutdown")
+			}
+			select {
+			case <-session.done:
+			default:
+				t.Error("session was not ended")
+			}
+			if tt.stream && !tt.stalled && !strings.Contains(rec.String(), ShutdownNotification) {
+				t.Errorf("stream ended without %s:\n%s", ShutdownNotification, rec.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WithStreamedResults writes tool results whose content exceeds threshold bytes
+// incrementally, one content block at a time, instead of marshaling the whole
+// response in memory first. Such responses have no Content-Length and are sent
+// with chunked transfer encoding. This lowers peak memory for very large outputs.
+//
+// Because the status line is sent before the content is encoded, a content block
+// that fails to encode aborts the connection rather than producing an error response.
+func WithStreamedResults(threshold int) ServerOption {
+	return func(s *Server) {
+		s.streamThreshold = threshold
+	}
+}
+
+// shouldStream reports whether result is large enough to be written with writeStreamedResult.
+func (s *Server) shouldStream(result *protocol.CallToolResult) bool {
+	if s.streamThreshold <= 0 {
+		return false
+	}
+	// The streamed response is written as JSON by hand.
+	if _, isJSON := s.codec.(JSONCodec); !isJSON {
+		return false
+	}
+	size := 0
+	for _, block := range result.Content {
+		size += len(block.Text) + len(block.Data)
+		if block.Resource != nil {
+			size += len(block.Resource.Text) + len(block.Resource.Blob)
+		}
+	}
+	return size > s.streamThreshold
+}
+
+// writeStreamedResult writes a tools/call success response, encoding each content block
+// straight to the connection. The id, structured content and metadata are encoded up
+// front so the common failure cases can still be reported as a JSON-RPC error. The
+// response is aborted if ctx is cancelled part-way, as when the client disconnects.
+func (s *Server) writeStreamedResult(ctx context.Context, w http.ResponseWriter, id protocol.RequestID, result *protocol.CallToolResult) {
+	idBytes, err := json.Marshal(id)
+	if err != nil {
+		s.writeErrorResponse(w, id, -32603, "Internal server error: failed to marshal result", err)
+		return
+	}
+	var structured, meta []byte
+	if result.StructuredContent != nil {
+		if structured, err = json.Marshal(result.StructuredContent); err != nil {
+			s.writeErrorResponse(w, id, -32603, "Internal server error: failed to marshal result", err)
+			return
+		}
+	}
+	if len(result.Meta) > 0 {
+		if meta, err = json.Marshal(result.Meta); err != nil {
+			s.writeErrorResponse(w, id, -32603, "Internal server error: failed to marshal result", err)
+			return
+		}
+	}
+
+	startResponse(w)
+
+	write := func(parts ...[]byte) {
+		if err := ctx.Err(); err != nil {
+			log.Debugf("Abandoning streamed response: %v", err)
+			panic(http.ErrAbortHandler)
+		}
+		for _, part := range parts {
+			if _, err := w.Write(part); err != nil {
+				log.Errorf("Error writing streamed response: %v", err)
+				panic(http.ErrAbortHandler)
+			}
+		}
+	}
+
+	write([]byte(`{"jsonrpc":"2.0","id":`), idBytes, []byte(`,"result":{"content":[`))
+	for i, block := range result.Content {
+		blockBytes, err := json.Marshal(block)
+		if err != nil {
+			log.Errorf("Error encoding content block %d of streamed response: %v", i, err)
+			panic(http.ErrAbortHandler)
+		}
+		if i > 0 {
+			write([]byte(","))
+		}
+		write(blockBytes)
+	}
+	write([]byte("]"))
+	if structured != nil {
+		write([]byte(`,"structuredContent":`), structured)
+	}
+	if result.IsError {
+		write([]byte(`,"isError":true`))
+	}
+	if meta != nil {
+		write([]byte(`,"_meta":`), meta)
+	}
+	write([]byte("}}\n"))
+}
+
+// startResponse sends the status line of a response written piece by piece. On a
+// request that has switched to an SSE stream, the response is instead written through
+// as the data of the stream's final event rather than collected in memory.
+func startResponse(w http.ResponseWriter) {
+	if rs, ok := w.(*requestStream); ok && rs.startResult() {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+}
+
+// readerChunkSize is how much of a tool's io.Reader result is read and encoded at a time.
+const readerChunkSize = 32 * 1024
+
+// canStreamReader reports whether an io.Reader result of tool can be written as it is
+// read. Result interceptors and the result cache need the complete result, and the
+// response is written as JSON by hand.
+func (s *Server) canStreamReader(tool internalRegisteredTool) bool {
+	if _, isJSON := s.codec.(JSONCodec); !isJSON {
+		return false
+	}
+	return s.resultInterceptor == nil && !(tool.cacheable && s.resultCache != nil)
+}
+
+// writeReaderResult writes a tools/call success response whose single text content
+// block is read from reader, encoding it chunk by chunk. Once the status line is sent,
+// failures can no longer be reported to the client; they are returned so the caller
+// can abort the response. Cancelling ctx stops the copy at the next chunk.
+func (s *Server) writeReaderResult(ctx context.Context, w http.ResponseWriter, id protocol.RequestID, reader io.Reader, warnings []string) error {
+	idBytes, err := json.Marshal(id)
+	if err != nil {
+		s.writeErrorResponse(w, id, -32603, "Internal server error: failed to marshal result", err)
+		return nil
+	}
+	var meta []byte
+	if len(warnings) > 0 {
+		if meta, err = json.Marshal(map[string]interface{}{"warnings": warnings}); err != nil {
+			s.writeErrorResponse(w, id, -32603, "Internal server error: failed to marshal result", err)
+			return nil
+		}
+	}
+
+	startResponse(w)
+
+	write := func(parts ...[]byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, part := range parts {
+			if _, err := w.Write(part); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := write([]byte(`{"jsonrpc":"2.0","id":`), idBytes, []byte(`,"result":{"content":[{"type":"text","text":"`)); err != nil {
+		return err
+	}
+	// A chunk may end part-way through a UTF-8 sequence; those bytes are carried over to
+	// the next chunk so the character is encoded whole.
+	buf := make([]byte, readerChunkSize+utf8.UTFMax)
+	pending := 0
+	for {
+		n, readErr := reader.Read(buf[pending : pending+readerChunkSize])
+		n += pending
+		complete := n
+		if readErr == nil {
+			complete = completeUTF8Prefix(buf[:n])
+		}
+		if complete > 0 {
+			if err := write(encodeJSONStringContent(buf[:complete])); err != nil {
+				return err
+			}
+		}
+		pending = copy(buf, buf[complete:n])
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	if err := write([]byte(`"}]`)); err != nil {
+		return err
+	}
+	if meta != nil {
+		if err := write([]byte(`,"_meta":`), meta); err != nil {
+			return err
+		}
+	}
+	return write([]byte("}}\n"))
+}
+
+// completeUTF8Prefix returns the length of p without a trailing, incomplete UTF-8 sequence.
+func completeUTF8Prefix(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
+
+// encodeJSONStringContent returns p encoded as the inside of a JSON string, escaped
+// exactly as encoding/json escapes strings.
+func encodeJSONStringContent(p []byte) []byte {
+	encoded, _ := json.Marshal(string(p))
+	return encoded[1 : len(encoded)-1]
+}
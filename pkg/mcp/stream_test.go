@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestStreamedResults(t *testing.T) {
+	large := strings.Repeat("line of output\n", 4096)
+	tests := []struct {
+		name     string
+		handler  interface{}
+		progress bool
+	}{
+		{"plain JSON", func(ctx context.Context, in echoInput) (string, error) {
+			return large, nil
+		}, false},
+		{"SSE stream", func(ctx context.Context, in echoInput) (string, error) {
+			ReportProgress(ctx, 1, 1, "done")
+			return large, nil
+		}, true},
+		{"reader on plain JSON", func(ctx context.Context, in echoInput) (io.Reader, error) {
+			return strings.NewReader(large), nil
+		}, false},
+		{"reader on SSE stream", func(ctx context.Context, in echoInput) (io.Reader, error) {
+			ReportProgress(ctx, 1, 1, "done")
+			return strings.NewReader(large), nil
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "dump", Description: "Dumps output."},
+				Handler:    tt.handler,
+			}}, WithStreamedResults(1024))
+			c := mcptest.NewClient(t, s)
+
+			params := map[string]interface{}{"name": "dump", "arguments": map[string]interface{}{}}
+			if tt.progress {
+				params["_meta"] = map[string]interface{}{"progressToken": "p"}
+			}
+			resp := c.Call("tools/call", params)
+			if resp.Error != nil {
+				t.Fatalf("tools/call failed: %d %s", resp.Error.Code, resp.Error.Message)
+			}
+			var result protocol.CallToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatalf("decoding result: %v", err)
+			}
+			if got := textOf(t, &result); got != large {
+				t.Errorf("text has %d bytes, want %d", len(got), len(large))
+			}
+			if got := len(c.Notifications()) > 0; got != tt.progress {
+				t.Errorf("progress notification received = %v, want %v", got, tt.progress)
+			}
+		})
+	}
+}
+
+func TestRequestStreamWritesResultThrough(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rs := newRequestStream(rec, 0, 0)
+	rs.start()
+	startResponse(rs)
+	if _, err := rs.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}` + "\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if rs.body.Len() != 0 {
+		t.Errorf("result was collected in memory: %q", rs.body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `data: {"jsonrpc"`) {
+		t.Errorf("result not written through before finish: %q", rec.Body.String())
+	}
+	rs.finish()
+	if want := "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{}}\n\n\n"; rec.Body.String() != want {
+		t.Errorf("stream = %q, want %q", rec.Body.String(), want)
+	}
+}
+
+// discardResponseWriter throws away everything written, so benchmarks measure the
+// server's own allocations.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d *discardResponseWriter) Header() http.Header         { return d.header }
+func (d *discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (d *discardResponseWriter) WriteHeader(int)             {}
+func (d *discardResponseWriter) Flush()                      {}
+
+func BenchmarkStreamedResult(b *testing.B) {
+	large := strings.Repeat("x", 4<<20)
+	for _, threshold := range []int{0, 64 * 1024} {
+		name := "buffered"
+		if threshold > 0 {
+			name = "streamed"
+		}
+		b.Run(name, func(b *testing.B) {
+			s := newTestServer(b, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "dump", Description: "Dumps output."},
+				Handler: func(ctx context.Context, in echoInput) (string, error) {
+					return large, nil
+				},
+			}}, WithStreamedResults(threshold))
+			sessionID := mcptest.NewClient(b, s).SessionID()
+			body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"dump","arguments":{}}}`
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Mcp-Session-Id", sessionID)
+				w := &discardResponseWriter{header: http.Header{}}
+				s.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+// disconnectingWriter records a response and disconnects the client, by cancelling the
+// request's context, as soon as the first bytes are written.
+type disconnectingWriter struct {
+	header     http.Header
+	body       strings.Builder
+	disconnect func()
+}
+
+func (d *disconnectingWriter) Header() http.Header { return d.header }
+func (d *disconnectingWriter) WriteHeader(int)     {}
+func (d *disconnectingWriter) Write(p []byte) (int, error) {
+	d.body.Write(p)
+	d.disconnect()
+	return len(p), nil
+}
+
+// endlessReader produces output for as long as it is read, counting the reads.
+type endlessReader struct {
+	reads *atomic.Int64
+}
+
+func (r endlessReader) Read(p []byte) (int, error) {
+	r.reads.Add(1)
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestClientDisconnectMidWrite(t *testing.T) {
+	var reads atomic.Int64
+	var disconnect func()
+	var handlerSawCancel atomic.Bool
+	tests := []struct {
+		name      string
+		handler   interface{}
+		wantAbort bool
+	}{
+		{"streamed content", func(ctx context.Context, in echoInput) (string, error) {
+			return strings.Repeat("line of output\n", 4096), nil
+		}, true},
+		{"reader", func(ctx context.Context, in echoInput) (io.Reader, error) {
+			return endlessReader{&reads}, nil
+		}, true},
+		{"gone before the result", func(ctx context.Context, in echoInput) (string, error) {
+			disconnect()
+			handlerSawCancel.Store(ctx.Err() != nil)
+			return "too late", nil
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reads.Store(0)
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "dump", Description: "Dumps output."},
+				Handler:    tt.handler,
+			}}, WithStreamedResults(1024))
+			sessionID := mcptest.NewClient(t, s).SessionID()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			disconnect = cancel
+			body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"dump","arguments":{}}}`
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)).WithContext(ctx)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Mcp-Session-Id", sessionID)
+			w := &disconnectingWriter{header: make(http.Header), disconnect: cancel}
+
+			aborted := func() (aborted bool) {
+				defer func() {
+					if rec := recover(); rec != nil {
+						if rec != http.ErrAbortHandler {
+							panic(rec)
+						}
+						aborted = true
+					}
+				}()
+				s.ServeHTTP(w, req)
+				return false
+			}()
+			if aborted != tt.wantAbort {
+				t.Errorf("aborted = %v, want %v", aborted, tt.wantAbort)
+			}
+			if strings.HasSuffix(strings.TrimSpace(w.body.String()), "}") {
+				t.Errorf("a complete response was written after the client went away: %.80q", w.body.String())
+			}
+			if got := reads.Load(); got > 2 {
+				t.Errorf("reader was read %d times after the client went away", got)
+			}
+		})
+	}
+	if !handlerSawCancel.Load() {
+		t.Error("the handler's context was not cancelled when the client went away")
+	}
+}
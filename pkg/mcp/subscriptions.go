@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NotifyResourceUpdated tells every session subscribed to uri that the resource changed,
+// with a "notifications/resources/updated" notification. For resources registered with
+// InlineUpdates, the resource is read once, using ctx, and its contents are included.
+// If that read fails, subscribers are still notified, without contents, and the error
+// is returned.
+func (s *Server) NotifyResourceUpdated(ctx context.Context, uri string) error {
+	s.resourceLock.RLock()
+	resource, exists := s.resources[uri]
+	s.resourceLock.RUnlock()
+	if !exists {
+		return fmt.Errorf("resource '%s' is not registered", uri)
+	}
+
+	type target struct {
+		id      string
+		session *SessionState
+	}
+	s.sessionLock.RLock()
+	var targets []target
+	for sessionID, session := range s.sessions {
+		if session.subscribed(uri) {
+			targets = append(targets, target{id: sessionID, session: session})
+		}
+	}
+	s.sessionLock.RUnlock()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	params := protocol.ResourceUpdatedNotification{URI: uri}
+	var readErr error
+	if resource.InlineUpdates {
+		params.Contents, readErr = resource.Handler(ctx, uri)
+		if readErr != nil {
+			params.Contents = nil
+			readErr = fmt.Errorf("failed to read resource %s: %w", uri, readErr)
+		} else {
+			params.Contents = typeResourceContents(params.Contents, resource.Definition.MIMEType)
+		}
+	}
+	notif, err := newNotification("notifications/resources/updated", params)
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if !t.session.enqueue(notif, s.overflowPolicy) {
+			log.Warnf("Notification queue full for session %s while sending %s", t.id, notif.Method)
+		}
+	}
+	return readErr
+}
+
+// subscribed reports whether the session subscribed to the resource at uri.
+func (st *SessionState) subscribed(uri string) bool {
+	st.subscriptionLock.Lock()
+	defer st.subscriptionLock.Unlock()
+	return st.subscriptions[uri]
+}
+
+// handleSubscribe serves "resources/subscribe" and, when subscribe is false,
+// "resources/unsubscribe".
+func (s *Server) handleSubscribe(ctx context.Context, w http.ResponseWriter, req *protocol.Request, subscribe bool) {
+	resources := s.capabilities.Resources
+	if !s.requireCapability(w, req, resources != nil && resources.Subscribe, "resource subscriptions") {
+		return
+	}
+
+	var params protocol.SubscribeRequest
+	if err := DecodeParams(req, &params); err != nil {
+		s.writeRPCError(w, req.ID, err)
+		return
+	}
+	log.Infof("Received %s request for '%s': ID=%s", req.Method, params.URI, req.ID.String())
+
+	if subscribe {
+		s.resourceLock.RLock()
+		_, exists := s.resources[params.URI]
+		s.resourceLock.RUnlock()
+		if !exists {
+			s.writeErrorResponse(w, req.ID, -32002, fmt.Sprintf("Resource not found: %s", params.URI), nil)
+			return
+		}
+	}
+
+	if session := s.lookupSession(SessionIDFromContext(ctx)); session != nil {
+		session.subscriptionLock.Lock()
+		if subscribe {
+			if session.subscriptions == nil {
+				session.subscriptions = make(map[string]bool)
+			}
+			session.subscriptions[params.URI] = true
+		} else {
+			delete(session.subscriptions, params.URI)
+		}
+		session.subscriptionLock.Unlock()
+	}
+	s.writeSuccessResponse(w, req.ID, struct{}{})
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestNotifyResourceUpdated(t *testing.T) {
+	const uri = "file:///status"
+	tests := []struct {
+		name       string
+		inline     bool
+		readErr    error
+		subscribe  []string
+		wantParams []string
+		wantErr    bool
+	}{
+		{"inline contents", true, nil, []string{"resources/subscribe"}, []string{`{"uri":"file:///status","contents":[{"uri":"file:///status","mimeType":"text/plain","text":"ok"}]}`}, false},
+		{"notify only", false, nil, []string{"resources/subscribe"}, []string{`{"uri":"file:///status"}`}, false},
+		{"inline read fails", true, errors.New("disk gone"), []string{"resources/subscribe"}, []string{`{"uri":"file:///status"}`}, true},
+		{"not subscribed", true, nil, nil, nil, false},
+		{"unsubscribed", true, nil, []string{"resources/subscribe", "resources/unsubscribe"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			reads := 0
+			err := s.RegisterResources([]ResourceRegistration{{
+				Definition: protocol.Resource{URI: uri, Name: "status"},
+				Handler: func(ctx context.Context, uri string) ([]protocol.ResourceContents, error) {
+					reads++
+					if tt.readErr != nil {
+						return nil, tt.readErr
+					}
+					return []protocol.ResourceContents{{URI: uri, MIMEType: "text/plain", Text: "ok"}}, nil
+				},
+				InlineUpdates: tt.inline,
+			}})
+			if err != nil {
+				t.Fatalf("RegisterResources: %v", err)
+			}
+			c := mcptest.NewClient(t, s)
+			for _, method := range tt.subscribe {
+				if resp := c.Call(method, protocol.SubscribeRequest{URI: uri}); resp.Error != nil {
+					t.Fatalf("%s: %+v", method, resp.Error)
+				}
+			}
+
+			err = s.NotifyResourceUpdated(context.Background(), uri)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NotifyResourceUpdated error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := queuedParams(t, s, c.SessionID()); !reflect.DeepEqual(got, tt.wantParams) {
+				t.Errorf("notifications = %v, want %v", got, tt.wantParams)
+			}
+			if wantReads := len(tt.wantParams); tt.inline && reads != wantReads {
+				t.Errorf("resource read %d times, want %d", reads, wantReads)
+			}
+		})
+	}
+}
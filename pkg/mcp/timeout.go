@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"time"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// WithToolTimeout bounds how long any tool handler may run. The handler's context is
+// cancelled once the timeout passes, so handlers should watch ctx.Done().
+//
+// A tool's own Timeout and a deadline sent by the client in the request's
+// "_meta.timeout" (in milliseconds) can only shorten this limit: a call runs with the
+// smallest of the three that are set. Zero, the default, sets no server-wide limit.
+func WithToolTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		if timeout >= 0 {
+			s.toolTimeout = timeout
+		}
+	}
+}
+
+// callTimeout returns the time a call to tool may take, or zero if it is unbounded.
+func (s *Server) callTimeout(tool internalRegisteredTool, meta *protocol.RequestMeta) time.Duration {
+	timeout := s.toolTimeout
+	tighten := func(limit time.Duration) {
+		if limit > 0 && (timeout == 0 || limit < timeout) {
+			timeout = limit
+		}
+	}
+	tighten(tool.timeout)
+	if meta != nil && meta.Timeout > 0 {
+		tighten(time.Duration(meta.Timeout) * time.Millisecond)
+	}
+	return timeout
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestCallTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		serverLimit  time.Duration
+		toolLimit    time.Duration
+		clientMillis int64
+		want         time.Duration
+	}{
+		{"unbounded", 0, 0, 0, 0},
+		{"client only", 0, 0, 1500, 1500 * time.Millisecond},
+		{"server only", time.Minute, 0, 0, time.Minute},
+		{"client shortens server", time.Minute, 0, 2000, 2 * time.Second},
+		{"client cannot extend server", time.Second, 0, 5000, time.Second},
+		{"tool shortest", time.Minute, 100 * time.Millisecond, 500, 100 * time.Millisecond},
+		{"client shortest", time.Minute, time.Second, 250, 250 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, WithToolTimeout(tt.serverLimit))
+			tool := internalRegisteredTool{timeout: tt.toolLimit}
+			var meta *protocol.RequestMeta
+			if tt.clientMillis != 0 {
+				meta = &protocol.RequestMeta{Timeout: tt.clientMillis}
+			}
+			if got := s.callTimeout(tool, meta); got != tt.want {
+				t.Errorf("callTimeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientSuppliedDeadline(t *testing.T) {
+	tests := []struct {
+		name string
+		meta string
+		// wantDeadline is the longest the handler may be given; zero means no deadline.
+		wantDeadline time.Duration
+		wantExpired  bool
+	}{
+		{"no deadline", ``, 0, false},
+		{"generous deadline", `,"_meta":{"timeout":5000}`, 5 * time.Second, false},
+		{"expired deadline", `,"_meta":{"timeout":20}`, 20 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "wait", Description: "Waits for its deadline, if it is short."},
+				Handler: func(ctx context.Context, in *echoInput) (string, error) {
+					deadline, ok := ctx.Deadline()
+					if !ok {
+						return "none", nil
+					}
+					if time.Until(deadline) < time.Second {
+						<-ctx.Done()
+						return "", ctx.Err()
+					}
+					return fmt.Sprint(time.Until(deadline) <= tt.wantDeadline), nil
+				},
+			}})
+			resp := mcptest.NewClient(t, s).Call("tools/call", json.RawMessage(`{"name":"wait","arguments":{"value":""}`+tt.meta+`}`))
+			if resp.Error != nil {
+				t.Fatalf("tools/call failed: %d %s", resp.Error.Code, resp.Error.Message)
+			}
+			var result protocol.CallToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatalf("decoding result: %v", err)
+			}
+			want := "true"
+			switch {
+			case tt.wantExpired:
+				want = context.DeadlineExceeded.Error()
+			case tt.wantDeadline == 0:
+				want = "none"
+			}
+			if got := textOf(t, &result); got != want {
+				t.Errorf("handler answered %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -2,8 +2,14 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"go-mcp-sdk/internal/jsonschema"
 	"go-mcp-sdk/pkg/protocol"
@@ -11,53 +17,518 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// semverPattern matches semantic versions such as "1.0.0", "2.1.0-beta.1" or "1.0.0+build.5".
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// reservedMethodNames are MCP protocol methods. Tools may not take these names, since
+// some clients treat tool names and method names as one namespace.
+var reservedMethodNames = map[string]bool{
+	"initialize":               true,
+	"ping":                     true,
+	"tools/list":               true,
+	"tools/call":               true,
+	"tools/get":                true,
+	"resources/list":           true,
+	"resources/read":           true,
+	"resources/templates/list": true,
+	"resources/subscribe":      true,
+	"resources/unsubscribe":    true,
+	"prompts/list":             true,
+	"prompts/get":              true,
+	"logging/setLevel":         true,
+	"completion/complete":      true,
+	"sampling/createMessage":   true,
+	"roots/list":               true,
+	"elicitation/create":       true,
+}
+
+// reservedMethodPrefix starts the name of every protocol notification.
+const reservedMethodPrefix = "notifications/"
+
+// isReservedToolName reports whether name collides with a protocol method.
+func isReservedToolName(name string) bool {
+	return reservedMethodNames[name] || strings.HasPrefix(name, reservedMethodPrefix)
+}
+
+// errorType is the required type of a handler's last return value.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// jsonUnmarshalerType is used to accept non-struct input types that decode themselves.
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// readerType marks handlers whose text result is streamed from an io.Reader.
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+// VisibilityFunc reports whether a tool should be exposed to the caller of the current request.
+type VisibilityFunc func(ctx context.Context) bool
+
 // ToolRegistration is a struct to define and register their tools.
 type ToolRegistration struct {
 	Definition protocol.Tool
 	// Handler is the strongly-typed function that implements the tool.
+	// It takes an optional context.Context and its input struct, by value or by pointer
+	// (or a pointer to a type implementing json.Unmarshaler), and returns one of:
+	//   - error
+	//   - (T, error), where T is formatted into a text content block
+	//   - (R, error), where R implements io.Reader: its contents become the text content
+	//     block and are streamed into the response rather than held in memory where
+	//     possible; R is closed afterwards if it implements io.Closer
+	//   - (string, S, error), where the string becomes a text content block and S,
+	//     a map with string keys or a struct, becomes the result's structuredContent
+	//   - (*protocol.CallToolResult, error), where the result (see NewResult) is sent
+	//     as built; a non-nil error marks it as an error and appends the error's message,
+	//     so a tool can report partial output together with a failure
+	//
+	// A string field of the input tagged `pattern:"^[A-Z]{3}$"` is advertised with that
+	// pattern, and calls whose value does not match it are rejected before Validate runs.
 	Handler interface{}
+	// Version is an optional semantic version (e.g. "1.2.0") advertised in tools/list.
+	Version string
+	// Validate, if set, receives a pointer to the decoded input (the same pointer passed to
+	// Handler, or one to the struct it is given by value) before the handler runs.
+	// A non-nil error rejects the call with an invalid params error.
+	Validate func(input interface{}) error
+	// Visible, if set, decides per request whether the tool is listed and callable.
+	// The context carries whatever the HTTP request's context carries (e.g. auth claims
+	// added by middleware) plus the session id.
+	Visible VisibilityFunc
+	// MaxInputBytes caps the size of the serialized arguments accepted by this tool.
+	// Zero means no per-tool limit.
+	MaxInputBytes int64
+	// Cacheable marks the tool as deterministic: identical arguments always give the same
+	// result, so results may be served from the cache configured with WithResultCache.
+	Cacheable bool
+	// Timeout bounds how long the handler may run; its context is cancelled once it
+	// passes. Zero means no per-tool limit. See WithToolTimeout.
+	Timeout time.Duration
+	// Descriptions documents arguments without 'description' tags, or overrides them,
+	// keyed by argument name; nested fields are named by path, e.g. "address.city".
+	// It requires the built-in schema generator, and naming an argument the input
+	// struct does not have is an error.
+	Descriptions map[string]string
+	// MaxConcurrent, if positive, bounds how many calls to this tool run at once, for
+	// tools wrapping something that cannot be used in parallel, such as a serial device.
+	// It applies in addition to WithMaxConcurrency. A tool supplied by a ToolProvider
+	// keeps its limit while the provider returns the same registration for it.
+	MaxConcurrent int
+	// MaxQueued is how many calls over MaxConcurrent may wait for a turn (or for their
+	// request to be cancelled); further calls are rejected as busy. Zero rejects every
+	// call over the limit at once.
+	MaxQueued int
+	// Localized gives the tool's title and description in other languages, keyed by
+	// language tag such as "fr" or "pt-BR". tools/list and tools/get answer in the
+	// language that best matches the request's Accept-Language header, or else the
+	// locale the client gave during initialize, and fall back to the definition.
+	Localized map[string]LocalizedText
+	// SelfTest, if set, is run by Server.Validate to check that the tool can work,
+	// for example that a service it depends on is reachable. It is bounded by the
+	// tool's timeout, if any.
+	SelfTest func(ctx context.Context) error
 }
 
 // internalRegisteredTool stores the processed, ready-to-use tool information.
 // This is not exposed to the user of the SDK.
 type internalRegisteredTool struct {
-	Definition   protocol.Tool
-	handlerValue reflect.Value
-	inputType    reflect.Type
-	takesContext bool
+	Definition    protocol.Tool
+	handlerValue  reflect.Value
+	inputType     reflect.Type
+	takesContext  bool
+	maxInputBytes int64
+	validate      func(input interface{}) error
+	visible       VisibilityFunc
+	// aliases maps old or deprecated argument names to how they are handled.
+	aliases   map[string]argumentAlias
+	cacheable bool
+	timeout   time.Duration
+	// returnsReader is set for (io.Reader, error) handlers.
+	returnsReader bool
+	// inputByValue is set when the handler takes its input struct by value rather
+	// than through a pointer; inputType is a pointer either way.
+	inputByValue bool
+	// required lists the arguments the input schema marks as required.
+	required []string
+	// selfTest is the registration's SelfTest, run by Validate.
+	selfTest func(ctx context.Context) error
+	// limiter bounds concurrent calls to this tool; nil means unlimited.
+	limiter *executionLimiter
+	// origin is where lookupTool found the tool; it is not set in the registries.
+	origin toolOrigin
+	// localized holds the registration's Localized texts keyed by lower-case tag.
+	localized map[string]LocalizedText
+	// patterns holds the compiled 'pattern' tags of the input type, checked on every call.
+	patterns map[string]*regexp.Regexp
+	// renameArgs is set when argument names must be mapped back to Go field
+	// names before decoding; see WithFieldNaming.
+	renameArgs bool
+	// dynamic is set for tools registered with RegisterDynamicTools, whose shared
+	// handler is also passed the name of the tool being called.
+	dynamic bool
+}
+
+// argumentAlias describes an argument name that is accepted with a deprecation warning.
+type argumentAlias struct {
+	// name is the argument the value is decoded into.
+	name string
+	// warning is reported back to the client when the argument is used.
+	warning string
 }
 
 // RegisterTools registers a slice of tools, making them available to clients.
 // This is the primary method for adding functionality to the server.
+// The tools are registered all together, or not at all if any of them is invalid or
+// has a name that is already taken.
 func (s *Server) RegisterTools(registrations []ToolRegistration) error {
+	tools := make([]internalRegisteredTool, 0, len(registrations))
 	for _, reg := range registrations {
-		if err := s.registerSingleTool(reg); err != nil {
-			// Return on the first error to ensure atomicity.
+		tool, err := s.buildTool(reg)
+		if err == nil {
+			err = s.checkDescription(tool.Definition)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to register tool '%s': %w", reg.Definition.Name, err)
 		}
+		tools = append(tools, tool)
+	}
+	if err := s.installTools(tools); err != nil {
+		return err
+	}
+	for _, tool := range tools {
+		log.Infof("Registered tool: %s", tool.Definition.Name)
+	}
+	if len(tools) > 0 {
+		s.notifyListChanged(listTools)
+	}
+	return nil
+}
+
+// installTools adds built tools to the registry in one step. Nothing is added if a name
+// is taken, whether by a registered tool or by another tool in the batch, or if the
+// batch would exceed the tool limit.
+func (s *Server) installTools(tools []internalRegisteredTool) error {
+	s.toolLock.Lock()
+	defer s.toolLock.Unlock()
+	names := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		name := tool.Definition.Name
+		if _, exists := s.tools[name]; exists || names[name] {
+			return fmt.Errorf("failed to register tool '%s': tool with name '%s' already registered", name, name)
+		}
+		names[name] = true
+	}
+	if s.maxTools > 0 && len(s.tools)+len(tools) > s.maxTools {
+		return fmt.Errorf("registering %d tools would exceed the tool limit of %d", len(tools), s.maxTools)
+	}
+	for _, tool := range tools {
+		s.tools[tool.Definition.Name] = tool
 	}
 	return nil
 }
 
-// registerSingleTool is the internal helper that processes one registration.
-func (s *Server) registerSingleTool(reg ToolRegistration) error {
+// UpdateToolDefinition atomically replaces the definition of a registered tool while keeping
+// its handler. Fields def leaves empty keep their current values: the title, the
+// description, the input and output schemas, and the version. The tool's name cannot be
+// changed, and the new definition is checked for a description as at registration.
+//
+// Localized texts of a title or description that def changes are dropped, as they would
+// translate the old text; clients are served the new text until the tool is registered
+// again with translations of it. Arguments are still decoded into the handler's input
+// type, so the 'pattern' tags of its fields remain enforced whatever the new input schema
+// says.
+func (s *Server) UpdateToolDefinition(name string, def protocol.Tool) error {
+	if def.Name != "" && def.Name != name {
+		return fmt.Errorf("cannot rename tool '%s' to '%s'", name, def.Name)
+	}
+	if len(def.InputSchema) > 0 && !json.Valid(def.InputSchema) {
+		return fmt.Errorf("input schema for tool '%s' is not valid JSON", name)
+	}
+	if len(def.OutputSchema) > 0 && !json.Valid(def.OutputSchema) {
+		return fmt.Errorf("output schema for tool '%s' is not valid JSON", name)
+	}
+	def.Name = name
+
+	s.toolLock.Lock()
+	tool, exists := s.tools[name]
+	if !exists {
+		s.toolLock.Unlock()
+		return fmt.Errorf("tool '%s' is not registered", name)
+	}
+	if len(def.InputSchema) == 0 {
+		def.InputSchema = tool.Definition.InputSchema
+	} else {
+		tool.required = requiredArguments(def.InputSchema)
+	}
+	if len(def.OutputSchema) == 0 {
+		def.OutputSchema = tool.Definition.OutputSchema
+	}
+	if def.Title == "" {
+		def.Title = tool.Definition.Title
+	}
+	if def.Description == "" {
+		def.Description = tool.Definition.Description
+	}
+	if def.Version == "" {
+		def.Version = tool.Definition.Version
+	}
+	if err := s.checkDescription(def); err != nil {
+		s.toolLock.Unlock()
+		return fmt.Errorf("failed to update tool '%s': %w", name, err)
+	}
+	tool.localized = staleLocalizedRemoved(tool.localized, tool.Definition, def)
+	tool.Definition = def
+	s.tools[name] = tool
+	s.toolLock.Unlock()
+
+	log.Infof("Updated definition of tool: %s", name)
+	s.notifyListChanged(listTools)
+	return nil
+}
+
+// ToolSchema returns the input schema advertised for a registered tool, for example to
+// generate documentation or client stubs. The returned bytes are a copy and may be
+// modified freely. It reports false if no tool with that name is registered.
+func (s *Server) ToolSchema(name string) (json.RawMessage, bool) {
+	s.toolLock.RLock()
+	defer s.toolLock.RUnlock()
+	tool, exists := s.tools[name]
+	if !exists {
+		return nil, false
+	}
+	return append(json.RawMessage(nil), tool.Definition.InputSchema...), true
+}
+
+// checkDescription warns about a tool without a description, since models decide which
+// tool to call from its description, or rejects it under WithStrictToolValidation.
+func (s *Server) checkDescription(def protocol.Tool) error {
+	if strings.TrimSpace(def.Description) != "" {
+		return nil
+	}
+	if s.strictToolValidation {
+		return fmt.Errorf("tool definition must include a description")
+	}
+	log.Warnf("Tool '%s' has no description, which leaves clients to guess what it does", def.Name)
+	return nil
+}
+
+// buildTool validates a registration and prepares its schemas and handler for dispatch.
+func (s *Server) buildTool(reg ToolRegistration) (internalRegisteredTool, error) {
 	toolDef := reg.Definition
 	handlerFn := reg.Handler
 
 	if toolDef.Name == "" {
-		return fmt.Errorf("tool definition must include a name")
+		return internalRegisteredTool{}, fmt.Errorf("tool definition must include a name")
+	}
+	if isReservedToolName(toolDef.Name) {
+		return internalRegisteredTool{}, fmt.Errorf("tool name '%s' is reserved for a protocol method", toolDef.Name)
+	}
+	if reg.Version != "" {
+		if !semverPattern.MatchString(reg.Version) {
+			return internalRegisteredTool{}, fmt.Errorf("tool version '%s' is not a valid semantic version", reg.Version)
+		}
+		toolDef.Version = reg.Version
+	}
+	if reg.Timeout < 0 {
+		return internalRegisteredTool{}, fmt.Errorf("timeout must not be negative")
+	}
+	if reg.MaxInputBytes < 0 {
+		return internalRegisteredTool{}, fmt.Errorf("max input bytes must not be negative")
+	}
+	if reg.MaxConcurrent < 0 || reg.MaxQueued < 0 {
+		return internalRegisteredTool{}, fmt.Errorf("concurrency limits must not be negative")
 	}
 
 	handlerVal := reflect.ValueOf(handlerFn)
+	inputType, takesContext, err := inspectHandler(handlerVal)
+	if err != nil {
+		return internalRegisteredTool{}, err
+	}
+	// A struct taken by value is decoded through a pointer like any other input.
+	inputByValue := inputType.Kind() == reflect.Struct
+	if inputByValue {
+		inputType = reflect.PointerTo(inputType)
+	}
+
+	aliases, err := argumentAliases(inputType, s.fieldNaming.schemaNaming())
+	if err != nil {
+		return internalRegisteredTool{}, err
+	}
+	patterns, err := inputPatterns(inputType)
+	if err != nil {
+		return internalRegisteredTool{}, err
+	}
+	localized, err := normalizeLocalized(reg.Localized)
+	if err != nil {
+		return internalRegisteredTool{}, err
+	}
+
+	// Generate schema from the input type. Output schemas keep Go field names for
+	// untagged fields, since that is how encoding/json writes them.
+	generator := s.schemaGenerator
+	if generator == nil {
+		options := s.schemaOptions
+		options.Descriptions = reg.Descriptions
+		options.FieldNaming = s.fieldNaming.schemaNaming()
+		generator = defaultSchemaGenerator{options: options}
+	} else if len(reg.Descriptions) > 0 {
+		return internalRegisteredTool{}, fmt.Errorf("descriptions cannot be applied with a custom schema generator")
+	}
+	inputSchema, err := generator.Generate(inputType)
+	if err != nil {
+		return internalRegisteredTool{}, fmt.Errorf("could not generate schema for type %s: %w", inputType, err)
+	}
+	toolDef.InputSchema = inputSchema
+
+	// A struct returned as structured content gets an output schema, unless one was declared.
+	if len(toolDef.OutputSchema) == 0 {
+		if structuredType := structuredResultType(handlerVal.Type()); structuredType != nil {
+			outputSchema, err := s.schemaGeneratorOrDefault().Generate(structuredType)
+			if err != nil {
+				return internalRegisteredTool{}, fmt.Errorf("could not generate output schema for type %s: %w", structuredType, err)
+			}
+			toolDef.OutputSchema = outputSchema
+		}
+	} else if !json.Valid(toolDef.OutputSchema) {
+		return internalRegisteredTool{}, fmt.Errorf("output schema is not valid JSON")
+	}
+
+	return internalRegisteredTool{
+		Definition:    toolDef,
+		handlerValue:  handlerVal,
+		inputType:     inputType,
+		inputByValue:  inputByValue,
+		required:      requiredArguments(toolDef.InputSchema),
+		selfTest:      reg.SelfTest,
+		renameArgs:    needsArgumentRenames(inputType, s.fieldNaming.schemaNaming()),
+		limiter:       newExecutionLimiter(reg.MaxConcurrent, reg.MaxQueued, errToolBusy),
+		patterns:      patterns,
+		localized:     localized,
+		takesContext:  takesContext,
+		maxInputBytes: reg.MaxInputBytes,
+		validate:      reg.Validate,
+		visible:       reg.Visible,
+		aliases:       aliases,
+		cacheable:     reg.Cacheable,
+		timeout:       reg.Timeout,
+		returnsReader: handlerVal.Type().NumOut() == 2 && handlerVal.Type().Out(0).Implements(readerType),
+	}, nil
+}
+
+// argumentAliases collects the deprecated argument names of a struct input type.
+// A field tagged `alias:"oldName"` also accepts "oldName"; a field tagged
+// `deprecated:"reason"` is accepted as usual but reported as deprecated.
+func argumentAliases(t reflect.Type, naming jsonschema.FieldNaming) (map[string]argumentAlias, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, ok := jsonschema.FieldName(t.Field(i), naming); ok {
+			names[name] = true
+		}
+	}
+
+	aliases := make(map[string]argumentAlias)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, ok := jsonschema.FieldName(field, naming)
+		if !ok {
+			continue
+		}
+		deprecation := field.Tag.Get("deprecated")
+		if deprecation != "" {
+			aliases[name] = argumentAlias{name: name, warning: fmt.Sprintf("argument '%s' is deprecated: %s", name, deprecation)}
+		}
+		for _, alias := range jsonschema.ParseAliasTag(field.Tag.Get("alias")) {
+			if names[alias] {
+				return nil, fmt.Errorf("alias '%s' of argument '%s' clashes with another argument", alias, name)
+			}
+			if _, exists := aliases[alias]; exists {
+				return nil, fmt.Errorf("alias '%s' is used by more than one argument", alias)
+			}
+			aliases[alias] = argumentAlias{name: name, warning: fmt.Sprintf("argument '%s' is deprecated: use '%s' instead", alias, name)}
+		}
+	}
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+	return aliases, nil
+}
+
+// resolveAliases rewrites deprecated argument names in an arguments object to the names
+// the input struct expects. It returns the rewritten arguments and a warning for each
+// deprecated name used.
+func resolveAliases(args json.RawMessage, aliases map[string]argumentAlias) (json.RawMessage, []string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(args, &fields); err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	renamed := false
+	for key, value := range fields {
+		alias, ok := aliases[key]
+		if !ok {
+			continue
+		}
+		warnings = append(warnings, alias.warning)
+		if alias.name == key {
+			continue
+		}
+		if _, exists := fields[alias.name]; exists {
+			return nil, nil, fmt.Errorf("arguments '%s' and '%s' cannot both be given", key, alias.name)
+		}
+		fields[alias.name] = value
+		delete(fields, key)
+		renamed = true
+	}
+	sort.Strings(warnings)
+	if !renamed {
+		return args, warnings, nil
+	}
+	rewritten, err := json.Marshal(fields)
+	return rewritten, warnings, err
+}
+
+// positionalToNamed maps positional arguments onto the argument names of a struct's
+// fields, following the order in which the fields are declared.
+func positionalToNamed(t reflect.Type, args []interface{}, naming jsonschema.FieldNaming) (map[string]interface{}, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("positional arguments require a struct input type, but got %s", t)
+	}
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, ok := jsonschema.FieldName(t.Field(i), naming); ok {
+			names = append(names, name)
+		}
+	}
+
+	if len(args) > len(names) {
+		return nil, fmt.Errorf("too many positional arguments (expected at most %d, got %d)", len(names), len(args))
+	}
+
+	named := make(map[string]interface{}, len(args))
+	for i, arg := range args {
+		named[names[i]] = arg
+	}
+	return named, nil
+}
+
+// inspectHandler validates a handler's signature and returns its input type and
+// whether it takes a context as its first argument. The input type is a pointer, or a
+// struct for handlers that take their input by value.
+func inspectHandler(handlerVal reflect.Value) (reflect.Type, bool, error) {
+	if !handlerVal.IsValid() {
+		return nil, false, fmt.Errorf("handler must not be nil")
+	}
 	handlerType := handlerVal.Type()
 	if handlerType.Kind() != reflect.Func {
-		return fmt.Errorf("handler must be a function")
+		return nil, false, fmt.Errorf("handler must be a function")
 	}
 
-	// Validate handler signature and extract input type
-	var inputType reflect.Type
 	var takesContext bool
-
 	numIn := handlerType.NumIn()
 	if numIn > 0 && handlerType.In(0).Implements(reflect.TypeOf((*context.Context)(nil)).Elem()) {
 		takesContext = true
@@ -68,37 +539,151 @@ func (s *Server) registerSingleTool(reg ToolRegistration) error {
 		expectedArgCount = 2
 	}
 	if numIn != expectedArgCount {
-		return fmt.Errorf("handler has incorrect number of arguments (expected %d, got %d)", expectedArgCount, numIn)
+		return nil, false, fmt.Errorf("handler has incorrect number of arguments (expected %d, got %d)", expectedArgCount, numIn)
 	}
 
 	// The input type is the last argument.
-	inputType = handlerType.In(numIn - 1)
-	if inputType.Kind() != reflect.Ptr || inputType.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("handler's parameter type must be a pointer to a struct, but got %s", inputType)
+	inputType := handlerType.In(numIn - 1)
+	// Structs are decoded field by field, and may be taken by value; any other type
+	// must decode itself.
+	if inputType.Kind() != reflect.Struct && (inputType.Kind() != reflect.Ptr || (inputType.Elem().Kind() != reflect.Struct && !inputType.Implements(jsonUnmarshalerType))) {
+		return nil, false, fmt.Errorf("handler's parameter type must be a struct, a pointer to a struct or a pointer to a type implementing json.Unmarshaler, but got %s", inputType)
 	}
 
-	// Generate schema from the input type
-	inputSchema, err := jsonschema.GenerateSchemaForType(inputType)
-	if err != nil {
-		return fmt.Errorf("could not generate schema for type %s: %w", inputType, err)
+	if err := validateHandlerResults(handlerType); err != nil {
+		return nil, false, err
 	}
-	toolDef.InputSchema = inputSchema
+	return inputType, takesContext, nil
+}
 
-	// Store the processed tool
-	s.toolLock.Lock()
-	defer s.toolLock.Unlock()
+// validateHandlerResults checks that a handler returns one of the supported result shapes.
+func validateHandlerResults(handlerType reflect.Type) error {
+	numOut := handlerType.NumOut()
+	if numOut < 1 || numOut > 3 {
+		return fmt.Errorf("handler must return between 1 and 3 values (got %d)", numOut)
+	}
+	if handlerType.Out(numOut-1) != errorType {
+		return fmt.Errorf("handler's last return value must be error, but got %s", handlerType.Out(numOut-1))
+	}
+	if numOut == 3 {
+		if handlerType.Out(0).Kind() != reflect.String {
+			return fmt.Errorf("handler returning three values must return a string first, but got %s", handlerType.Out(0))
+		}
+		structured := handlerType.Out(1)
+		if structured.Kind() == reflect.Ptr {
+			structured = structured.Elem()
+		}
+		isStringMap := structured.Kind() == reflect.Map && structured.Key().Kind() == reflect.String
+		if !isStringMap && structured.Kind() != reflect.Struct {
+			return fmt.Errorf("handler's structured return value must be a map with string keys or a struct, but got %s", handlerType.Out(1))
+		}
+	}
+	return nil
+}
 
-	if _, exists := s.tools[toolDef.Name]; exists {
-		return fmt.Errorf("tool with name '%s' already registered", toolDef.Name)
+// buildCallToolResult converts a handler's return values into the result sent to the client.
+// A handler that returns only an error has no output: on success its result has no
+// content, unless emptyText is set, in which case that text is its only block.
+func buildCallToolResult(results []reflect.Value, resultErr error, emptyText string) *protocol.CallToolResult {
+	// The value is checked rather than the declared type, so that handlers returning
+	// interface{}, such as dynamic ones, can build their result too.
+	if len(results) == 2 {
+		if built, ok := results[0].Interface().(*protocol.CallToolResult); ok {
+			if built == nil {
+				built = &protocol.CallToolResult{}
+			}
+			return builtToolResult(built, resultErr)
+		}
+	}
+	if resultErr != nil {
+		return &protocol.CallToolResult{
+			Content: []protocol.ContentBlock{{Type: "text", Text: resultErr.Error()}},
+			IsError: true,
+		}
 	}
 
-	s.tools[toolDef.Name] = internalRegisteredTool{
-		Definition:   toolDef,
-		handlerValue: handlerVal,
-		inputType:    inputType,
-		takesContext: takesContext,
+	result := &protocol.CallToolResult{Content: []protocol.ContentBlock{}}
+	if len(results) > 1 {
+		result.Content = append(result.Content, protocol.ContentBlock{Type: "text", Text: formatResultText(results[0].Interface())})
+	} else if emptyText != "" {
+		result.Content = append(result.Content, protocol.ContentBlock{Type: "text", Text: emptyText})
+	}
+	if len(results) == 3 {
+		structured := results[1]
+		if (structured.Kind() != reflect.Map && structured.Kind() != reflect.Ptr) || !structured.IsNil() {
+			if _, err := json.Marshal(structured.Interface()); err != nil {
+				log.Warnf("Dropping structured content of type %s: %v", structured.Type(), err)
+			} else {
+				result.StructuredContent = structured.Interface()
+			}
+		}
 	}
+	return result
+}
 
-	log.Infof("Registered tool: %s", toolDef.Name)
-	return nil
-}
\ No newline at end of file
+// builtToolResult returns a copy of a result built by a handler. If the handler also
+// returned an error, the result is marked as an error and the message is appended
+// after whatever partial output it holds.
+func builtToolResult(built *protocol.CallToolResult, resultErr error) *protocol.CallToolResult {
+	result := *built
+	result.Content = append([]protocol.ContentBlock{}, built.Content...)
+	if resultErr != nil {
+		result.IsError = true
+		result.Content = append(result.Content, protocol.ContentBlock{Type: "text", Text: resultErr.Error()})
+	}
+	return &result
+}
+
+// formatResultText renders a handler's return value as text. Strings and fmt.Stringers
+// are used as-is; anything else is JSON-encoded. Values that cannot be encoded (for
+// example, ones containing channels or funcs) fall back to Go formatting with a warning,
+// so a bad value never corrupts the response.
+func formatResultText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return fmt.Sprint(v)
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		log.Warnf("Tool result of type %T is not JSON-serializable, falling back to text: %v", value, err)
+		return fmt.Sprintf("%+v", value)
+	}
+	return string(encoded)
+}
+
+// structuredResultType returns the struct type a three-value handler returns as
+// structured content, or nil if it returns a map or fewer values.
+func structuredResultType(handlerType reflect.Type) reflect.Type {
+	if handlerType.NumOut() != 3 {
+		return nil
+	}
+	structured := handlerType.Out(1)
+	if structured.Kind() == reflect.Ptr {
+		structured = structured.Elem()
+	}
+	if structured.Kind() != reflect.Struct {
+		return nil
+	}
+	return structured
+}
+
+// validateStructuredContent checks a result's structured content against the tool's output schema.
+// Results without structured content, or tools without an output schema, always pass.
+func validateStructuredContent(def protocol.Tool, result *protocol.CallToolResult) error {
+	if len(def.OutputSchema) == 0 || result.IsError || result.StructuredContent == nil {
+		return nil
+	}
+	structuredBytes, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return fmt.Errorf("could not encode structured content: %w", err)
+	}
+	return jsonschema.Validate(def.OutputSchema, structuredBytes)
+}
+
+// visibleTo reports whether the tool is exposed to the caller of ctx.
+func (t internalRegisteredTool) visibleTo(ctx context.Context) bool {
+	return t.visible == nil || t.visible(ctx)
+}
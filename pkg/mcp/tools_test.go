@@ -0,0 +1,777 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+type codeInput struct {
+	Code string `json:"code" pattern:"^[A-Z]{3}$"`
+}
+
+type codeOutput struct {
+	Valid bool `json:"valid"`
+}
+
+func TestUpdateToolDefinition(t *testing.T) {
+	newSchema := json.RawMessage(`{"type":"object","properties":{"code":{"type":"string"}}}`)
+	newOutput := json.RawMessage(`{"type":"object","properties":{"valid":{"type":"boolean"},"reason":{"type":"string"}}}`)
+	tests := []struct {
+		name string
+		def  protocol.Tool
+		// want checks the advertised definition against the original one.
+		want func(t *testing.T, before, after protocol.Tool)
+		// wantGerman is the description served to German clients after the update.
+		wantGerman string
+	}{
+		{
+			name: "description only",
+			def:  protocol.Tool{Description: "Checks a currency code."},
+			want: func(t *testing.T, before, after protocol.Tool) {
+				if after.Title != before.Title || string(after.InputSchema) != string(before.InputSchema) ||
+					string(after.OutputSchema) != string(before.OutputSchema) || after.Version != before.Version {
+					t.Errorf("fields left empty were not kept: %+v", after)
+				}
+				if after.Description != "Checks a currency code." {
+					t.Errorf("Description = %q", after.Description)
+				}
+			},
+			wantGerman: "Checks a currency code.",
+		},
+		{
+			name: "same description, new title",
+			def:  protocol.Tool{Title: "Currency Check", Description: "Checks a code."},
+			want: func(t *testing.T, before, after protocol.Tool) {
+				if after.Title != "Currency Check" {
+					t.Errorf("Title = %q", after.Title)
+				}
+			},
+			wantGerman: "Prüft einen Code.",
+		},
+		{
+			name: "title only",
+			def:  protocol.Tool{Title: "Currency Check"},
+			want: func(t *testing.T, before, after protocol.Tool) {
+				if after.Description != before.Description {
+					t.Errorf("Description = %q, want the unchanged %q", after.Description, before.Description)
+				}
+			},
+			wantGerman: "Prüft einen Code.",
+		},
+		{
+			name: "new schemas",
+			def:  protocol.Tool{Description: "Checks a code.", InputSchema: newSchema, OutputSchema: newOutput},
+			want: func(t *testing.T, before, after protocol.Tool) {
+				if string(after.InputSchema) != string(newSchema) || string(after.OutputSchema) != string(newOutput) {
+					t.Errorf("schemas not replaced: %s %s", after.InputSchema, after.OutputSchema)
+				}
+				if after.Title != before.Title {
+					t.Errorf("Title = %q, want %q", after.Title, before.Title)
+				}
+			},
+			wantGerman: "Prüft einen Code.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := TypedTool("check", func(ctx context.Context, in *codeInput) (codeOutput, error) {
+				return codeOutput{Valid: true}, nil
+			}, WithDescription("Checks a code."))
+			reg.Definition.Title = "Code Check"
+			reg.Version = "1.0.0"
+			reg.Localized = map[string]LocalizedText{"de": {Title: "Codeprüfung", Description: "Prüft einen Code."}}
+			s := newTestServer(t, []ToolRegistration{reg})
+			before, _ := s.lookupTool(context.Background(), "check")
+			if len(before.Definition.OutputSchema) == 0 {
+				t.Fatal("typed tool has no output schema to keep")
+			}
+
+			if err := s.UpdateToolDefinition("check", tt.def); err != nil {
+				t.Fatalf("UpdateToolDefinition: %v", err)
+			}
+			after, _ := s.lookupTool(context.Background(), "check")
+			tt.want(t, before.Definition, after.Definition)
+			if got := after.definitionFor([]string{"de"}).Description; got != tt.wantGerman {
+				t.Errorf("German description = %q, want %q", got, tt.wantGerman)
+			}
+			if got := after.definitionFor([]string{"de"}).Title; got != "Codeprüfung" && tt.def.Title == "" {
+				t.Errorf("German title = %q, want the unchanged translation", got)
+			}
+
+			c := mcptest.NewClient(t, s)
+			if resp := c.Call("tools/call", map[string]interface{}{"name": "check", "arguments": map[string]string{"code": "usd"}}); resp.Error == nil {
+				t.Error("argument not matching the input type's pattern was accepted after the update")
+			}
+		})
+	}
+}
+
+func TestUpdateToolDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		def  protocol.Tool
+	}{
+		{"unknown tool", "missing", protocol.Tool{Description: "x"}},
+		{"rename", "check", protocol.Tool{Name: "other"}},
+		{"invalid input schema", "check", protocol.Tool{InputSchema: json.RawMessage(`{`)}},
+		{"invalid output schema", "check", protocol.Tool{OutputSchema: json.RawMessage(`{`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{
+				Tool("check", func(ctx context.Context, in *codeInput) (string, error) { return "", nil }, WithDescription("Checks a code.")),
+			})
+			if err := s.UpdateToolDefinition(tt.tool, tt.def); err == nil {
+				t.Error("UpdateToolDefinition succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestRegisterToolsIsAtomic(t *testing.T) {
+	echo := func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil }
+	valid := ToolRegistration{Definition: protocol.Tool{Name: "first", Description: "Echoes."}, Handler: echo}
+	tests := []struct {
+		name    string
+		opts    []ServerOption
+		batch   []ToolRegistration
+		wantErr string
+	}{
+		{"invalid handler", nil, []ToolRegistration{valid, {Definition: protocol.Tool{Name: "second"}, Handler: "not a function"}}, "second"},
+		{"listed twice", nil, []ToolRegistration{valid, valid}, "already registered"},
+		{"name taken", nil, []ToolRegistration{valid, {Definition: protocol.Tool{Name: "existing", Description: "Echoes."}, Handler: echo}}, "already registered"},
+		{"over the limit", []ServerOption{WithMaxTools(2)}, []ToolRegistration{valid, {Definition: protocol.Tool{Name: "second", Description: "Echoes."}, Handler: echo}}, "tool limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{
+				{Definition: protocol.Tool{Name: "existing", Description: "Echoes."}, Handler: echo},
+			}, tt.opts...)
+			sessionID := mcptest.NewClient(t, s).SessionID()
+
+			err := s.RegisterTools(tt.batch)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("RegisterTools error = %v, want %q", err, tt.wantErr)
+			}
+			if _, exists := s.lookupTool(context.Background(), "first"); exists {
+				t.Error("tool 'first' was registered from a failed batch")
+			}
+			if got := queuedNotifications(t, s, sessionID); len(got) != 0 {
+				t.Errorf("notifications after a failed batch = %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestRegisterToolsNotifiesOnce(t *testing.T) {
+	echo := func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil }
+	s := newTestServer(t, nil)
+	sessionID := mcptest.NewClient(t, s).SessionID()
+	err := s.RegisterTools([]ToolRegistration{
+		{Definition: protocol.Tool{Name: "a", Description: "Echoes."}, Handler: echo},
+		{Definition: protocol.Tool{Name: "b", Description: "Echoes."}, Handler: echo},
+	})
+	if err != nil {
+		t.Fatalf("RegisterTools: %v", err)
+	}
+	want := []string{"notifications/tools/list_changed"}
+	if got := queuedNotifications(t, s, sessionID); !reflect.DeepEqual(got, want) {
+		t.Errorf("notifications = %v, want %v", got, want)
+	}
+}
+
+// unitInput records that its own UnmarshalJSON ran.
+type unitInput struct {
+	Celsius float64 `json:"celsius"`
+	decoded bool
+}
+
+func (u *unitInput) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Fahrenheit *float64 `json:"fahrenheit"`
+		Celsius    float64  `json:"celsius"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	u.Celsius, u.decoded = raw.Celsius, true
+	if raw.Fahrenheit != nil {
+		u.Celsius = (*raw.Fahrenheit - 32) * 5 / 9
+	}
+	return nil
+}
+
+// wordsInput is a non-struct input type that decodes itself from {"text": "..."}.
+type wordsInput []string
+
+func (w *wordsInput) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*w = strings.Fields(raw.Text)
+	return nil
+}
+
+// plainWords is a non-struct input type without a custom decoder.
+type plainWords []string
+
+func TestUnmarshalerInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler interface{}
+		args    string
+		want    string
+		wantReg bool
+	}{
+		{"struct with custom decoder", func(ctx context.Context, in *unitInput) (string, error) {
+			return fmt.Sprintf("%v %v", in.Celsius, in.decoded), nil
+		}, `{"fahrenheit":212}`, "100 true", true},
+		{"non-struct with custom decoder", func(ctx context.Context, in *wordsInput) (string, error) {
+			return strings.Join(*in, "|"), nil
+		}, `{"text":"a b  c"}`, "a|b|c", true},
+		{"non-struct without decoder", func(ctx context.Context, in *plainWords) (string, error) {
+			return "", nil
+		}, ``, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("test", "1.0.0", testCapabilities)
+			err := s.RegisterTools([]ToolRegistration{{
+				Definition: protocol.Tool{Name: "convert", Description: "Converts its input."},
+				Handler:    tt.handler,
+			}})
+			if (err == nil) != tt.wantReg {
+				t.Fatalf("RegisterTools error = %v, want registered %v", err, tt.wantReg)
+			}
+			if !tt.wantReg {
+				return
+			}
+			result := mcptest.CallTool(t, s, "convert", json.RawMessage(tt.args))
+			if got := textOf(t, result); got != tt.want {
+				t.Errorf("handler saw %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		outputSchema string
+		opts         []ServerOption
+		wantErr      bool
+	}{
+		{"matching output", `{"type":"object","properties":{"valid":{"type":"boolean"}},"required":["valid"]}`, []ServerOption{WithOutputValidation()}, false},
+		{"mismatching output", `{"type":"object","properties":{"valid":{"type":"string"}},"required":["valid"]}`, []ServerOption{WithOutputValidation()}, true},
+		{"missing required property", `{"type":"object","required":["reason"]}`, []ServerOption{WithOutputValidation()}, true},
+		{"mismatch without validation", `{"type":"object","properties":{"valid":{"type":"string"}},"required":["valid"]}`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "check", Description: "Checks a code.", OutputSchema: json.RawMessage(tt.outputSchema)},
+				Handler: func(ctx context.Context, in *codeInput) (string, *codeOutput, error) {
+					return "checked", &codeOutput{Valid: true}, nil
+				},
+			}}, tt.opts...)
+			resp := mcptest.NewClient(t, s).Call("tools/call", map[string]interface{}{"name": "check", "arguments": map[string]string{"code": "EUR"}})
+			if tt.wantErr {
+				if resp.Error == nil || resp.Error.Code != -32603 {
+					t.Errorf("error = %+v, want -32603", resp.Error)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Errorf("tools/call failed: %d %s", resp.Error.Code, resp.Error.Message)
+			}
+		})
+	}
+}
+
+type celsius float64
+
+func (c celsius) String() string { return fmt.Sprintf("%.1f°C", float64(c)) }
+
+type jobStatus struct {
+	Name    string        `json:"name"`
+	Updates chan struct{} `json:"updates"`
+}
+
+func TestFormatResultText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"string", "done", "done"},
+		{"stringer", celsius(21.5), "21.5°C"},
+		{"number", 42, "42"},
+		{"struct", codeOutput{Valid: true}, `{"valid":true}`},
+		{"channel field", jobStatus{Name: "backup"}, "{Name:backup Updates:<nil>}"},
+		{"func", func() {}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatResultText(tt.value)
+			if tt.want == "" {
+				// Funcs print as an address; all that matters is that some text is produced.
+				if !strings.HasPrefix(got, "0x") {
+					t.Errorf("formatResultText = %q, want Go's formatting of a func", got)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("formatResultText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallToolUnencodableResult(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        interface{}
+		wantText       string
+		wantStructured bool
+	}{
+		{"unencodable value", func(ctx context.Context, in *codeInput) (jobStatus, error) {
+			return jobStatus{Name: in.Code}, nil
+		}, "{Name:EUR Updates:<nil>}", false},
+		{"unencodable structured content", func(ctx context.Context, in *codeInput) (string, map[string]interface{}, error) {
+			return "started", map[string]interface{}{"updates": make(chan struct{})}, nil
+		}, "started", false},
+		{"encodable structured content", func(ctx context.Context, in *codeInput) (string, map[string]interface{}, error) {
+			return "started", map[string]interface{}{"job": in.Code}, nil
+		}, "started", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "status", Description: "Reports a job's status."},
+				Handler:    tt.handler,
+			}})
+			result := mcptest.CallTool(t, s, "status", map[string]string{"code": "EUR"})
+			if got := textOf(t, result); got != tt.wantText {
+				t.Errorf("text = %q, want %q", got, tt.wantText)
+			}
+			if got := result.StructuredContent != nil; got != tt.wantStructured {
+				t.Errorf("has structured content = %v, want %v", got, tt.wantStructured)
+			}
+		})
+	}
+}
+
+func TestToolVisibilityByRole(t *testing.T) {
+	adminOnly := func(ctx context.Context) bool {
+		role, _ := SessionFromContext(ctx).Value("role")
+		return role == "admin"
+	}
+	echo := func(ctx context.Context, in *echoInput) (string, error) { return in.Value, nil }
+	s := newTestServer(t, []ToolRegistration{
+		{Definition: protocol.Tool{Name: "read", Description: "Reads a record."}, Handler: echo},
+		{Definition: protocol.Tool{Name: "delete", Description: "Deletes a record."}, Handler: echo, Visible: adminOnly},
+	})
+	tests := []struct {
+		role          string
+		wantListed    []string
+		wantCanDelete bool
+	}{
+		{"admin", []string{"delete", "read"}, true},
+		{"viewer", []string{"read"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			c := mcptest.NewClient(t, s)
+			s.Session(c.SessionID()).SetValue("role", tt.role)
+
+			var list protocol.ListToolsResult
+			if err := json.Unmarshal(c.Call("tools/list", nil).Result, &list); err != nil {
+				t.Fatalf("decoding tools/list: %v", err)
+			}
+			var listed []string
+			for _, tool := range list.Tools {
+				listed = append(listed, tool.Name)
+			}
+			if !reflect.DeepEqual(listed, tt.wantListed) {
+				t.Errorf("tools/list = %v, want %v", listed, tt.wantListed)
+			}
+			resp := c.Call("tools/call", map[string]interface{}{"name": "delete", "arguments": map[string]string{"value": "x"}})
+			if canDelete := resp.Error == nil; canDelete != tt.wantCanDelete {
+				t.Errorf("calling delete: error = %+v, want allowed %v", resp.Error, tt.wantCanDelete)
+			}
+		})
+	}
+}
+
+func TestRegisterToolsRejectsReservedNames(t *testing.T) {
+	tests := []struct {
+		register string
+		name     string
+		wantErr  bool
+	}{
+		{"static", "initialize", true},
+		{"static", "tools/list", true},
+		{"static", "ping", true},
+		{"static", "notifications/progress", true},
+		{"static", "notifications/custom", true},
+		{"dynamic", "tools/call", true},
+		{"dynamic", "notifications/custom", true},
+		{"static", "initialize_db", false},
+		{"static", "tools", false},
+		{"dynamic", "list", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.register+" "+tt.name, func(t *testing.T) {
+			s := NewServer("test", "1.0.0", testCapabilities)
+			def := protocol.Tool{Name: tt.name, Description: "A tool."}
+			var err error
+			if tt.register == "static" {
+				err = s.RegisterTools([]ToolRegistration{{
+					Definition: def,
+					Handler:    func(ctx context.Context, in *echoInput) (string, error) { return "", nil },
+				}})
+			} else {
+				err = s.RegisterDynamicTools([]protocol.Tool{def}, func(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
+					return nil, nil
+				})
+			}
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "reserved") {
+					t.Errorf("registering %q: error = %v, want a reserved-name error", tt.name, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("registering %q: %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestErrorOnlyHandlerResults(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ServerOption
+		handlerErr  error
+		wantContent string
+	}{
+		{"empty content by default", nil, nil, `[]`},
+		{"configured text", []ServerOption{WithEmptyResultText("Operation completed successfully.")}, nil, `[{"type":"text","text":"Operation completed successfully."}]`},
+		{"error", []ServerOption{WithEmptyResultText("done")}, fmt.Errorf("disk full"), `[{"type":"text","text":"disk full"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, []ToolRegistration{{
+				Definition: protocol.Tool{Name: "purge", Description: "Purges the cache."},
+				Handler:    func(ctx context.Context, in *echoInput) error { return tt.handlerErr },
+			}}, tt.opts...)
+			resp := mcptest.NewClient(t, s).Call("tools/call", map[string]interface{}{"name": "purge", "arguments": map[string]string{}})
+			if resp.Error != nil {
+				t.Fatalf("tools/call failed: %+v", resp.Error)
+			}
+			var result struct {
+				Content json.RawMessage `json:"content"`
+				IsError bool            `json:"isError"`
+			}
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatal(err)
+			}
+			if string(result.Content) != tt.wantContent {
+				t.Errorf("content = %s, want %s", result.Content, tt.wantContent)
+			}
+			if result.IsError != (tt.handlerErr != nil) {
+				t.Errorf("isError = %v, want %v", result.IsError, tt.handlerErr != nil)
+			}
+		})
+	}
+}
+
+func TestListToolsIsSorted(t *testing.T) {
+	tools := func(names ...string) []ToolRegistration {
+		var regs []ToolRegistration
+		for _, name := range names {
+			regs = append(regs, ToolRegistration{
+				Definition: protocol.Tool{Name: name, Description: "A tool."},
+				Handler:    replyWith(name),
+			})
+		}
+		return regs
+	}
+	tests := []struct {
+		name     string
+		global   []ToolRegistration
+		session  []ToolRegistration
+		provided []ToolRegistration
+		want     []string
+	}{
+		{"global tools", tools("zeta", "alpha", "mu", "beta", "omega"), nil, nil,
+			[]string{"alpha", "beta", "mu", "omega", "zeta"}},
+		{"with session tools", tools("zeta", "alpha"), tools("mu", "beta", "alpha"), nil,
+			[]string{"alpha", "beta", "mu", "zeta"}},
+		{"with provided tools", tools("zeta", "alpha"), tools("mu"), tools("omega", "beta"),
+			[]string{"alpha", "beta", "mu", "omega", "zeta"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []ServerOption
+			if tt.provided != nil {
+				provider := &stubProvider{}
+				for _, reg := range tt.provided {
+					provider.set(reg)
+				}
+				opts = append(opts, WithToolProvider(provider))
+			}
+			s := newTestServer(t, tt.global, opts...)
+			c := mcptest.NewClient(t, s)
+			if tt.session != nil {
+				if err := s.RegisterSessionTools(c.SessionID(), tt.session); err != nil {
+					t.Fatalf("RegisterSessionTools: %v", err)
+				}
+			}
+
+			for i := 0; i < 5; i++ {
+				var result protocol.ListToolsResult
+				if err := json.Unmarshal(c.Call("tools/list", nil).Result, &result); err != nil {
+					t.Fatalf("decoding tools/list: %v", err)
+				}
+				var got []string
+				for _, tool := range result.Tools {
+					got = append(got, tool.Name)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("tools/list = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMaxTools(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		register  string
+		batches   [][]string
+		wantErr   []bool
+		wantTools int
+	}{
+		{"under the limit", 3, "static", [][]string{{"a", "b"}, {"c"}}, []bool{false, false}, 3},
+		{"one past the limit", 3, "static", [][]string{{"a", "b", "c"}, {"d"}}, []bool{false, true}, 3},
+		{"batch crossing the limit", 3, "static", [][]string{{"a", "b"}, {"c", "d"}}, []bool{false, true}, 2},
+		{"dynamic batch crossing the limit", 3, "dynamic", [][]string{{"a", "b"}, {"c", "d"}}, []bool{false, true}, 2},
+		{"dynamic at the limit", 3, "dynamic", [][]string{{"a"}, {"b", "c"}}, []bool{false, false}, 3},
+		{"unlimited", 0, "static", [][]string{{"a", "b", "c"}, {"d", "e"}}, []bool{false, false}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("test", "1.0.0", testCapabilities, WithMaxTools(tt.max))
+			for i, batch := range tt.batches {
+				var err error
+				if tt.register == "static" {
+					var regs []ToolRegistration
+					for _, name := range batch {
+						regs = append(regs, ToolRegistration{Definition: protocol.Tool{Name: name, Description: "A tool."}, Handler: replyWith(name)})
+					}
+					err = s.RegisterTools(regs)
+				} else {
+					var defs []protocol.Tool
+					for _, name := range batch {
+						defs = append(defs, protocol.Tool{Name: name, Description: "A tool."})
+					}
+					err = s.RegisterDynamicTools(defs, func(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
+						return name, nil
+					})
+				}
+				if (err != nil) != tt.wantErr[i] {
+					t.Errorf("batch %d: error = %v, want error %v", i, err, tt.wantErr[i])
+				}
+				if err != nil && !strings.Contains(err.Error(), "limit") {
+					t.Errorf("batch %d: error %q does not mention the limit", i, err)
+				}
+			}
+			s.toolLock.RLock()
+			registered := len(s.tools)
+			s.toolLock.RUnlock()
+			if registered != tt.wantTools {
+				t.Errorf("%d tools registered, want %d", registered, tt.wantTools)
+			}
+		})
+	}
+}
+
+func TestGetTool(t *testing.T) {
+	provider := &stubProvider{}
+	provider.set(ToolRegistration{Definition: protocol.Tool{Name: "provided", Description: "A provided tool."}, Handler: replyWith("provided")})
+	provider.set(ToolRegistration{Definition: protocol.Tool{Name: "provided hidden", Description: "A hidden provided tool."}, Handler: replyWith("hidden"),
+		Visible: func(ctx context.Context) bool { return false }})
+	s := newTestServer(t, []ToolRegistration{
+		{Definition: protocol.Tool{Name: "validate", Description: "Validates a code."}, Handler: func(ctx context.Context, in *codeInput) (codeOutput, error) {
+			return codeOutput{Valid: true}, nil
+		}},
+		{Definition: protocol.Tool{Name: "hidden", Description: "A hidden tool."}, Handler: replyWith("hidden"),
+			Visible: func(ctx context.Context) bool { return false }},
+	}, WithToolProvider(provider))
+	c := mcptest.NewClient(t, s)
+	if err := s.RegisterSessionTools(c.SessionID(), []ToolRegistration{
+		{Definition: protocol.Tool{Name: "session", Description: "A session tool."}, Handler: replyWith("session")},
+	}); err != nil {
+		t.Fatalf("RegisterSessionTools: %v", err)
+	}
+
+	var list protocol.ListToolsResult
+	if err := json.Unmarshal(c.Call("tools/list", nil).Result, &list); err != nil {
+		t.Fatalf("decoding tools/list: %v", err)
+	}
+	listed := make(map[string]protocol.Tool)
+	for _, tool := range list.Tools {
+		listed[tool.Name] = tool
+	}
+
+	tests := []struct {
+		name    string
+		params  interface{}
+		wantErr bool
+	}{
+		{"validate", protocol.GetToolRequest{Name: "validate"}, false},
+		{"session", protocol.GetToolRequest{Name: "session"}, false},
+		{"provided", protocol.GetToolRequest{Name: "provided"}, false},
+		{"unknown", protocol.GetToolRequest{Name: "unknown"}, true},
+		{"hidden", protocol.GetToolRequest{Name: "hidden"}, true},
+		{"provided hidden", protocol.GetToolRequest{Name: "provided hidden"}, true},
+		{"no params", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := c.Call("tools/get", tt.params)
+			if tt.wantErr {
+				if resp.Error == nil || resp.Error.Code != -32602 {
+					t.Errorf("error = %+v, want -32602", resp.Error)
+				}
+				if _, ok := listed[tt.name]; ok {
+					t.Errorf("tools/list lists %s", tt.name)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("tools/get failed: %+v", resp.Error)
+			}
+			var result protocol.GetToolResult
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatalf("decoding tools/get: %v", err)
+			}
+			if len(result.Tool.InputSchema) == 0 {
+				t.Errorf("tools/get = %+v, want a definition with its input schema", result.Tool)
+			}
+			if !reflect.DeepEqual(result.Tool, listed[tt.name]) {
+				t.Errorf("tools/get = %+v, want the tools/list entry %+v", result.Tool, listed[tt.name])
+			}
+		})
+	}
+}
+
+func TestToolDescriptionCheck(t *testing.T) {
+	hook := new(logtest.Hook)
+	saved := log.StandardLogger().ReplaceHooks(log.LevelHooks{})
+	defer log.StandardLogger().ReplaceHooks(saved)
+	log.AddHook(hook)
+
+	paths := []struct {
+		name     string
+		register func(s *Server, def protocol.Tool) error
+		// keepsEmpty is set when an empty description leaves the current one in place.
+		keepsEmpty bool
+	}{
+		{"static", func(s *Server, def protocol.Tool) error {
+			return s.RegisterTools([]ToolRegistration{{Definition: def, Handler: replyWith("ok")}})
+		}, false},
+		{"dynamic", func(s *Server, def protocol.Tool) error {
+			return s.RegisterDynamicTools([]protocol.Tool{def}, func(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
+				return "ok", nil
+			})
+		}, false},
+		{"session", func(s *Server, def protocol.Tool) error {
+			return s.RegisterSessionTools(mcptest.NewClient(t, s).SessionID(), []ToolRegistration{{Definition: def, Handler: replyWith("ok")}})
+		}, false},
+		{"definitions", func(s *Server, def protocol.Tool) error {
+			return s.RegisterFromDefinitions([]protocol.Tool{def}, func(string) interface{} { return replyWith("ok") })
+		}, false},
+		{"update", func(s *Server, def protocol.Tool) error {
+			if err := s.RegisterTools([]ToolRegistration{{Definition: protocol.Tool{Name: def.Name, Description: "Looks up."}, Handler: replyWith("ok")}}); err != nil {
+				return err
+			}
+			hook.Reset()
+			return s.UpdateToolDefinition(def.Name, def)
+		}, true},
+		{"provider", func(s *Server, def protocol.Tool) error {
+			provider := &stubProvider{}
+			provider.set(ToolRegistration{Definition: def, Handler: replyWith("ok")})
+			s.toolProvider = provider
+			err := s.Validate()
+			if _, ok := s.lookupTool(context.Background(), def.Name); ok == (err != nil) {
+				t.Errorf("provided tool resolves = %v, with Validate error %v", ok, err)
+			}
+			return err
+		}, false},
+	}
+	tests := []struct {
+		description string
+		strict      bool
+		wantWarning bool
+		wantErr     bool
+	}{
+		{"Looks things up.", false, false, false},
+		{"Looks things up.", true, false, false},
+		{"", false, true, false},
+		{"  ", false, true, false},
+		{"", true, false, true},
+		{"\t", true, false, true},
+	}
+	for _, path := range paths {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s %q strict=%v", path.name, tt.description, tt.strict), func(t *testing.T) {
+				var opts []ServerOption
+				if tt.strict {
+					opts = append(opts, WithStrictToolValidation())
+				}
+				wantWarning, wantErr := tt.wantWarning, tt.wantErr
+				if path.keepsEmpty && tt.description == "" {
+					wantWarning, wantErr = false, false
+				}
+				s := newTestServer(t, nil, opts...)
+				hook.Reset()
+				err := path.register(s, protocol.Tool{Name: "lookup", Description: tt.description})
+				if wantErr {
+					if err == nil || !strings.Contains(err.Error(), "must include a description") {
+						t.Errorf("error = %v, want a missing description error", err)
+					}
+				} else if err != nil {
+					t.Errorf("registering: %v", err)
+				}
+
+				warned := false
+				for _, entry := range hook.AllEntries() {
+					if entry.Level == log.WarnLevel && strings.Contains(entry.Message, "'lookup' has no description") {
+						warned = true
+					}
+				}
+				if warned != wantWarning {
+					t.Errorf("warning logged = %v, want %v", warned, wantWarning)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Validate checks every tool the server offers and reports all problems found, so that
+// misconfiguration can fail a deployment, or a CI run, instead of the first call. It
+// checks that each registered tool's schemas are valid JSON, that every tool listed by
+// the tool provider resolves to a valid registration, and runs each registered tool's
+// SelfTest. The returned error joins one error per problem, in tool name order; it is
+// nil if there are none.
+func (s *Server) Validate() error {
+	ctx := context.Background()
+
+	s.toolLock.RLock()
+	tools := make([]internalRegisteredTool, 0, len(s.tools))
+	for _, tool := range s.tools {
+		tools = append(tools, tool)
+	}
+	s.toolLock.RUnlock()
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Definition.Name < tools[j].Definition.Name
+	})
+
+	var problems []error
+	registered := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		registered[tool.Definition.Name] = true
+		problems = append(problems, s.validateTool(ctx, tool)...)
+	}
+
+	if s.toolProvider != nil {
+		defs := s.toolProvider.List(ctx)
+		sort.Slice(defs, func(i, j int) bool {
+			return defs[i].Name < defs[j].Name
+		})
+		for _, def := range defs {
+			// A registered tool takes precedence over a provided one with the same name.
+			if registered[def.Name] {
+				continue
+			}
+			reg, ok := s.toolProvider.Resolve(ctx, def.Name)
+			if !ok {
+				problems = append(problems, fmt.Errorf("tool '%s': listed by the tool provider but cannot be resolved", def.Name))
+				continue
+			}
+			if reg.Definition.Name == "" {
+				reg.Definition.Name = def.Name
+			}
+			tool, err := s.buildTool(reg)
+			if err == nil {
+				err = s.checkDescription(tool.Definition)
+			}
+			if err != nil {
+				problems = append(problems, fmt.Errorf("tool '%s': %w", def.Name, err))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// validateTool checks a registered tool's definition and runs its self-test.
+func (s *Server) validateTool(ctx context.Context, tool internalRegisteredTool) []error {
+	var problems []error
+	name := tool.Definition.Name
+	if len(tool.Definition.InputSchema) > 0 && !json.Valid(tool.Definition.InputSchema) {
+		problems = append(problems, fmt.Errorf("tool '%s': input schema is not valid JSON", name))
+	}
+	if len(tool.Definition.OutputSchema) > 0 && !json.Valid(tool.Definition.OutputSchema) {
+		problems = append(problems, fmt.Errorf("tool '%s': output schema is not valid JSON", name))
+	}
+	if tool.selfTest != nil {
+		testCtx := ctx
+		if timeout := s.callTimeout(tool, nil); timeout > 0 {
+			var cancel context.CancelFunc
+			testCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if err := tool.selfTest(testCtx); err != nil {
+			problems = append(problems, fmt.Errorf("tool '%s': self-test failed: %w", name, err))
+		}
+	}
+	return problems
+}
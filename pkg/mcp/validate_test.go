@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestValidate(t *testing.T) {
+	passes := func(ctx context.Context) error { return nil }
+	fails := func(ctx context.Context) error { return errors.New("database unreachable") }
+	hangs := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	tool := func(name string, selfTest func(ctx context.Context) error) ToolRegistration {
+		return ToolRegistration{
+			Definition: protocol.Tool{Name: name, Description: "A tool."},
+			Handler:    replyWith(name),
+			SelfTest:   selfTest,
+		}
+	}
+	tests := []struct {
+		name     string
+		tools    []ToolRegistration
+		provided []ToolRegistration
+		opts     []ServerOption
+		want     []string
+	}{
+		{"no problems", []ToolRegistration{tool("a", passes), tool("b", nil)}, nil, nil, nil},
+		{"failed self-tests in name order", []ToolRegistration{tool("zeta", fails), tool("alpha", fails), tool("mu", passes)}, nil, nil, []string{
+			"tool 'alpha': self-test failed: database unreachable",
+			"tool 'zeta': self-test failed: database unreachable",
+		}},
+		{"self-test bounded by the tool timeout", []ToolRegistration{tool("slow", hangs)}, nil, []ServerOption{WithToolTimeout(10 * time.Millisecond)}, []string{
+			"tool 'slow': self-test failed: context deadline exceeded",
+		}},
+		{"invalid provided tool", nil, []ToolRegistration{tool("fine", nil), {
+			Definition: protocol.Tool{Name: "broken", Description: "A tool."},
+			Handler:    func(in string) string { return in },
+		}}, nil, []string{"tool 'broken': "}},
+		{"registered tool shadows a provided one", []ToolRegistration{tool("shared", nil)}, []ToolRegistration{{
+			Definition: protocol.Tool{Name: "shared", Description: "A tool."},
+			Handler:    func(in string) string { return in },
+		}}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if tt.provided != nil {
+				provider := &stubProvider{}
+				for _, reg := range tt.provided {
+					provider.set(reg)
+				}
+				opts = append(opts, WithToolProvider(provider))
+			}
+			err := newTestServer(t, tt.tools, opts...).Validate()
+			var got []string
+			if err != nil {
+				got = strings.Split(err.Error(), "\n")
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d problems: %v", err, len(tt.want), tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("problem %d = %q, want it to start with %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
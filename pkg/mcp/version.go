@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"slices"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// supportedProtocolVersions lists the protocol revisions this SDK understands, newest first.
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// WithSupportedProtocolVersions replaces the protocol versions the server accepts during
+// initialize. Versions are date-based ("YYYY-MM-DD") and may be given in any order.
+func WithSupportedProtocolVersions(versions ...string) ServerOption {
+	return func(s *Server) {
+		if len(versions) == 0 {
+			return
+		}
+		sorted := append([]string(nil), versions...)
+		sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+		s.supportedVersions = sorted
+	}
+}
+
+// WithProtocolVersionAlias makes the server treat a client's requested version alias,
+// such as a draft revision name, as if it had asked for version.
+func WithProtocolVersionAlias(alias, version string) ServerOption {
+	return func(s *Server) {
+		if s.versionAliases == nil {
+			s.versionAliases = make(map[string]string)
+		}
+		s.versionAliases[alias] = version
+	}
+}
+
+// protocolVersions returns the versions the server will agree to during initialize, in
+// order of preference: the pinned version, if any, then the supported ones, newest first.
+func (s *Server) protocolVersions() []string {
+	supported := s.supportedVersionList()
+	if s.protocolVersion == "" {
+		return supported
+	}
+	versions := []string{s.protocolVersion}
+	for _, version := range supported {
+		if version != s.protocolVersion {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// supportedVersionList returns the versions the server negotiates between, newest first.
+func (s *Server) supportedVersionList() []string {
+	if len(s.supportedVersions) > 0 {
+		return append([]string(nil), s.supportedVersions...)
+	}
+	return append([]string(nil), supportedProtocolVersions...)
+}
+
+// negotiateProtocolVersion picks the version to answer an initialize request with.
+// A pinned version answers any client that requested a version the server supports, or
+// the pinned version itself; other clients are negotiated with as if nothing were pinned,
+// rather than being handed a version they may not speak. A supported version is accepted
+// as is. Otherwise the server downgrades to the newest supported version that is older
+// than the one requested, since date-based versions order lexically; a request older
+// than everything supported gets the oldest version, and anything unrecognisable gets
+// the newest. The client decides whether to proceed.
+func (s *Server) negotiateProtocolVersion(requested string) string {
+	versions := s.supportedVersionList()
+
+	candidate := requested
+	if alias, ok := s.versionAliases[requested]; ok {
+		candidate = alias
+	}
+
+	if s.protocolVersion != "" {
+		if candidate == s.protocolVersion || slices.Contains(versions, candidate) {
+			if s.protocolVersion != requested {
+				log.Warnf("Client requested protocol version '%s', responding with pinned version '%s'", requested, s.protocolVersion)
+			}
+			return s.protocolVersion
+		}
+		log.Warnf("Client requested unsupported protocol version '%s', negotiating instead of responding with pinned version '%s'", requested, s.protocolVersion)
+	}
+
+	negotiated := versions[0]
+	if isDateVersion(candidate) {
+		negotiated = versions[len(versions)-1]
+		for _, version := range versions {
+			if version <= candidate {
+				negotiated = version
+				break
+			}
+		}
+	}
+
+	if negotiated == requested {
+		log.Infof("Negotiated protocol version '%s'", negotiated)
+	} else {
+		log.Infof("Client requested protocol version '%s', negotiated '%s'", requested, negotiated)
+	}
+	return negotiated
+}
+
+// isDateVersion reports whether version has the "YYYY-MM-DD" shape used by protocol revisions.
+func isDateVersion(version string) bool {
+	if len(version) != len("2006-01-02") {
+		return false
+	}
+	for i, c := range version {
+		switch i {
+		case 4, 7:
+			if c != '-' {
+				return false
+			}
+		default:
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ServerOption
+		requested string
+		want      string
+	}{
+		{"newest supported", nil, "2025-06-18", "2025-06-18"},
+		{"older supported", nil, "2025-03-26", "2025-03-26"},
+		{"oldest supported", nil, "2024-11-05", "2024-11-05"},
+		{"newer than supported", nil, "2026-01-01", "2025-06-18"},
+		{"between supported", nil, "2025-01-15", "2024-11-05"},
+		{"older than supported", nil, "2024-01-01", "2024-11-05"},
+		{"not a date", nil, "draft", "2025-06-18"},
+		{"empty", nil, "", "2025-06-18"},
+		{"alias", []ServerOption{WithProtocolVersionAlias("draft", "2025-03-26")}, "draft", "2025-03-26"},
+		{"alias to an unsupported version", []ServerOption{WithProtocolVersionAlias("next", "2025-05-01")}, "next", "2025-03-26"},
+		{"pinned version", []ServerOption{WithProtocolVersion("2025-03-26")}, "2025-06-18", "2025-03-26"},
+		{"pinned version requested", []ServerOption{WithProtocolVersion("2025-11-25")}, "2025-11-25", "2025-11-25"},
+		{"pinned version, older client", []ServerOption{WithProtocolVersion("2025-06-18")}, "2024-01-01", "2024-11-05"},
+		{"pinned version, unknown client version", []ServerOption{WithProtocolVersion("2025-03-26")}, "draft", "2025-06-18"},
+		{"pinned version, aliased client version", []ServerOption{WithProtocolVersion("2025-03-26"), WithProtocolVersionAlias("draft", "2024-11-05")}, "draft", "2025-03-26"},
+		{"configured versions", []ServerOption{WithSupportedProtocolVersions("2024-11-05", "2025-03-26")}, "2025-06-18", "2025-03-26"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, nil, tt.opts...)
+			if got := s.negotiateProtocolVersion(tt.requested); got != tt.want {
+				t.Errorf("negotiateProtocolVersion(%q) = %q, want %q", tt.requested, got, tt.want)
+			}
+
+			rec := post(t, s, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+tt.requested+`","clientInfo":{"name":"c","version":"1"}}}`)
+			var resp struct {
+				Result protocol.InitializeResult `json:"result"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding initialize response: %v\n%s", err, rec.Body.String())
+			}
+			if resp.Result.ProtocolVersion != tt.want {
+				t.Errorf("initialize answered %q, want %q", resp.Result.ProtocolVersion, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package mcptest provides helpers for exercising MCP servers in tests without
+// hand-building JSON-RPC envelopes.
+package mcptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mcp-sdk/pkg/protocol"
+)
+
+// ProtocolVersion is the protocol version the test client requests during initialize.
+const ProtocolVersion = "2025-06-18"
+
+// Client drives an MCP server in-process through its http.Handler.
+// The initialize handshake is performed by NewClient, so calls can be made straight away.
+type Client struct {
+	t         testing.TB
+	handler   http.Handler
+	path      string
+	sessionID string
+	nextID    float64
+	// notifications collects notifications received on streamed responses.
+	notifications []protocol.Notification
+}
+
+// NewClient initializes a session against handler and returns a client bound to it.
+func NewClient(t testing.TB, handler http.Handler) *Client {
+	t.Helper()
+	return NewClientAt(t, handler, "/mcp")
+}
+
+// NewClientAt is like NewClient but sends requests to path, for servers configured
+// with mcp.WithPath or mounted on a shared mux.
+func NewClientAt(t testing.TB, handler http.Handler, path string) *Client {
+	t.Helper()
+	c := &Client{t: t, handler: handler, path: path}
+
+	resp, header := c.send("initialize", protocol.InitializeRequest{
+		ProtocolVersion: ProtocolVersion,
+		ClientInfo:      protocol.ImplementationInfo{Name: "mcptest", Version: "0.0.0"},
+	})
+	if resp.Error != nil {
+		t.Fatalf("mcptest: initialize failed: %d %s", resp.Error.Code, resp.Error.Message)
+	}
+	c.sessionID = header.Get("Mcp-Session-Id")
+
+	c.Notify("notifications/initialized", nil)
+	return c
+}
+
+// SessionID returns the session id assigned by the server during initialize.
+func (c *Client) SessionID() string {
+	return c.sessionID
+}
+
+// Call sends a request and returns the decoded JSON-RPC response, including error responses.
+func (c *Client) Call(method string, params interface{}) *protocol.Response {
+	c.t.Helper()
+	resp, _ := c.send(method, params)
+	return resp
+}
+
+// Notify sends a notification. Notifications have no response body.
+func (c *Client) Notify(method string, params interface{}) {
+	c.t.Helper()
+	notif := protocol.Notification{JSONRPC: "2.0", Method: method, Params: c.marshal(params)}
+	c.do(notif)
+}
+
+// CallTool invokes a tool and returns its decoded result.
+// A JSON-RPC error response fails the test; a tool-level error is returned with IsError set.
+func (c *Client) CallTool(name string, args interface{}) *protocol.CallToolResult {
+	c.t.Helper()
+	resp := c.Call("tools/call", map[string]interface{}{"name": name, "arguments": args})
+	if resp.Error != nil {
+		c.t.Fatalf("mcptest: tools/call %s failed: %d %s", name, resp.Error.Code, resp.Error.Message)
+	}
+	var result protocol.CallToolResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		c.t.Fatalf("mcptest: could not decode tools/call result: %v", err)
+	}
+	return &result
+}
+
+// CallTool initializes a fresh session against handler and invokes a single tool.
+func CallTool(t testing.TB, handler http.Handler, name string, args interface{}) *protocol.CallToolResult {
+	t.Helper()
+	return NewClient(t, handler).CallTool(name, args)
+}
+
+func (c *Client) send(method string, params interface{}) (*protocol.Response, http.Header) {
+	c.t.Helper()
+	c.nextID++
+	req := protocol.Request{
+		JSONRPC: "2.0",
+		ID:      protocol.NewNumericRequestID(c.nextID),
+		Method:  method,
+		Params:  c.marshal(params),
+	}
+	rec := c.do(req)
+
+	body := rec.Body.Bytes()
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+		body = c.readStream(method, body)
+	}
+	var resp protocol.Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		c.t.Fatalf("mcptest: could not decode response to %s (HTTP %d): %v", method, rec.Code, err)
+	}
+	return &resp, rec.Header()
+}
+
+// Notifications returns the notifications the server sent on streamed responses so far,
+// in the order they arrived.
+func (c *Client) Notifications() []protocol.Notification {
+	return append([]protocol.Notification(nil), c.notifications...)
+}
+
+// readStream collects the notifications in an SSE response body and returns the data
+// of the final event, which carries the response.
+func (c *Client) readStream(method string, body []byte) []byte {
+	c.t.Helper()
+	var events [][]byte
+	for _, event := range bytes.Split(body, []byte("\n\n")) {
+		var data [][]byte
+		for _, line := range bytes.Split(event, []byte("\n")) {
+			if rest, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+				data = append(data, bytes.TrimPrefix(rest, []byte(" ")))
+			}
+		}
+		if len(data) > 0 {
+			events = append(events, bytes.Join(data, []byte("\n")))
+		}
+	}
+	if len(events) == 0 {
+		c.t.Fatalf("mcptest: streamed response to %s carried no events", method)
+	}
+	for _, event := range events[:len(events)-1] {
+		var notif protocol.Notification
+		if err := json.Unmarshal(event, &notif); err != nil {
+			c.t.Fatalf("mcptest: could not decode streamed notification for %s: %v", method, err)
+		}
+		c.notifications = append(c.notifications, notif)
+	}
+	return events[len(events)-1]
+}
+
+func (c *Client) do(message interface{}) *httptest.ResponseRecorder {
+	c.t.Helper()
+	body, err := json.Marshal(message)
+	if err != nil {
+		c.t.Fatalf("mcptest: could not encode message: %v", err)
+	}
+	httpReq := httptest.NewRequest(http.MethodPost, c.path, bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	if c.sessionID != "" {
+		httpReq.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, httpReq)
+	return rec
+}
+
+func (c *Client) marshal(params interface{}) json.RawMessage {
+	c.t.Helper()
+	if params == nil {
+		return nil
+	}
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatalf("mcptest: could not encode params: %v", err)
+	}
+	return paramsBytes
+}
@@ -0,0 +1,93 @@
+package mcptest_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"go-mcp-sdk/pkg/mcp"
+	"go-mcp-sdk/pkg/mcptest"
+	"go-mcp-sdk/pkg/protocol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.WarnLevel)
+	os.Exit(m.Run())
+}
+
+type greetInput struct {
+	Name string `json:"name"`
+}
+
+func newGreeter(t *testing.T) *mcp.Server {
+	t.Helper()
+	s := mcp.NewServer("greeter", "1.0.0", protocol.ServerCapabilities{Tools: &protocol.ServerToolCapabilities{}})
+	err := s.RegisterTools([]mcp.ToolRegistration{{
+		Definition: protocol.Tool{Name: "greet", Description: "Greets someone."},
+		Handler: func(ctx context.Context, in *greetInput) (string, error) {
+			if in.Name == "" {
+				return "", errors.New("name is required")
+			}
+			return "Hello, " + in.Name, nil
+		},
+	}})
+	if err != nil {
+		t.Fatalf("RegisterTools: %v", err)
+	}
+	return s
+}
+
+func TestCallTool(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        interface{}
+		wantText    string
+		wantIsError bool
+	}{
+		{"success", map[string]string{"name": "Ada"}, "Hello, Ada", false},
+		{"tool error", map[string]string{}, "name is required", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mcptest.CallTool(t, newGreeter(t), "greet", tt.args)
+			if result.IsError != tt.wantIsError {
+				t.Errorf("IsError = %v, want %v", result.IsError, tt.wantIsError)
+			}
+			if len(result.Content) == 0 || result.Content[0].Text != tt.wantText {
+				t.Errorf("content = %+v, want text %q", result.Content, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestClientCall(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		params    interface{}
+		wantError int
+	}{
+		{"list tools", "tools/list", nil, 0},
+		{"tool call", "tools/call", map[string]interface{}{"name": "greet", "arguments": map[string]string{"name": "Ada"}}, 0},
+		{"unknown tool", "tools/call", map[string]interface{}{"name": "wave"}, -32602},
+		{"unknown method", "tools/unknown", nil, -32601},
+	}
+	c := mcptest.NewClient(t, newGreeter(t))
+	if c.SessionID() == "" {
+		t.Fatal("SessionID() is empty after initialize")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := c.Call(tt.method, tt.params)
+			switch {
+			case tt.wantError == 0 && resp.Error != nil:
+				t.Errorf("%s failed: %d %s", tt.method, resp.Error.Code, resp.Error.Message)
+			case tt.wantError != 0 && (resp.Error == nil || resp.Error.Code != tt.wantError):
+				t.Errorf("%s error = %+v, want code %d", tt.method, resp.Error, tt.wantError)
+			}
+		})
+	}
+}
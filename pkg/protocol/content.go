@@ -0,0 +1,113 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// contentBlockAlias has the same fields as ContentBlock but none of its methods,
+// so it can be used for the default encoding without recursing.
+type contentBlockAlias ContentBlock
+
+// contentBlockFields is the set of JSON keys modelled directly by ContentBlock.
+var contentBlockFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(ContentBlock{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// MarshalJSON encodes the block, merging any Extra fields into the object.
+func (b ContentBlock) MarshalJSON() ([]byte, error) {
+	base, err := json.Marshal(contentBlockAlias(b))
+	if err != nil || len(b.Extra) == 0 {
+		return base, err
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range b.Extra {
+		if contentBlockFields[key] {
+			continue
+		}
+		merged[key] = value
+	}
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON decodes the block, keeping unrecognised fields in Extra.
+func (b *ContentBlock) UnmarshalJSON(data []byte) error {
+	var alias contentBlockAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for key := range all {
+		if contentBlockFields[key] {
+			delete(all, key)
+		}
+	}
+	if len(all) > 0 {
+		alias.Extra = all
+	} else {
+		alias.Extra = nil
+	}
+
+	*b = ContentBlock(alias)
+	return nil
+}
+
+// ContentValidator checks that a content block of a custom type is well-formed.
+type ContentValidator func(ContentBlock) error
+
+var (
+	contentTypesLock sync.RWMutex
+	contentTypes     = make(map[string]ContentValidator)
+)
+
+// RegisterContentType registers a validator for a custom content block type.
+// Blocks of unregistered types are passed through without validation.
+func RegisterContentType(contentType string, validate ContentValidator) error {
+	if contentType == "" {
+		return fmt.Errorf("content type must not be empty")
+	}
+	if validate == nil {
+		return fmt.Errorf("validator for content type '%s' must not be nil", contentType)
+	}
+
+	contentTypesLock.Lock()
+	defer contentTypesLock.Unlock()
+	if _, exists := contentTypes[contentType]; exists {
+		return fmt.Errorf("content type '%s' already registered", contentType)
+	}
+	contentTypes[contentType] = validate
+	return nil
+}
+
+// ValidateContentBlock runs the registered validator for the block's type, if any.
+func ValidateContentBlock(b ContentBlock) error {
+	contentTypesLock.RLock()
+	validate, ok := contentTypes[b.Type]
+	contentTypesLock.RUnlock()
+	if !ok {
+		return nil
+	}
+	if err := validate(b); err != nil {
+		return fmt.Errorf("invalid '%s' content: %w", b.Type, err)
+	}
+	return nil
+}
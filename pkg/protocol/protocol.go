@@ -1,6 +1,7 @@
 package protocol
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -29,6 +30,8 @@ func (id RequestID) String() string {
 	switch v := id.value.(type) {
 	case string:
 		return v
+	case json.Number:
+		return v.String()
 	case float64:
 		return strconv.FormatFloat(v, 'f', -1, 64)
 	case int:
@@ -38,33 +41,35 @@ func (id RequestID) String() string {
 	}
 }
 
-// Value returns the underlying value
+// Value returns the underlying value. Numeric ids decoded from JSON are
+// returned as a json.Number holding the original literal.
 func (id RequestID) Value() interface{} {
 	return id.value
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling
 func (id *RequestID) UnmarshalJSON(data []byte) error {
-	// Try to unmarshal as string first
+	// Check for null first, since it would otherwise decode as an empty string
+	if string(bytes.TrimSpace(data)) == "null" {
+		id.value = nil
+		return nil
+	}
+
+	// Try to unmarshal as string
 	var str string
 	if err := json.Unmarshal(data, &str); err == nil {
 		id.value = str
 		return nil
 	}
 
-	// Try to unmarshal as number
-	var num float64
+	// Try to unmarshal as number. The literal is kept as a json.Number so the
+	// response echoes exactly what the client sent, without float64 rounding.
+	var num json.Number
 	if err := json.Unmarshal(data, &num); err == nil {
 		id.value = num
 		return nil
 	}
 
-	// Check if it's null
-	if string(data) == "null" {
-		id.value = nil
-		return nil
-	}
-
 	return fmt.Errorf("invalid request ID: must be string, number, or null")
 }
 
@@ -99,6 +104,15 @@ type ErrorObject struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// Error implements the error interface, so an ErrorObject can be returned from
+// helpers and later written to the client unchanged.
+func (e *ErrorObject) Error() string {
+	if e.Data != nil {
+		return fmt.Sprintf("%s (code %d): %v", e.Message, e.Code, e.Data)
+	}
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
 // Notification is a generic JSON-RPC 2.0 notification object.
 type Notification struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -112,6 +126,9 @@ type InitializeRequest struct {
 	ProtocolVersion string             `json:"protocolVersion"`
 	ClientInfo      ImplementationInfo `json:"clientInfo"`
 	Capabilities    ClientCapabilities `json:"capabilities"`
+	// Locale is the client's preferred language as a BCP 47 tag, such as "fr-CA". It is
+	// an extension to the protocol; clients may send an Accept-Language header instead.
+	Locale string `json:"locale,omitempty"`
 }
 
 // InitializeResult represents the successful result of an "initialize" request.
@@ -174,6 +191,10 @@ type Tool struct {
 	Title       string          `json:"title,omitempty"`
 	Description string          `json:"description,omitempty"`
 	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+	// OutputSchema describes the tool's structuredContent, if it returns any.
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+	// Version is the semantic version of the tool's contract, if the server declares one.
+	Version string `json:"version,omitempty"`
 }
 
 // ListToolsResult is the response for a "tools/list" request.
@@ -181,20 +202,227 @@ type ListToolsResult struct {
 	Tools []Tool `json:"tools"`
 }
 
+// GetToolRequest represents the parameters for a "tools/get" request.
+type GetToolRequest struct {
+	Name string `json:"name"`
+}
+
+// GetToolResult is the response for a "tools/get" request.
+type GetToolResult struct {
+	Tool Tool `json:"tool"`
+}
+
 // CallToolRequest represents the parameters for a "tools/call" request.
 type CallToolRequest struct {
-	Name      string                 `json:"name"`
-	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Name string `json:"name"`
+	// Arguments holds the arguments exactly as the client sent them, normally a JSON
+	// object. Keeping the raw bytes lets them be decoded straight into the tool's typed
+	// input, without a round trip through a map that could lose number precision.
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	// Meta carries request metadata, such as a progress token.
+	Meta *RequestMeta `json:"_meta,omitempty"`
+}
+
+// RequestMeta is the "_meta" object a client may attach to a request.
+type RequestMeta struct {
+	// ProgressToken, if set, asks the server to send "notifications/progress" for the
+	// request. It is a string or number and is kept raw so it is echoed back exactly.
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"`
+	// Timeout, if positive, is how many milliseconds the client will wait for the
+	// response. The server stops waiting on the request's behalf once it has passed.
+	Timeout int64 `json:"timeout,omitempty"`
+}
+
+// HasArguments reports whether any arguments were supplied (an explicit null counts as none).
+func (r *CallToolRequest) HasArguments() bool {
+	args := bytes.TrimSpace(r.Arguments)
+	return len(args) > 0 && !bytes.Equal(args, []byte("null"))
+}
+
+// PositionalArguments reports whether the arguments were sent as a JSON array.
+func (r *CallToolRequest) PositionalArguments() bool {
+	args := bytes.TrimSpace(r.Arguments)
+	return len(args) > 0 && args[0] == '['
 }
 
 // CallToolResult is the response from a successful tool call.
 type CallToolResult struct {
 	Content []ContentBlock `json:"content"`
-	IsError bool           `json:"isError,omitempty"`
+	// StructuredContent is an optional JSON object describing the result in machine-readable form.
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+	// Meta carries protocol-level metadata about the result, such as warnings.
+	Meta map[string]interface{} `json:"_meta,omitempty"`
 }
 
-// ContentBlock represents a piece of content in a tool's result.
+// ToolError describes why a tool call failed, in a form clients can render apart from
+// ordinary output. Handlers may return one as their error to set its code and details.
+type ToolError struct {
+	// Code is a short, machine-readable identifier such as "not_found".
+	Code    string      `json:"code,omitempty"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Error implements the error interface and returns the message.
+func (e *ToolError) Error() string {
+	return e.Message
+}
+
+// ContentBlock represents a piece of content in a tool's result or a prompt message.
 type ContentBlock struct {
 	Type string `json:"type"`
 	Text string `json:"text,omitempty"`
-}
\ No newline at end of file
+	// Data holds base64-encoded bytes for "image" and "audio" content.
+	Data     string `json:"data,omitempty"`
+	MIMEType string `json:"mimeType,omitempty"`
+	// Resource holds the embedded contents when Type is "resource".
+	Resource *ResourceContents `json:"resource,omitempty"`
+	// Error holds the structured error when Type is "error".
+	Error *ToolError `json:"error,omitempty"`
+	// Extra holds any fields not modelled above, such as those used by custom
+	// content types. They are preserved when a block is decoded and re-encoded.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ResourceContents holds the contents of a resource embedded in a message.
+// Exactly one of Text or Blob (base64-encoded) should be set.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MIMEType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// Resource describes a piece of data that the server exposes to clients.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mimeType,omitempty"`
+}
+
+// ListResourcesResult is the response for a "resources/list" request.
+type ListResourcesResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ReadResourceRequest represents the parameters for a "resources/read" request.
+type ReadResourceRequest struct {
+	URI string `json:"uri"`
+}
+
+// ReadResourceResult is the response for a "resources/read" request.
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// SubscribeRequest represents the parameters for a "resources/subscribe" or
+// "resources/unsubscribe" request.
+type SubscribeRequest struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedNotification represents the parameters for the
+// "notifications/resources/updated" notification.
+type ResourceUpdatedNotification struct {
+	URI string `json:"uri"`
+	// Contents holds the resource's new contents, for resources the server delivers
+	// inline. Without it, clients call "resources/read" to fetch them.
+	Contents []ResourceContents `json:"contents,omitempty"`
+}
+
+// Prompt defines a prompt template that a client can retrieve.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument that a prompt template accepts.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ListPromptsResult is the response for a "prompts/list" request.
+type ListPromptsResult struct {
+	Prompts []Prompt `json:"prompts"`
+}
+
+// GetPromptRequest represents the parameters for a "prompts/get" request.
+type GetPromptRequest struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// GetPromptResult is the response for a "prompts/get" request.
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptMessage is a single message returned as part of a prompt.
+type PromptMessage struct {
+	Role    string       `json:"role"`
+	Content ContentBlock `json:"content"`
+}
+
+// LoggingLevel is the severity of a log message, following syslog (RFC 5424) levels.
+type LoggingLevel string
+
+const (
+	LevelDebug     LoggingLevel = "debug"
+	LevelInfo      LoggingLevel = "info"
+	LevelNotice    LoggingLevel = "notice"
+	LevelWarning   LoggingLevel = "warning"
+	LevelError     LoggingLevel = "error"
+	LevelCritical  LoggingLevel = "critical"
+	LevelAlert     LoggingLevel = "alert"
+	LevelEmergency LoggingLevel = "emergency"
+)
+
+var loggingLevelSeverity = map[LoggingLevel]int{
+	LevelDebug:     0,
+	LevelInfo:      1,
+	LevelNotice:    2,
+	LevelWarning:   3,
+	LevelError:     4,
+	LevelCritical:  5,
+	LevelAlert:     6,
+	LevelEmergency: 7,
+}
+
+// Valid reports whether l is one of the defined levels.
+func (l LoggingLevel) Valid() bool {
+	_, ok := loggingLevelSeverity[l]
+	return ok
+}
+
+// AtLeast reports whether l is as severe as, or more severe than, min.
+func (l LoggingLevel) AtLeast(min LoggingLevel) bool {
+	return loggingLevelSeverity[l] >= loggingLevelSeverity[min]
+}
+
+// SetLevelRequest represents the parameters for a "logging/setLevel" request.
+type SetLevelRequest struct {
+	Level LoggingLevel `json:"level"`
+}
+
+// LoggingMessageNotification is the payload of a "notifications/message" notification.
+type LoggingMessageNotification struct {
+	Level  LoggingLevel `json:"level"`
+	Logger string       `json:"logger,omitempty"`
+	Data   interface{}  `json:"data"`
+}
+
+// ProgressNotification is the payload of a "notifications/progress" notification.
+type ProgressNotification struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
@@ -0,0 +1,54 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRequestIDRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		wantString string
+	}{
+		{"small integer", `1`, "1"},
+		{"beyond float64 precision", `9007199254740993`, "9007199254740993"},
+		{"large integer", `10000000000000001`, "10000000000000001"},
+		{"negative", `-42`, "-42"},
+		{"fraction", `1.5`, "1.5"},
+		{"exponent", `1e3`, "1e3"},
+		{"string", `"abc-123"`, "abc-123"},
+		{"numeric string", `"9007199254740993"`, "9007199254740993"},
+		{"empty string", `""`, ""},
+		{"null", `null`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req Request
+			if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":`+tt.json+`,"method":"ping"}`), &req); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got := req.ID.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+			encoded, err := json.Marshal(Response{JSONRPC: "2.0", ID: req.ID})
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if want := `{"jsonrpc":"2.0","id":` + tt.json + `}`; string(encoded) != want {
+				t.Errorf("response = %s, want %s", encoded, want)
+			}
+		})
+	}
+}
+
+func TestRequestIDRejectsOtherTypes(t *testing.T) {
+	for _, raw := range []string{`true`, `{}`, `[1]`} {
+		t.Run(raw, func(t *testing.T) {
+			var id RequestID
+			if err := json.Unmarshal([]byte(raw), &id); err == nil {
+				t.Errorf("Unmarshal(%s) succeeded with %v", raw, id.Value())
+			}
+		})
+	}
+}